@@ -8,10 +8,12 @@ import (
 	"os"
 	"strings"
 
+	"github.com/kofno/bullderdash/internal/redisconn"
 	"github.com/redis/go-redis/v9"
 )
 
 func main() {
+	url := flag.String("url", "", "Redis URL (redis:// or rediss://); overrides -addr, -username, -password and -db")
 	addr := flag.String("addr", "127.0.0.1:6379", "Redis address")
 	username := flag.String("username", "", "Redis username")
 	password := flag.String("password", "", "Redis password")
@@ -20,11 +22,39 @@ func main() {
 	sentinelAddrs := flag.String("sentinel-addrs", "", "Comma-separated Sentinel addresses")
 	sentinelUsername := flag.String("sentinel-username", "", "Sentinel username")
 	sentinelPassword := flag.String("sentinel-password", "", "Sentinel password")
+	useTLS := flag.Bool("tls", false, "Connect with TLS")
+	tlsCAFile := flag.String("tls-ca-file", "", "PEM CA bundle for verifying the server")
+	tlsCertFile := flag.String("tls-cert-file", "", "PEM client certificate for mutual TLS")
+	tlsKeyFile := flag.String("tls-key-file", "", "PEM client key for mutual TLS")
+	tlsServerName := flag.String("tls-server-name", "", "Server name to verify instead of the host")
+	tlsInsecure := flag.Bool("tls-insecure-skip-verify", false, "Skip server certificate verification (development only)")
 	flag.Parse()
 
 	// Create Redis client
-	client := newRedisClient(*addr, *username, *password, *db, *sentinelMaster, *sentinelAddrs, *sentinelUsername, *sentinelPassword)
-	defer func(client *redis.Client) {
+	client, err := redisconn.NewClient(redisconn.Options{
+		URL:              *url,
+		Addr:             *addr,
+		Username:         *username,
+		Password:         *password,
+		DB:               *db,
+		SentinelMaster:   *sentinelMaster,
+		SentinelAddrs:    parseList(*sentinelAddrs),
+		SentinelUsername: *sentinelUsername,
+		SentinelPassword: *sentinelPassword,
+		TLS: redisconn.TLSOptions{
+			Enabled:            *useTLS,
+			CAFile:             *tlsCAFile,
+			CertFile:           *tlsCertFile,
+			KeyFile:            *tlsKeyFile,
+			ServerName:         *tlsServerName,
+			InsecureSkipVerify: *tlsInsecure,
+		},
+	})
+	if err != nil {
+		fmt.Printf("❌ Invalid connection settings: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(client redis.UniversalClient) {
 		err := client.Close()
 		if err != nil {
 			fmt.Printf("Failed to close Redis client: %v\n", err)
@@ -208,11 +238,14 @@ func main() {
 
 		case "QUEUE-STATS":
 			if len(parts) < 2 {
-				fmt.Println("Usage: QUEUE-STATS <queue-name>")
+				fmt.Println("Usage: QUEUE-STATS <queue-name> [prefix]")
 				continue
 			}
 			queueName := parts[1]
 			prefix := "bull"
+			if len(parts) > 2 {
+				prefix = parts[2]
+			}
 
 			waiting, _ := client.LLen(ctx, fmt.Sprintf("%s:%s:wait", prefix, queueName)).Result()
 			active, _ := client.LLen(ctx, fmt.Sprintf("%s:%s:active", prefix, queueName)).Result()
@@ -348,37 +381,6 @@ func main() {
 	}
 }
 
-func newRedisClient(
-	addr string,
-	username string,
-	password string,
-	db int,
-	sentinelMaster string,
-	sentinelAddrs string,
-	sentinelUsername string,
-	sentinelPassword string,
-) *redis.Client {
-	addrs := parseList(sentinelAddrs)
-	if sentinelMaster != "" && len(addrs) > 0 {
-		return redis.NewFailoverClient(&redis.FailoverOptions{
-			MasterName:       sentinelMaster,
-			SentinelAddrs:    addrs,
-			Username:         username,
-			Password:         password,
-			SentinelUsername: sentinelUsername,
-			SentinelPassword: sentinelPassword,
-			DB:               db,
-		})
-	}
-
-	return redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Username: username,
-		Password: password,
-		DB:       db,
-	})
-}
-
 func showHelp() {
 	fmt.Print(`
 Available Commands:
@@ -392,7 +394,7 @@ Available Commands:
   TYPE <key>            - Get key type
   DBSIZE                - Get total number of keys
   FLUSHDB               - Clear all keys (⚠️ careful!)
-  QUEUE-STATS <name>    - Get BullMQ queue statistics
+  QUEUE-STATS <name> [prefix] - Get BullMQ queue statistics (prefix defaults to "bull")
   PING                  - Test connection
   HELP                  - Show this help
   QUIT/EXIT             - Exit
@@ -0,0 +1,152 @@
+// Package audit records who changed what through the dashboard and the API,
+// and finds those records again for the audit page.
+package audit
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Outcomes of an audited action.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	// OutcomeDenied is an action the identity's role did not allow.
+	OutcomeDenied = "denied"
+)
+
+// MaxJobIDs caps the job IDs kept on one event; JobCount still has them all.
+const MaxJobIDs = 1000
+
+// Event is one state-changing request.
+type Event struct {
+	// ID is the sink's identifier, such as the Redis stream entry ID. It is
+	// empty for sinks that have none.
+	ID          string    `json:"id,omitempty"`
+	Time        time.Time `json:"time"`
+	Environment string    `json:"env"`
+	// Actor is the identity's name, or "anonymous" with authentication off.
+	// Names can change; ActorSubject, the basic-auth user, token name or
+	// OIDC sub claim, identifies the same person across events.
+	Actor        string `json:"actor"`
+	ActorSubject string `json:"actorSubject,omitempty"`
+	AuthMethod   string `json:"authMethod,omitempty"`
+	RemoteAddr   string `json:"remoteAddr,omitempty"`
+	// Action is the API operation ID, e.g. "retryJob" or "cleanQueue", for
+	// dashboard and API requests alike.
+	Action   string            `json:"action"`
+	Prefix   string            `json:"prefix,omitempty"`
+	Queue    string            `json:"queue,omitempty"`
+	Jobs     []string          `json:"jobs,omitempty"`
+	JobCount int               `json:"jobCount,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+	Outcome  string            `json:"outcome"`
+	Status   int               `json:"status"`
+	Message  string            `json:"message,omitempty"`
+}
+
+// Query selects events for the audit page. Empty fields match everything.
+type Query struct {
+	Environment string
+	Queue       string
+	Outcome     string
+	// Text matches the actor and their subject and auth method, action,
+	// queue, job IDs, parameters or message, ignoring case.
+	Text string
+	// Limit caps the results; 0 means DefaultLimit.
+	Limit int
+}
+
+// DefaultLimit is how many events a search returns by default.
+const DefaultLimit = 200
+
+func (q Query) limit() int {
+	if q.Limit <= 0 {
+		return DefaultLimit
+	}
+	return q.Limit
+}
+
+// Matches reports whether e satisfies q.
+func (q Query) Matches(e Event) bool {
+	if q.Environment != "" && e.Environment != q.Environment {
+		return false
+	}
+	if q.Queue != "" && e.Queue != q.Queue {
+		return false
+	}
+	if q.Outcome != "" && e.Outcome != q.Outcome {
+		return false
+	}
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+	if text == "" {
+		return true
+	}
+	fields := []string{e.Actor, e.ActorSubject, e.AuthMethod, e.Action, e.Prefix, e.Queue, e.Message, e.RemoteAddr}
+	fields = append(fields, e.Jobs...)
+	for name, value := range e.Params {
+		fields = append(fields, name+"="+value)
+	}
+	return slices.ContainsFunc(fields, func(field string) bool {
+		return strings.Contains(strings.ToLower(field), text)
+	})
+}
+
+// Sink stores events and searches them, newest first.
+type Sink interface {
+	Write(ctx context.Context, e Event) error
+	Search(ctx context.Context, q Query) ([]Event, error)
+}
+
+// Logger records events for one environment. A nil Logger records nothing,
+// which keeps tests and disabled auditing simple.
+type Logger struct {
+	sink        Sink
+	environment string
+	now         func() time.Time
+}
+
+// New returns a Logger that writes environment's events to sink.
+func New(sink Sink, environment string) *Logger {
+	return &Logger{sink: sink, environment: environment, now: time.Now}
+}
+
+// Record stamps e with the time and environment and writes it. The action
+// has already happened, so a sink failure is logged rather than returned.
+func (l *Logger) Record(ctx context.Context, e Event) {
+	if l == nil {
+		return
+	}
+	e.Time = l.now().UTC()
+	e.Environment = l.environment
+	if e.JobCount == 0 {
+		e.JobCount = len(e.Jobs)
+	}
+	if len(e.Jobs) > MaxJobIDs {
+		e.Jobs = e.Jobs[:MaxJobIDs]
+	}
+	// Record runs after the request's work; a client that hung up must not
+	// lose the record of what it did.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := l.sink.Write(ctx, e); err != nil {
+		log.Printf("❌ audit write error (env=%s action=%s actor=%s queue=%s): %v", e.Environment, e.Action, e.Actor, e.Queue, err)
+	}
+}
+
+// Search returns this environment's events matching q, newest first.
+func (l *Logger) Search(ctx context.Context, q Query) ([]Event, error) {
+	if l == nil {
+		return nil, nil
+	}
+	q.Environment = l.environment
+	return l.sink.Search(ctx, q)
+}
+
+// Enabled reports whether events are recorded.
+func (l *Logger) Enabled() bool {
+	return l != nil
+}
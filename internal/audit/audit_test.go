@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryMatches(t *testing.T) {
+	event := Event{
+		Environment:  "prod",
+		Actor:        "alice@example.com",
+		ActorSubject: "00u1a2b3",
+		AuthMethod:   "oidc",
+		Action:       "cleanQueue",
+		Queue:        "orders",
+		Jobs:         []string{"42"},
+		Params:       map[string]string{"state": "failed", "grace": "7d"},
+		Outcome:      OutcomeSuccess,
+		Message:      "Removed 3 failed jobs",
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  bool
+	}{
+		{name: "empty", query: Query{}, want: true},
+		{name: "actor text", query: Query{Text: "ALICE"}, want: true},
+		{name: "actor subject", query: Query{Text: "00u1a2b3"}, want: true},
+		{name: "auth method", query: Query{Text: "oidc"}, want: true},
+		{name: "job id", query: Query{Text: "42"}, want: true},
+		{name: "param", query: Query{Text: "state=failed"}, want: true},
+		{name: "message", query: Query{Text: "removed 3"}, want: true},
+		{name: "queue", query: Query{Queue: "orders"}, want: true},
+		{name: "other queue", query: Query{Queue: "order"}, want: false},
+		{name: "outcome", query: Query{Outcome: OutcomeDenied}, want: false},
+		{name: "environment", query: Query{Environment: "staging"}, want: false},
+		{name: "no text match", query: Query{Text: "bob"}, want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.query.Matches(event); got != tc.want {
+				t.Fatalf("Matches(%+v) = %t, want %t", tc.query, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoggerRecordStampsAndCapsJobs(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(NewWriterSink(&out, 10), "prod")
+	logger.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+
+	jobs := make([]string, MaxJobIDs+5)
+	for i := range jobs {
+		jobs[i] = fmt.Sprint(i)
+	}
+	logger.Record(context.Background(), Event{Actor: "alice", Action: "startBulkRetry", Jobs: jobs, Outcome: OutcomeSuccess})
+
+	var got Event
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	if got.Environment != "prod" || !got.Time.Equal(logger.now()) {
+		t.Fatalf("event not stamped: %+v", got)
+	}
+	if len(got.Jobs) != MaxJobIDs || got.JobCount != MaxJobIDs+5 {
+		t.Fatalf("jobs = %d, jobCount = %d", len(got.Jobs), got.JobCount)
+	}
+}
+
+func TestNilLoggerRecordsNothing(t *testing.T) {
+	var logger *Logger
+	logger.Record(context.Background(), Event{Action: "retryJob"})
+	if events, err := logger.Search(context.Background(), Query{}); err != nil || events != nil {
+		t.Fatalf("Search = %v, %v", events, err)
+	}
+	if logger.Enabled() {
+		t.Fatal("nil logger reports enabled")
+	}
+}
+
+func TestWriterSinkSearchesRecentEventsNewestFirst(t *testing.T) {
+	sink := NewWriterSink(&bytes.Buffer{}, 3)
+	for i := range 5 {
+		if err := sink.Write(context.Background(), Event{Action: fmt.Sprint("action", i)}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	events, err := sink.Search(context.Background(), Query{Limit: 2})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(events) != 2 || events[0].Action != "action4" || events[1].Action != "action3" {
+		t.Fatalf("events = %+v", events)
+	}
+	all, _ := sink.Search(context.Background(), Query{})
+	if len(all) != 3 {
+		t.Fatalf("kept %d events, want 3", len(all))
+	}
+}
+
+func TestFileSinkAppendsAndSearches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	defer sink.Close()
+
+	for _, e := range []Event{
+		{Environment: "prod", Action: "retryJob", Queue: "orders"},
+		{Environment: "staging", Action: "retryJob", Queue: "orders"},
+		{Environment: "prod", Action: "drainQueue", Queue: "orders"},
+		{Environment: "prod", Action: "pauseQueue", Queue: "payments"},
+	} {
+		if err := sink.Write(context.Background(), e); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	// A second sink on the same file sees the earlier events.
+	reopened, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	defer reopened.Close()
+
+	events, err := reopened.Search(context.Background(), Query{Environment: "prod", Queue: "orders"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	var actions []string
+	for _, e := range events {
+		actions = append(actions, e.Action)
+	}
+	if strings.Join(actions, ",") != "drainQueue,retryJob" {
+		t.Fatalf("actions = %v", actions)
+	}
+}
@@ -0,0 +1,213 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Sink kinds for configuration.
+const (
+	SinkNone   = "none"
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkRedis  = "redis"
+)
+
+// DefaultRedisKey is the stream the Redis sink appends to. It sits outside
+// any BullMQ prefix so queue discovery never mistakes it for a queue.
+const DefaultRedisKey = "bullderdash:audit"
+
+// WriterSink writes JSON lines to w, such as stdout for a log collector. It
+// cannot read them back, so it searches the most recent events it wrote
+// since the process started.
+type WriterSink struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	recent []Event
+	keep   int
+}
+
+// NewWriterSink returns a sink writing to w and remembering the last keep
+// events for search.
+func NewWriterSink(w io.Writer, keep int) *WriterSink {
+	return &WriterSink{enc: json.NewEncoder(w), keep: keep}
+}
+
+func (s *WriterSink) Write(ctx context.Context, e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.enc.Encode(e); err != nil {
+		return err
+	}
+	s.recent = append(s.recent, e)
+	if len(s.recent) > s.keep {
+		s.recent = slices.Delete(s.recent, 0, len(s.recent)-s.keep)
+	}
+	return nil
+}
+
+func (s *WriterSink) Search(ctx context.Context, q Query) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []Event
+	for i := len(s.recent) - 1; i >= 0 && len(events) < q.limit(); i-- {
+		if q.Matches(s.recent[i]) {
+			events = append(events, s.recent[i])
+		}
+	}
+	return events, nil
+}
+
+// FileSink appends JSON lines to a file, which survives restarts and can be
+// shipped elsewhere. Search reads the whole file.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileSink opens path for appending, creating it readable only by the
+// owner.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("audit file: %w", err)
+	}
+	return &FileSink{path: path, file: file}, nil
+}
+
+func (s *FileSink) Write(ctx context.Context, e Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+func (s *FileSink) Search(ctx context.Context, q Query) ([]Event, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("audit file: %w", err)
+	}
+	defer file.Close()
+
+	// Keep the newest matches: the file is oldest first.
+	limit := q.limit()
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if !q.Matches(e) {
+			continue
+		}
+		events = append(events, e)
+		if len(events) > limit {
+			events = events[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("audit file: %w", err)
+	}
+	slices.Reverse(events)
+	return events, nil
+}
+
+// Close flushes the file to disk and closes it.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+// RedisSink appends events to a Redis stream. The stream is never trimmed:
+// retention is left to whoever owns the Redis instance.
+type RedisSink struct {
+	client redis.UniversalClient
+	key    string
+}
+
+// redisSearchBatch and redisSearchMaxScan bound how much of the stream one
+// search reads.
+const (
+	redisSearchBatch   = 500
+	redisSearchMaxScan = 50000
+)
+
+func NewRedisSink(client redis.UniversalClient, key string) *RedisSink {
+	if key == "" {
+		key = DefaultRedisKey
+	}
+	return &RedisSink{client: client, key: key}
+}
+
+func (s *RedisSink) Write(ctx context.Context, e Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.key,
+		Values: map[string]any{"event": payload},
+	}).Err()
+}
+
+// Search reads the stream newest first until it has enough matches or has
+// scanned redisSearchMaxScan entries.
+func (s *RedisSink) Search(ctx context.Context, q Query) ([]Event, error) {
+	limit := q.limit()
+	var events []Event
+	end := "+"
+	for scanned := 0; scanned < redisSearchMaxScan && len(events) < limit; {
+		entries, err := s.client.XRevRangeN(ctx, s.key, end, "-", redisSearchBatch).Result()
+		if err != nil {
+			return nil, fmt.Errorf("audit stream: %w", err)
+		}
+		for _, entry := range entries {
+			e, ok := decodeStreamEntry(entry)
+			if ok && q.Matches(e) {
+				events = append(events, e)
+				if len(events) == limit {
+					break
+				}
+			}
+		}
+		if len(entries) < redisSearchBatch {
+			break
+		}
+		scanned += len(entries)
+		end = "(" + entries[len(entries)-1].ID
+	}
+	return events, nil
+}
+
+func decodeStreamEntry(entry redis.XMessage) (Event, bool) {
+	payload, ok := entry.Values["event"].(string)
+	if !ok {
+		return Event{}, false
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(payload), &e); err != nil {
+		return Event{}, false
+	}
+	e.ID = entry.ID
+	return e, true
+}
@@ -0,0 +1,337 @@
+// Package auth authenticates dashboard and API requests with static
+// basic-auth users, bearer API tokens or OIDC login sessions, and grants
+// the identities they yield roles per queue.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Methods that can be enabled, alone or together.
+const (
+	MethodBasic = "basic"
+	MethodToken = "token"
+	MethodOIDC  = "oidc"
+)
+
+// Paths the middleware serves itself when OIDC is enabled, under
+// Options.BasePath. The callback path comes from OIDCOptions.RedirectURL.
+const (
+	LoginPath  = "/auth/login"
+	LogoutPath = "/auth/logout"
+)
+
+const realm = "Bull-der-dash"
+
+// Identity is who a request was authenticated as.
+type Identity struct {
+	// Subject is the basic-auth user name, the token name or the OIDC
+	// subject claim.
+	Subject string `json:"sub"`
+	// Name is what the UI shows: the user or token name, or the configured
+	// OIDC username claim.
+	Name   string `json:"name"`
+	Method string `json:"method"`
+	// Email is the OIDC email claim, set only when the provider marks it
+	// verified.
+	Email string `json:"email,omitempty"`
+	// Groups are the OIDC groups claim.
+	Groups []string `json:"groups,omitempty"`
+	// Scopes are an API token's scopes.
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+type identityContextKey struct{}
+
+// WithIdentity returns ctx carrying id.
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, id)
+}
+
+// FromContext returns the request's identity, or nil when authentication is
+// off or the path is public.
+func FromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityContextKey{}).(*Identity)
+	return id
+}
+
+// authenticator checks one kind of credential. It returns nil and no error
+// when the request carries none of that kind, and an error when it carries
+// bad ones.
+type authenticator interface {
+	authenticate(r *http.Request) (*Identity, error)
+}
+
+var errInvalidCredentials = errors.New("invalid credentials")
+
+// Options configure the middleware. With no Methods it lets every request
+// through.
+type Options struct {
+	Methods []string
+
+	// BasicUsers are htpasswd lines, "user:<bcrypt hash>"; BasicUsersFile is
+	// an htpasswd file with more of them.
+	BasicUsers     []string
+	BasicUsersFile string
+
+	// APITokens are "name:token" or "name:token:scope scope..." entries;
+	// APITokensFile has one per line.
+	APITokens     []string
+	APITokensFile string
+
+	OIDC OIDCOptions
+
+	// SessionSecret signs OIDC session cookies. Every replica needs the same
+	// one.
+	SessionSecret string
+	SessionTTL    time.Duration
+
+	// Public reports paths served without credentials, such as probes.
+	Public func(path string) bool
+
+	// Policy limits what each identity may do per queue; nil allows
+	// everything.
+	Policy *Policy
+
+	// BasePath is the prefix the dashboard is mounted under, e.g.
+	// "/tools/bullmq". Requests reach the middleware with it stripped; login
+	// redirects and session cookies put it back.
+	BasePath string
+
+	// TrustedOrigins are other origins, "scheme://host[:port]", whose pages
+	// may send state-changing requests, e.g. when a proxy rewrites the Host
+	// header.
+	TrustedOrigins []string
+}
+
+// Middleware requires credentials on every non-public request and puts the
+// Identity in the request context.
+type Middleware struct {
+	authenticators []authenticator
+	basic          *basicAuth
+	tokens         *tokenAuth
+	oidc           *oidcAuth
+	public         func(path string) bool
+	policy         *Policy
+	basePath       string
+	crossOrigin    *http.CrossOriginProtection
+}
+
+// New builds the middleware for opts, reading the users and tokens files.
+func New(opts Options) (*Middleware, error) {
+	m := &Middleware{public: opts.Public, policy: opts.Policy, basePath: opts.BasePath}
+	crossOrigin, err := newCrossOriginProtection(opts.TrustedOrigins)
+	if err != nil {
+		return nil, err
+	}
+	m.crossOrigin = crossOrigin
+	for _, method := range opts.Methods {
+		switch strings.ToLower(strings.TrimSpace(method)) {
+		case "", "none":
+		case MethodBasic:
+			basic, err := newBasicAuth(opts.BasicUsers, opts.BasicUsersFile)
+			if err != nil {
+				return nil, err
+			}
+			m.basic = basic
+		case MethodToken:
+			tokens, err := newTokenAuth(opts.APITokens, opts.APITokensFile)
+			if err != nil {
+				return nil, err
+			}
+			m.tokens = tokens
+		case MethodOIDC:
+			oidc, err := newOIDCAuth(opts.OIDC, opts.SessionSecret, opts.SessionTTL, opts.BasePath)
+			if err != nil {
+				return nil, err
+			}
+			m.oidc = oidc
+		default:
+			return nil, fmt.Errorf("unknown authentication method %q: use basic, token or oidc", method)
+		}
+	}
+
+	// Tokens first: API clients that also hold a session cookie act as the
+	// token.
+	if m.tokens != nil {
+		m.authenticators = append(m.authenticators, m.tokens)
+	}
+	if m.basic != nil {
+		m.authenticators = append(m.authenticators, m.basic)
+	}
+	if m.oidc != nil {
+		m.authenticators = append(m.authenticators, m.oidc)
+	}
+	if m.policy != nil && !m.Enabled() {
+		return nil, errors.New("RBAC grants need an authentication method to identify users")
+	}
+	return m, nil
+}
+
+// Enabled reports whether any method is on.
+func (m *Middleware) Enabled() bool {
+	return len(m.authenticators) > 0
+}
+
+// Methods lists the enabled methods, for logging.
+func (m *Middleware) Methods() []string {
+	var methods []string
+	if m.basic != nil {
+		methods = append(methods, MethodBasic)
+	}
+	if m.tokens != nil {
+		methods = append(methods, MethodToken)
+	}
+	if m.oidc != nil {
+		methods = append(methods, MethodOIDC)
+	}
+	return methods
+}
+
+// Wrap returns next behind authentication, with the identity and policy in
+// the request context. With OIDC it also serves the login, callback and
+// logout paths.
+//
+// Whatever the methods, and with none, state-changing requests sent from
+// another site's page are refused first: browsers replay basic-auth
+// credentials to them, and only the OIDC cookie is SameSite.
+func (m *Middleware) Wrap(next http.Handler) http.Handler {
+	if !m.Enabled() {
+		return m.crossOrigin.Handler(next)
+	}
+	return m.crossOrigin.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.oidc != nil {
+			switch r.URL.Path {
+			case LoginPath:
+				m.oidc.login(w, r)
+				return
+			case m.oidc.callbackPath:
+				m.oidc.callback(w, r)
+				return
+			case LogoutPath:
+				m.oidc.logout(w, r)
+				return
+			}
+		}
+		if m.public != nil && m.public(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id, err := m.authenticate(r)
+		if err != nil {
+			log.Printf("⚠️ authentication failed (%s %s from %s): %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+		}
+		if id == nil {
+			m.challenge(w, r)
+			return
+		}
+		ctx := WithIdentity(r.Context(), id)
+		if m.policy != nil {
+			ctx = WithPolicy(ctx, m.policy)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}))
+}
+
+// newCrossOriginProtection refuses non-GET requests whose Sec-Fetch-Site or
+// Origin header names another site. Scripts and API clients send neither
+// and are unaffected.
+func newCrossOriginProtection(trustedOrigins []string) (*http.CrossOriginProtection, error) {
+	protection := http.NewCrossOriginProtection()
+	for _, origin := range trustedOrigins {
+		if err := protection.AddTrustedOrigin(origin); err != nil {
+			return nil, fmt.Errorf("invalid trusted origin: %w", err)
+		}
+	}
+	protection.SetDenyHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("⚠️ cross-origin %s %s refused (Origin=%q Sec-Fetch-Site=%q)", r.Method, r.URL.Path, r.Header.Get("Origin"), r.Header.Get("Sec-Fetch-Site"))
+		if isAPIRequest(r) {
+			writeJSONError(w, http.StatusForbidden, "forbidden", "cross-origin request refused")
+			return
+		}
+		http.Error(w, "cross-origin request refused", http.StatusForbidden)
+	}))
+	return protection, nil
+}
+
+func (m *Middleware) authenticate(r *http.Request) (*Identity, error) {
+	for _, a := range m.authenticators {
+		id, err := a.authenticate(r)
+		if err != nil || id != nil {
+			return id, err
+		}
+	}
+	return nil, nil
+}
+
+// challenge answers an unauthenticated request: browsers go to the OIDC
+// login, htmx requests are told to, and everything else gets a 401 naming
+// the schemes it can use.
+func (m *Middleware) challenge(w http.ResponseWriter, r *http.Request) {
+	if m.oidc != nil && !isAPIRequest(r) {
+		if r.Header.Get("HX-Request") == "true" {
+			next := "/"
+			if current, err := url.Parse(r.Header.Get("HX-Current-URL")); err == nil {
+				next = strings.TrimPrefix(current.RequestURI(), m.basePath)
+			}
+			w.Header().Set("HX-Redirect", loginURL(m.basePath, next))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if (r.Method == http.MethodGet || r.Method == http.MethodHead) && strings.Contains(r.Header.Get("Accept"), "text/html") {
+			http.Redirect(w, r, loginURL(m.basePath, r.URL.RequestURI()), http.StatusFound)
+			return
+		}
+	}
+
+	if m.basic != nil {
+		w.Header().Add("WWW-Authenticate", `Basic realm="`+realm+`", charset="UTF-8"`)
+	}
+	if m.tokens != nil {
+		w.Header().Add("WWW-Authenticate", `Bearer realm="`+realm+`"`)
+	}
+	if isAPIRequest(r) {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+	http.Error(w, "authentication required", http.StatusUnauthorized)
+}
+
+// isAPIRequest reports requests that want JSON rather than a page.
+func isAPIRequest(r *http.Request) bool {
+	return strings.Contains(r.URL.Path, "/api/") ||
+		r.URL.Query().Get("format") == "json" ||
+		strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// writeJSONError writes the JSON API's error shape.
+func writeJSONError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{"status": status, "code": code, "message": message},
+	})
+}
+
+// loginURL is the login path under basePath; next is relative to basePath.
+func loginURL(basePath, next string) string {
+	return basePath + LoginPath + "?next=" + url.QueryEscape(next)
+}
+
+// safeRedirect keeps post-login redirects on this site. Its result is
+// relative to the base path.
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
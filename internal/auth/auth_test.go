@@ -0,0 +1,323 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func htpasswd(t *testing.T, user, password string) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+	return user + ":" + string(hash)
+}
+
+// whoami answers with the authenticated name, or "anonymous".
+var whoami = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if id := FromContext(r.Context()); id != nil {
+		w.Write([]byte(id.Method + ":" + id.Name + ":" + strings.Join(append(id.Groups, id.Scopes...), ",")))
+		return
+	}
+	w.Write([]byte("anonymous"))
+})
+
+func newTestMiddleware(t *testing.T, opts Options) http.Handler {
+	t.Helper()
+	m, err := New(opts)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	return m.Wrap(whoami)
+}
+
+func TestMiddlewareStaticCredentials(t *testing.T) {
+	handler := newTestMiddleware(t, Options{
+		Methods:    []string{"basic", "token"},
+		BasicUsers: []string{htpasswd(t, "alice", "wonderland")},
+		APITokens:  []string{"ci:s3cret:ops billing"},
+		Public:     func(path string) bool { return path == "/healthz" },
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(r *http.Request)
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "basic", path: "/", setup: func(r *http.Request) { r.SetBasicAuth("alice", "wonderland") }, wantStatus: http.StatusOK, wantBody: "basic:alice:"},
+		{name: "basic again from cache", path: "/", setup: func(r *http.Request) { r.SetBasicAuth("alice", "wonderland") }, wantStatus: http.StatusOK, wantBody: "basic:alice:"},
+		{name: "wrong password", path: "/", setup: func(r *http.Request) { r.SetBasicAuth("alice", "queen") }, wantStatus: http.StatusUnauthorized},
+		{name: "unknown user", path: "/", setup: func(r *http.Request) { r.SetBasicAuth("bob", "wonderland") }, wantStatus: http.StatusUnauthorized},
+		{name: "bearer", path: "/", setup: func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }, wantStatus: http.StatusOK, wantBody: "token:ci:ops,billing"},
+		{name: "wrong bearer", path: "/", setup: func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, wantStatus: http.StatusUnauthorized},
+		{name: "no credentials", path: "/", setup: func(r *http.Request) {}, wantStatus: http.StatusUnauthorized},
+		{name: "public path", path: "/healthz", setup: func(r *http.Request) {}, wantStatus: http.StatusOK, wantBody: "anonymous"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			tc.setup(req)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status mismatch: got %d want %d", rec.Code, tc.wantStatus)
+			}
+			if tc.wantBody != "" && rec.Body.String() != tc.wantBody {
+				t.Fatalf("body mismatch: got %q want %q", rec.Body.String(), tc.wantBody)
+			}
+			if rec.Code == http.StatusUnauthorized && len(rec.Header().Values("WWW-Authenticate")) != 2 {
+				t.Fatalf("expected Basic and Bearer challenges, got %v", rec.Header().Values("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestBasicAuthCacheHoldsOnePasswordPerUser(t *testing.T) {
+	b, err := newBasicAuth([]string{htpasswd(t, "alice", "wonderland")}, "")
+	if err != nil {
+		t.Fatalf("newBasicAuth: %v", err)
+	}
+	login := func(user, password string) error {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(user, password)
+		_, err := b.authenticate(req)
+		return err
+	}
+
+	for range 3 {
+		if err := login("alice", "wonderland"); err != nil {
+			t.Fatalf("alice: %v", err)
+		}
+	}
+	for i := range 3 {
+		if err := login(fmt.Sprintf("user%d", i), "wonderland"); err == nil {
+			t.Fatal("unknown user accepted")
+		}
+	}
+	if err := login("alice", "queen"); err == nil {
+		t.Fatal("a wrong password passed after a cached login")
+	}
+	if len(b.verified) != 1 {
+		t.Fatalf("cache has %d entries, want 1", len(b.verified))
+	}
+
+	// An expired entry is checked with bcrypt again.
+	b.verified["alice"] = verifiedPassword{digest: b.verified["alice"].digest, expires: time.Now().Add(-time.Second)}
+	if err := login("alice", "wonderland"); err != nil {
+		t.Fatalf("alice after expiry: %v", err)
+	}
+	if !b.verified["alice"].expires.After(time.Now()) {
+		t.Fatal("expired entry was not refreshed")
+	}
+}
+
+func TestMiddlewareAPIChallengeIsJSON(t *testing.T) {
+	handler := newTestMiddleware(t, Options{Methods: []string{"token"}, APITokens: []string{"ci:s3cret"}})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/env/prod/api/v1/queues", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status mismatch: got %d", rec.Code)
+	}
+	var body struct {
+		Error struct {
+			Status int    `json:"status"`
+			Code   string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Error.Status != http.StatusUnauthorized || body.Error.Code != "unauthorized" {
+		t.Fatalf("unexpected error body: %+v", body.Error)
+	}
+}
+
+func TestMiddlewareOIDCChallenge(t *testing.T) {
+	handler := newTestMiddleware(t, Options{
+		Methods:       []string{"oidc"},
+		OIDC:          OIDCOptions{IssuerURL: "https://idp.example.com", ClientID: "bullderdash", RedirectURL: "https://dash.example.com/auth/callback"},
+		SessionSecret: strings.Repeat("k", minSessionSecret),
+	})
+
+	page := httptest.NewRequest(http.MethodGet, "/queue/orders?prefix=bull", nil)
+	page.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, page)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/auth/login?next=%2Fqueue%2Forders%3Fprefix%3Dbull" {
+		t.Fatalf("expected a login redirect, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	fragment := httptest.NewRequest(http.MethodGet, "/queues", nil)
+	fragment.Header.Set("HX-Request", "true")
+	fragment.Header.Set("HX-Current-URL", "https://dash.example.com/search?q=x")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, fragment)
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("HX-Redirect") != "/auth/login?next=%2Fsearch%3Fq%3Dx" {
+		t.Fatalf("expected an htmx login redirect, got %d %q", rec.Code, rec.Header().Get("HX-Redirect"))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/job/retry", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a POST, got %d", rec.Code)
+	}
+}
+
+func TestMiddlewareOIDCUnderBasePath(t *testing.T) {
+	handler := newTestMiddleware(t, Options{
+		Methods:       []string{"oidc"},
+		OIDC:          OIDCOptions{IssuerURL: "https://idp.example.com", ClientID: "bullderdash", RedirectURL: "https://gw.example.com/tools/bullmq/auth/callback"},
+		SessionSecret: strings.Repeat("k", minSessionSecret),
+		BasePath:      "/tools/bullmq",
+	})
+
+	// Requests arrive with the base path already stripped.
+	page := httptest.NewRequest(http.MethodGet, "/queue/orders", nil)
+	page.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, page)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/tools/bullmq/auth/login?next=%2Fqueue%2Forders" {
+		t.Fatalf("expected a login redirect under the base path, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	fragment := httptest.NewRequest(http.MethodGet, "/queues", nil)
+	fragment.Header.Set("HX-Request", "true")
+	fragment.Header.Set("HX-Current-URL", "https://gw.example.com/tools/bullmq/search?q=x")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, fragment)
+	if rec.Header().Get("HX-Redirect") != "/tools/bullmq/auth/login?next=%2Fsearch%3Fq%3Dx" {
+		t.Fatalf("expected an htmx login redirect under the base path, got %q", rec.Header().Get("HX-Redirect"))
+	}
+
+	// A callback without a login in progress starts over at the login path.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/callback?code=x", nil))
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/tools/bullmq/auth/login" {
+		t.Fatalf("expected the callback to restart the login, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestMiddlewareRefusesCrossSitePosts(t *testing.T) {
+	basic := htpasswd(t, "alice", "wonderland")
+	modes := map[string]Options{
+		// Browsers replay basic credentials to any request for the site.
+		"basic": {Methods: []string{"basic"}, BasicUsers: []string{basic}},
+		"off":   {},
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		header   map[string]string
+		wantCode int
+	}{
+		// A hidden form on another site.
+		{name: "cross-site form", method: http.MethodPost, header: map[string]string{"Sec-Fetch-Site": "cross-site", "Origin": "https://evil.example"}, wantCode: http.StatusForbidden},
+		{name: "origin only", method: http.MethodPost, header: map[string]string{"Origin": "https://evil.example"}, wantCode: http.StatusForbidden},
+		{name: "trusted origin", method: http.MethodPost, header: map[string]string{"Sec-Fetch-Site": "cross-site", "Origin": "https://proxy.example"}, wantCode: http.StatusOK},
+		{name: "cross-site link", method: http.MethodGet, header: map[string]string{"Sec-Fetch-Site": "cross-site"}, wantCode: http.StatusOK},
+		{name: "same origin", method: http.MethodPost, header: map[string]string{"Sec-Fetch-Site": "same-origin", "Origin": "http://example.com"}, wantCode: http.StatusOK},
+		{name: "script", method: http.MethodPost, wantCode: http.StatusOK},
+	}
+	for mode, opts := range modes {
+		opts.TrustedOrigins = []string{"https://proxy.example"}
+		handler := newTestMiddleware(t, opts)
+		for _, tc := range tests {
+			t.Run(mode+"/"+tc.name, func(t *testing.T) {
+				req := httptest.NewRequest(tc.method, "/queue/drain?queue=orders&confirm=orders", nil)
+				req.SetBasicAuth("alice", "wonderland")
+				for name, value := range tc.header {
+					req.Header.Set(name, value)
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				if rec.Code != tc.wantCode {
+					t.Fatalf("status = %d, want %d (%s)", rec.Code, tc.wantCode, rec.Body.String())
+				}
+			})
+		}
+	}
+
+	// API clients are told in the API's error shape.
+	handler := newTestMiddleware(t, Options{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/queues/orders/drain", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), `"forbidden"`) {
+		t.Fatalf("api refusal = %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestNewRejectsBadConfiguration(t *testing.T) {
+	dir := t.TempDir()
+	usersFile := filepath.Join(dir, "htpasswd")
+	if err := os.WriteFile(usersFile, []byte("# team\n"+htpasswd(t, "alice", "pw")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "off", opts: Options{}},
+		{name: "users file", opts: Options{Methods: []string{"basic"}, BasicUsersFile: usersFile}},
+		{name: "unknown method", opts: Options{Methods: []string{"saml"}}, wantErr: true},
+		{name: "no users", opts: Options{Methods: []string{"basic"}}, wantErr: true},
+		{name: "plaintext password", opts: Options{Methods: []string{"basic"}, BasicUsers: []string{"alice:pw"}}, wantErr: true},
+		{name: "missing users file", opts: Options{Methods: []string{"basic"}, BasicUsersFile: filepath.Join(dir, "nope")}, wantErr: true},
+		{name: "no tokens", opts: Options{Methods: []string{"token"}}, wantErr: true},
+		{name: "duplicate token", opts: Options{Methods: []string{"token"}, APITokens: []string{"ci:a", "ci:b"}}, wantErr: true},
+		{name: "oidc without issuer", opts: Options{Methods: []string{"oidc"}, SessionSecret: strings.Repeat("k", minSessionSecret)}, wantErr: true},
+		{name: "oidc callback outside base path", opts: Options{
+			Methods:       []string{"oidc"},
+			OIDC:          OIDCOptions{IssuerURL: "https://idp.example.com", ClientID: "c", RedirectURL: "https://dash.example.com/auth/callback"},
+			SessionSecret: strings.Repeat("k", minSessionSecret),
+			BasePath:      "/tools/bullmq",
+		}, wantErr: true},
+		{name: "bad trusted origin", opts: Options{TrustedOrigins: []string{"dash.example.com"}}, wantErr: true},
+		{name: "oidc short secret", opts: Options{
+			Methods: []string{"oidc"},
+			OIDC:    OIDCOptions{IssuerURL: "https://idp.example.com", ClientID: "c", RedirectURL: "https://dash.example.com/auth/callback"},
+		}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(tc.opts)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("New error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSafeRedirect(t *testing.T) {
+	tests := map[string]string{
+		"/queue/orders?x=1":    "/queue/orders?x=1",
+		"":                     "/",
+		"https://evil.example": "/",
+		"//evil.example":       "/",
+		"/\\evil.example":      "/",
+	}
+	for next, want := range tests {
+		if got := safeRedirect(next); got != want {
+			t.Errorf("safeRedirect(%q) = %q, want %q", next, got, want)
+		}
+	}
+}
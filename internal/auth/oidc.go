@@ -0,0 +1,386 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+const (
+	sessionCookie = "bullderdash_session"
+	// loginCookie carries the state, nonce and PKCE verifier from the login
+	// redirect to the callback.
+	loginCookie   = "bullderdash_login"
+	loginTTL      = 10 * time.Minute
+	discoveryWait = 10 * time.Second
+	// minSessionSecret is the shortest session secret accepted, in bytes.
+	minSessionSecret = 32
+)
+
+// OIDCOptions configure the authorization code flow against an OpenID
+// Connect provider.
+type OIDCOptions struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback registered with the provider, e.g.
+	// https://bullderdash.example.com/auth/callback. Its path is served by
+	// the middleware, so it must be under the base path.
+	RedirectURL string
+	Scopes      []string
+	// UsernameClaim names the claim shown as the user's name; the subject
+	// is used when it is missing.
+	UsernameClaim string
+	GroupsClaim   string
+}
+
+// oidcAuth logs users in with the provider and keeps them signed in with an
+// HMAC-signed session cookie, so no session store is needed.
+type oidcAuth struct {
+	opts OIDCOptions
+	// callbackPath is RedirectURL's path without the base path.
+	callbackPath string
+	basePath     string
+	secure       bool
+	sessions     cookieCodec
+	ttl          time.Duration
+
+	// The provider is discovered on first use, so a provider outage does
+	// not stop the dashboard from starting.
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func newOIDCAuth(opts OIDCOptions, secret string, ttl time.Duration, basePath string) (*oidcAuth, error) {
+	if opts.IssuerURL == "" || opts.ClientID == "" || opts.RedirectURL == "" {
+		return nil, errors.New("OIDC needs an issuer URL, a client ID and a redirect URL")
+	}
+	redirect, err := url.Parse(opts.RedirectURL)
+	if err != nil || !redirect.IsAbs() || redirect.Path == "" {
+		return nil, fmt.Errorf("OIDC redirect URL %q must be an absolute URL with a path", opts.RedirectURL)
+	}
+	callbackPath, ok := strings.CutPrefix(redirect.Path, basePath)
+	if !ok || !strings.HasPrefix(callbackPath, "/") {
+		return nil, fmt.Errorf("OIDC redirect URL %q must be under the base path %q", opts.RedirectURL, basePath)
+	}
+	if len(secret) < minSessionSecret {
+		return nil, fmt.Errorf("OIDC needs a session secret of at least %d bytes", minSessionSecret)
+	}
+	if ttl <= 0 {
+		ttl = 8 * time.Hour
+	}
+	if len(opts.Scopes) == 0 {
+		opts.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+	if opts.GroupsClaim == "" {
+		opts.GroupsClaim = "groups"
+	}
+	return &oidcAuth{
+		opts:         opts,
+		callbackPath: callbackPath,
+		basePath:     basePath,
+		secure:       redirect.Scheme == "https",
+		sessions:     newCookieCodec(secret),
+		ttl:          ttl,
+	}, nil
+}
+
+func (o *oidcAuth) discover() (*oidc.Provider, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.provider != nil {
+		return o.provider, nil
+	}
+	// The context outlives this call: the provider fetches signing keys
+	// with it later.
+	ctx := oidc.ClientContext(context.Background(), &http.Client{Timeout: discoveryWait})
+	provider, err := oidc.NewProvider(ctx, o.opts.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC discovery (%s): %w", o.opts.IssuerURL, err)
+	}
+	o.provider = provider
+	return provider, nil
+}
+
+func (o *oidcAuth) oauth2Config(provider *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     o.opts.ClientID,
+		ClientSecret: o.opts.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  o.opts.RedirectURL,
+		Scopes:       o.opts.Scopes,
+	}
+}
+
+type loginState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Next     string `json:"next"`
+}
+
+func (o *oidcAuth) login(w http.ResponseWriter, r *http.Request) {
+	provider, err := o.discover()
+	if err != nil {
+		log.Printf("❌ %v", err)
+		http.Error(w, "identity provider unavailable", http.StatusBadGateway)
+		return
+	}
+
+	state := loginState{
+		State:    rand.Text(),
+		Nonce:    rand.Text(),
+		Verifier: oauth2.GenerateVerifier(),
+		Next:     safeRedirect(r.URL.Query().Get("next")),
+	}
+	value, err := o.sessions.encode(loginCookie, state, loginTTL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	o.setCookie(w, loginCookie, value, loginTTL)
+
+	target := o.oauth2Config(provider).AuthCodeURL(state.State, oidc.Nonce(state.Nonce), oauth2.S256ChallengeOption(state.Verifier))
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+func (o *oidcAuth) callback(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(loginCookie)
+	var state loginState
+	if err == nil {
+		err = o.sessions.decode(loginCookie, cookie.Value, &state)
+	}
+	if err != nil {
+		// Usually a stale tab or a callback replayed after the login
+		// expired; starting over fixes it.
+		http.Redirect(w, r, o.basePath+LoginPath, http.StatusFound)
+		return
+	}
+	o.setCookie(w, loginCookie, "", -1)
+
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		log.Printf("⚠️ OIDC login refused: %s %s", providerErr, query.Get("error_description"))
+		http.Error(w, "login failed: "+providerErr, http.StatusUnauthorized)
+		return
+	}
+	if query.Get("state") != state.State {
+		http.Error(w, "login failed: state mismatch", http.StatusBadRequest)
+		return
+	}
+
+	id, err := o.exchange(r.Context(), query.Get("code"), state)
+	if err != nil {
+		log.Printf("❌ OIDC login error: %v", err)
+		http.Error(w, "login failed", http.StatusUnauthorized)
+		return
+	}
+	value, err := o.sessions.encode(sessionCookie, id, o.ttl)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	o.setCookie(w, sessionCookie, value, o.ttl)
+	log.Printf("🔑 OIDC login: %s (sub=%s)", id.Name, id.Subject)
+	http.Redirect(w, r, o.basePath+state.Next, http.StatusFound)
+}
+
+// exchange trades the authorization code for tokens and builds the identity
+// from the verified ID token.
+func (o *oidcAuth) exchange(ctx context.Context, code string, state loginState) (*Identity, error) {
+	provider, err := o.discover()
+	if err != nil {
+		return nil, err
+	}
+	ctx = oidc.ClientContext(ctx, &http.Client{Timeout: discoveryWait})
+	token, err := o.oauth2Config(provider).Exchange(ctx, code, oauth2.VerifierOption(state.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("code exchange: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("token response has no id_token")
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: o.opts.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("verify id_token: %w", err)
+	}
+	if idToken.Nonce != state.Nonce {
+		return nil, errors.New("id_token nonce mismatch")
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	return identityFromClaims(idToken.Subject, claims, o.opts.UsernameClaim, o.opts.GroupsClaim), nil
+}
+
+func identityFromClaims(subject string, claims map[string]any, usernameClaim, groupsClaim string) *Identity {
+	id := &Identity{Subject: subject, Name: subject, Method: MethodOIDC}
+	for _, claim := range []string{usernameClaim, "email", "preferred_username"} {
+		if name, ok := claims[claim].(string); ok && claim != "" && name != "" {
+			id.Name = name
+			break
+		}
+	}
+	if email, ok := claims["email"].(string); ok && email != "" && emailVerified(claims["email_verified"]) {
+		id.Email = email
+	}
+	switch groups := claims[groupsClaim].(type) {
+	case string:
+		id.Groups = []string{groups}
+	case []any:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				id.Groups = append(id.Groups, name)
+			}
+		}
+	}
+	return id
+}
+
+// emailVerified reads the email_verified claim, which some providers send
+// as a string.
+func emailVerified(claim any) bool {
+	switch verified := claim.(type) {
+	case bool:
+		return verified
+	case string:
+		return verified == "true"
+	default:
+		return false
+	}
+}
+
+func (o *oidcAuth) logout(w http.ResponseWriter, r *http.Request) {
+	o.setCookie(w, sessionCookie, "", -1)
+
+	// Ending the provider's session too stops the next visit from signing
+	// straight back in.
+	target := o.basePath + "/"
+	if provider, err := o.discover(); err == nil {
+		var metadata struct {
+			EndSessionEndpoint string `json:"end_session_endpoint"`
+		}
+		if provider.Claims(&metadata) == nil && metadata.EndSessionEndpoint != "" {
+			target = metadata.EndSessionEndpoint + "?client_id=" + url.QueryEscape(o.opts.ClientID)
+		}
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+func (o *oidcAuth) authenticate(r *http.Request) (*Identity, error) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil, nil
+	}
+	var id Identity
+	if err := o.sessions.decode(sessionCookie, cookie.Value, &id); err != nil {
+		// An expired session sends the user back through login rather
+		// than failing the request.
+		if errors.Is(err, errSessionExpired) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("session cookie: %w", err)
+	}
+	return &id, nil
+}
+
+func (o *oidcAuth) setCookie(w http.ResponseWriter, name, value string, ttl time.Duration) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     o.basePath + "/",
+		HttpOnly: true,
+		Secure:   o.secure,
+		// Lax keeps the cookie off cross-site POSTs, which is what stops
+		// other sites from triggering actions.
+		SameSite: http.SameSiteLaxMode,
+	}
+	if ttl < 0 {
+		cookie.MaxAge = -1
+	} else {
+		cookie.MaxAge = int(ttl.Seconds())
+	}
+	http.SetCookie(w, cookie)
+}
+
+var (
+	errSessionInvalid = errors.New("invalid signature")
+	errSessionExpired = errors.New("expired")
+)
+
+// cookieCodec signs cookie values: base64(JSON payload) "." base64(HMAC).
+// The payload names the cookie it was made for, so a login cookie cannot be
+// passed off as a session.
+type cookieCodec struct {
+	key []byte
+}
+
+type signedValue struct {
+	Cookie  string          `json:"c"`
+	Expires int64           `json:"exp"`
+	Value   json.RawMessage `json:"v"`
+}
+
+func newCookieCodec(secret string) cookieCodec {
+	key := sha256.Sum256([]byte(secret))
+	return cookieCodec{key: key[:]}
+}
+
+func (c cookieCodec) encode(name string, value any, ttl time.Duration) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(signedValue{Cookie: name, Expires: time.Now().Add(ttl).Unix(), Value: raw})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.sign(encoded)), nil
+}
+
+func (c cookieCodec) decode(name, cookie string, value any) error {
+	encoded, signature, ok := strings.Cut(cookie, ".")
+	if !ok {
+		return errSessionInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(encoded)) {
+		return errSessionInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return errSessionInvalid
+	}
+	var signed signedValue
+	if err := json.Unmarshal(payload, &signed); err != nil || signed.Cookie != name {
+		return errSessionInvalid
+	}
+	if time.Now().Unix() >= signed.Expires {
+		return errSessionExpired
+	}
+	return json.Unmarshal(signed.Value, value)
+}
+
+func (c cookieCodec) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCookieCodec(t *testing.T) {
+	codec := newCookieCodec(strings.Repeat("k", minSessionSecret))
+	id := Identity{Subject: "123", Name: "alice@example.com", Method: MethodOIDC, Groups: []string{"ops"}}
+
+	value, err := codec.encode(sessionCookie, id, time.Hour)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	var decoded Identity
+	if err := codec.decode(sessionCookie, value, &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if decoded.Name != id.Name || !slices.Equal(decoded.Groups, id.Groups) {
+		t.Fatalf("round trip mismatch: %+v", decoded)
+	}
+
+	if err := codec.decode(loginCookie, value, &decoded); !errors.Is(err, errSessionInvalid) {
+		t.Fatalf("expected a session cookie to be refused as a login cookie, got %v", err)
+	}
+	if err := newCookieCodec(strings.Repeat("x", minSessionSecret)).decode(sessionCookie, value, &decoded); !errors.Is(err, errSessionInvalid) {
+		t.Fatalf("expected another secret to be refused, got %v", err)
+	}
+	payload, signature, _ := strings.Cut(value, ".")
+	if err := codec.decode(sessionCookie, payload+"x."+signature, &decoded); !errors.Is(err, errSessionInvalid) {
+		t.Fatalf("expected a tampered payload to be refused, got %v", err)
+	}
+
+	expired, err := codec.encode(sessionCookie, id, -time.Second)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if err := codec.decode(sessionCookie, expired, &decoded); !errors.Is(err, errSessionExpired) {
+		t.Fatalf("expected an expired session, got %v", err)
+	}
+}
+
+func TestOIDCSessionCookieAuthenticates(t *testing.T) {
+	secret := strings.Repeat("k", minSessionSecret)
+	handler := newTestMiddleware(t, Options{
+		Methods:       []string{"oidc"},
+		OIDC:          OIDCOptions{IssuerURL: "https://idp.example.com", ClientID: "bullderdash", RedirectURL: "https://dash.example.com/auth/callback"},
+		SessionSecret: secret,
+	})
+	value, err := newCookieCodec(secret).encode(sessionCookie, Identity{Subject: "123", Name: "alice", Method: MethodOIDC}, time.Hour)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookie, Value: value})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "oidc:alice:" {
+		t.Fatalf("expected the session to authenticate, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestIdentityFromClaims(t *testing.T) {
+	id := identityFromClaims("123", map[string]any{
+		"preferred_username": "alice",
+		"email":              "alice@example.com",
+		"roles":              []any{"ops", "billing", 7},
+	}, "preferred_username", "roles")
+	if id.Name != "alice" || !slices.Equal(id.Groups, []string{"ops", "billing"}) {
+		t.Fatalf("unexpected identity: %+v", id)
+	}
+	if id.Email != "" {
+		t.Fatalf("expected an unverified email to be dropped, got %q", id.Email)
+	}
+
+	for _, verified := range []any{true, "true"} {
+		id = identityFromClaims("123", map[string]any{"email": "alice@example.com", "email_verified": verified}, "", "groups")
+		if id.Email != "alice@example.com" {
+			t.Fatalf("expected email_verified=%v to keep the email, got %+v", verified, id)
+		}
+	}
+
+	id = identityFromClaims("123", map[string]any{"groups": "ops"}, "", "groups")
+	if id.Name != "123" || !slices.Equal(id.Groups, []string{"ops"}) {
+		t.Fatalf("expected the subject and a single group, got %+v", id)
+	}
+}
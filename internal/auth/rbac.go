@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Role is what an identity may do to a queue. Each role includes the ones
+// below it.
+type Role int
+
+const (
+	RoleNone Role = iota
+	// RoleViewer reads queues, jobs, logs and events.
+	RoleViewer
+	// RoleOperator also retries, promotes and removes jobs, pauses and
+	// resumes queues and starts bulk retries.
+	RoleOperator
+	// RoleAdmin also cleans, drains and obliterates queues and removes job
+	// schedulers.
+	RoleAdmin
+)
+
+var roleNames = []string{"none", "viewer", "operator", "admin"}
+
+func (r Role) String() string {
+	if r < RoleNone || int(r) >= len(roleNames) {
+		return fmt.Sprintf("Role(%d)", int(r))
+	}
+	return roleNames[r]
+}
+
+// ParseRole parses "viewer", "operator" or "admin".
+func ParseRole(name string) (Role, error) {
+	i := slices.Index(roleNames, strings.ToLower(strings.TrimSpace(name)))
+	if i <= int(RoleNone) {
+		return RoleNone, fmt.Errorf("unknown role %q: use viewer, operator or admin", name)
+	}
+	return Role(i), nil
+}
+
+// Grant gives a role on the queues matching any of Queues, or on every
+// queue when Queues is empty.
+type Grant struct {
+	// Subject is "user:<method>:<name>", "group:<group>", "scope:<scope>"
+	// or "*" for every authenticated identity. A user rule names the method
+	// that vouches for the name, so a token called like an OIDC user is not
+	// that user: "user:basic:alice", "user:token:ci", or for OIDC the
+	// immutable sub claim or a verified email, never the display name.
+	// group: matches OIDC groups and scope: API token scopes only.
+	Subject string
+	Role    Role
+	// Queues are path.Match patterns such as "billing-*".
+	Queues []string
+}
+
+// Policy maps identities to roles per queue. A nil Policy means access
+// control is off and every identity is an admin everywhere.
+type Policy struct {
+	grants []Grant
+}
+
+// ParsePolicy reads grants of the form "<subject> <role> [<queue pattern> ...]",
+// e.g. "group:team-billing operator billing-*". entries and the lines of file
+// are combined; no grants at all gives a nil Policy.
+func ParsePolicy(entries []string, file string) (*Policy, error) {
+	lines, err := withFileLines(entries, file)
+	if err != nil {
+		return nil, fmt.Errorf("RBAC grants: %w", err)
+	}
+	var grants []Grant
+	for _, line := range lines {
+		grant, err := parseGrant(line)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+	if len(grants) == 0 {
+		return nil, nil
+	}
+	return &Policy{grants: grants}, nil
+}
+
+// Len returns the number of grants, for logging.
+func (p *Policy) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.grants)
+}
+
+func parseGrant(line string) (Grant, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return Grant{}, fmt.Errorf("RBAC grant %q: want <subject> <role> [<queue pattern> ...]", line)
+	}
+	subject := fields[0]
+	if kind, name, ok := strings.Cut(subject, ":"); subject != "*" && (!ok || name == "" || !slices.Contains([]string{"user", "group", "scope"}, kind)) {
+		return Grant{}, fmt.Errorf("RBAC grant %q: subject must be user:<method>:<name>, group:<group>, scope:<scope> or *", line)
+	} else if kind == "user" {
+		method, user, ok := strings.Cut(name, ":")
+		if !ok || user == "" || !slices.Contains([]string{MethodBasic, MethodToken, MethodOIDC}, method) {
+			return Grant{}, fmt.Errorf("RBAC grant %q: user subjects name their auth method, as in user:basic:<user>, user:token:<name> or user:oidc:<sub or email>", line)
+		}
+	}
+	role, err := ParseRole(fields[1])
+	if err != nil {
+		return Grant{}, fmt.Errorf("RBAC grant %q: %w", line, err)
+	}
+	queues := fields[2:]
+	for _, pattern := range queues {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Grant{}, fmt.Errorf("RBAC grant %q: bad queue pattern %q: %w", line, pattern, err)
+		}
+	}
+	return Grant{Subject: subject, Role: role, Queues: queues}, nil
+}
+
+// Role returns the highest role id holds on queue.
+func (p *Policy) Role(id *Identity, queue string) Role {
+	if p == nil {
+		return RoleAdmin
+	}
+	if id == nil {
+		return RoleNone
+	}
+	best := RoleNone
+	for _, grant := range p.grants {
+		if grant.Role > best && grant.matchesSubject(id) && grant.matchesQueue(queue) {
+			best = grant.Role
+		}
+	}
+	return best
+}
+
+func (g Grant) matchesSubject(id *Identity) bool {
+	if g.Subject == "*" {
+		return true
+	}
+	kind, name, _ := strings.Cut(g.Subject, ":")
+	switch kind {
+	case "user":
+		// Basic users and tokens are named by their own configuration, so
+		// Subject is the name. An OIDC username claim can be changed by the
+		// user at some providers.
+		method, user, _ := strings.Cut(name, ":")
+		if method != id.Method {
+			return false
+		}
+		return user == id.Subject || (id.Email != "" && strings.EqualFold(user, id.Email))
+	case "group":
+		return slices.Contains(id.Groups, name)
+	case "scope":
+		return slices.Contains(id.Scopes, name)
+	default:
+		return false
+	}
+}
+
+func (g Grant) matchesQueue(queue string) bool {
+	if len(g.Queues) == 0 {
+		return true
+	}
+	for _, pattern := range g.Queues {
+		if matched, _ := path.Match(pattern, queue); matched {
+			return true
+		}
+	}
+	return false
+}
+
+type policyContextKey struct{}
+
+// WithPolicy returns ctx carrying p for Allowed.
+func WithPolicy(ctx context.Context, p *Policy) context.Context {
+	return context.WithValue(ctx, policyContextKey{}, p)
+}
+
+// Allowed reports whether the request's identity holds at least role on
+// queue. Without a policy in ctx everything is allowed.
+func Allowed(ctx context.Context, queue string, role Role) bool {
+	p, _ := ctx.Value(policyContextKey{}).(*Policy)
+	return p.Role(FromContext(ctx), queue) >= role
+}
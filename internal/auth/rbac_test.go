@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParsePolicyRejectsBadGrants(t *testing.T) {
+	tests := []struct {
+		name  string
+		grant string
+		want  string
+	}{
+		{name: "missing role", grant: "user:basic:alice", want: "want <subject> <role>"},
+		{name: "unknown subject kind", grant: "team:billing viewer", want: "subject must be"},
+		{name: "empty subject name", grant: "group: viewer", want: "subject must be"},
+		{name: "user without method", grant: "user:alice viewer", want: "name their auth method"},
+		{name: "user with unknown method", grant: "user:ldap:alice viewer", want: "name their auth method"},
+		{name: "user without name", grant: "user:oidc: viewer", want: "name their auth method"},
+		{name: "unknown role", grant: "user:basic:alice owner", want: `unknown role "owner"`},
+		{name: "bad pattern", grant: "user:basic:alice viewer billing-[", want: "bad queue pattern"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParsePolicy([]string{tc.grant}, "")
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("ParsePolicy(%q) error = %v, want it to mention %q", tc.grant, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestParsePolicyWithoutGrantsIsNil(t *testing.T) {
+	policy, err := ParsePolicy(nil, "")
+	if err != nil || policy != nil {
+		t.Fatalf("ParsePolicy(nil) = %v, %v; want nil, nil", policy, err)
+	}
+	if got := policy.Role(nil, "orders"); got != RoleAdmin {
+		t.Fatalf("nil policy role = %s, want admin", got)
+	}
+}
+
+func TestPolicyRole(t *testing.T) {
+	policy, err := ParsePolicy([]string{
+		"* viewer",
+		"group:team-billing operator billing-* invoices",
+		"user:oidc:alice@example.com admin",
+		"user:oidc:u-3 admin",
+		"scope:ci operator deploys",
+		"group:ci admin",
+	}, "")
+	if err != nil {
+		t.Fatalf("ParsePolicy returned error: %v", err)
+	}
+
+	bob := &Identity{Subject: "u-2", Name: "bob@example.com", Method: MethodOIDC, Groups: []string{"team-billing"}}
+	alice := &Identity{Subject: "u-1", Name: "alice", Method: MethodOIDC, Email: "Alice@example.com"}
+	// Carol set her display name to Alice's address; without a verified
+	// email it is not hers.
+	carol := &Identity{Subject: "u-4", Name: "alice@example.com", Method: MethodOIDC}
+	dave := &Identity{Subject: "u-3", Name: "dave", Method: MethodOIDC}
+	ci := &Identity{Subject: "ci", Name: "ci", Method: MethodToken, Scopes: []string{"ci"}}
+	// A token and a basic-auth user named like Dave's OIDC sub and Alice's
+	// email are neither of them.
+	fakeDave := &Identity{Subject: "u-3", Name: "u-3", Method: MethodToken}
+	fakeAlice := &Identity{Subject: "alice@example.com", Name: "alice@example.com", Method: MethodBasic}
+	billingBot := &Identity{Subject: "bot", Name: "bot", Method: MethodToken, Scopes: []string{"team-billing"}}
+
+	tests := []struct {
+		name  string
+		id    *Identity
+		queue string
+		want  Role
+	}{
+		{name: "group pattern", id: bob, queue: "billing-emails", want: RoleOperator},
+		{name: "group exact", id: bob, queue: "invoices", want: RoleOperator},
+		{name: "group falls back to everyone", id: bob, queue: "orders", want: RoleViewer},
+		{name: "user by verified email", id: alice, queue: "orders", want: RoleAdmin},
+		{name: "user by display name", id: carol, queue: "orders", want: RoleViewer},
+		{name: "user by subject", id: dave, queue: "orders", want: RoleAdmin},
+		{name: "token named like an OIDC sub", id: fakeDave, queue: "orders", want: RoleViewer},
+		{name: "basic user named like an OIDC email", id: fakeAlice, queue: "orders", want: RoleViewer},
+		{name: "token scope", id: ci, queue: "deploys", want: RoleOperator},
+		{name: "token scope elsewhere", id: ci, queue: "billing-emails", want: RoleViewer},
+		// A token scoped "team-billing" is not in the team-billing group, and
+		// the "ci" group grant is not for the ci scope.
+		{name: "scope is not a group", id: billingBot, queue: "billing-emails", want: RoleViewer},
+		{name: "group is not a scope", id: ci, queue: "orders", want: RoleViewer},
+		{name: "no identity", id: nil, queue: "orders", want: RoleNone},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := policy.Role(tc.id, tc.queue); got != tc.want {
+				t.Fatalf("Role(%v, %q) = %s, want %s", tc.id, tc.queue, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPolicyMatchesUserBySubject(t *testing.T) {
+	policy, err := ParsePolicy([]string{"user:oidc:u-1 operator"}, "")
+	if err != nil {
+		t.Fatalf("ParsePolicy returned error: %v", err)
+	}
+	if got := policy.Role(&Identity{Subject: "u-1", Name: "alice", Method: MethodOIDC}, "orders"); got != RoleOperator {
+		t.Fatalf("Role = %s, want operator", got)
+	}
+	if got := policy.Role(&Identity{Subject: "u-2", Name: "bob", Method: MethodOIDC}, "orders"); got != RoleNone {
+		t.Fatalf("Role = %s, want none", got)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	if !Allowed(context.Background(), "orders", RoleAdmin) {
+		t.Fatal("Allowed without a policy = false, want true")
+	}
+
+	policy, err := ParsePolicy([]string{"user:basic:alice viewer"}, "")
+	if err != nil {
+		t.Fatalf("ParsePolicy returned error: %v", err)
+	}
+	ctx := WithIdentity(WithPolicy(context.Background(), policy), &Identity{Subject: "alice", Name: "alice", Method: MethodBasic})
+	if !Allowed(ctx, "orders", RoleViewer) {
+		t.Fatal("viewer not allowed to view")
+	}
+	if Allowed(ctx, "orders", RoleOperator) {
+		t.Fatal("viewer allowed to operate")
+	}
+}
+
+func TestNewRejectsPolicyWithoutAuthentication(t *testing.T) {
+	policy, err := ParsePolicy([]string{"* viewer"}, "")
+	if err != nil {
+		t.Fatalf("ParsePolicy returned error: %v", err)
+	}
+	if _, err := New(Options{Policy: policy}); err == nil {
+		t.Fatal("New with a policy and no methods returned nil error")
+	}
+}
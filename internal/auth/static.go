@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// basicAuthCacheTTL is how long a verified password is trusted before
+// bcrypt checks it again.
+const basicAuthCacheTTL = 5 * time.Minute
+
+// basicAuth checks HTTP basic credentials against bcrypt hashes, as written
+// by `htpasswd -nbB user password`.
+type basicAuth struct {
+	users map[string][]byte
+	// verified remembers, per configured user, the digest of the last
+	// password that passed, so the dashboard's polling does not pay for
+	// bcrypt on every request. Keyed by user it never outgrows users.
+	mu       sync.Mutex
+	verified map[string]verifiedPassword
+}
+
+type verifiedPassword struct {
+	digest  [sha256.Size]byte
+	expires time.Time
+}
+
+// dummyHash is compared against for unknown users so they take as long to
+// reject as known ones.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("bullderdash"), bcrypt.DefaultCost)
+	return hash
+})
+
+func newBasicAuth(entries []string, file string) (*basicAuth, error) {
+	lines, err := withFileLines(entries, file)
+	if err != nil {
+		return nil, fmt.Errorf("basic auth users: %w", err)
+	}
+	users := make(map[string][]byte, len(lines))
+	for _, line := range lines {
+		name, hash, ok := strings.Cut(line, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("basic auth user %q: want user:<bcrypt hash>", name)
+		}
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("basic auth user %q: password must be a bcrypt hash: %w", name, err)
+		}
+		users[name] = []byte(hash)
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("basic auth needs at least one user")
+	}
+	return &basicAuth{users: users, verified: make(map[string]verifiedPassword)}, nil
+}
+
+func (b *basicAuth) authenticate(r *http.Request) (*Identity, error) {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, nil
+	}
+
+	digest := sha256.Sum256([]byte(password))
+	if !b.recentlyVerified(user, digest) {
+		hash, known := b.users[user]
+		if !known {
+			hash = dummyHash()
+		}
+		if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || !known {
+			return nil, fmt.Errorf("user %q: %w", user, errInvalidCredentials)
+		}
+		b.mu.Lock()
+		b.verified[user] = verifiedPassword{digest: digest, expires: time.Now().Add(basicAuthCacheTTL)}
+		b.mu.Unlock()
+	}
+	return &Identity{Subject: user, Name: user, Method: MethodBasic}, nil
+}
+
+func (b *basicAuth) recentlyVerified(user string, digest [sha256.Size]byte) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cached, ok := b.verified[user]
+	if !ok || time.Now().After(cached.expires) {
+		return false
+	}
+	return subtle.ConstantTimeCompare(digest[:], cached.digest[:]) == 1
+}
+
+type apiToken struct {
+	name   string
+	digest [sha256.Size]byte
+	scopes []string
+}
+
+// tokenAuth checks "Authorization: Bearer <token>" against static tokens.
+type tokenAuth struct {
+	tokens []apiToken
+}
+
+func newTokenAuth(entries []string, file string) (*tokenAuth, error) {
+	lines, err := withFileLines(entries, file)
+	if err != nil {
+		return nil, fmt.Errorf("API tokens: %w", err)
+	}
+	seen := make(map[string]bool, len(lines))
+	tokens := make([]apiToken, 0, len(lines))
+	for _, line := range lines {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("API token %q: want name:token[:scope ...]", parts[0])
+		}
+		if seen[parts[0]] {
+			return nil, fmt.Errorf("API token %q is defined twice", parts[0])
+		}
+		seen[parts[0]] = true
+		token := apiToken{name: parts[0], digest: sha256.Sum256([]byte(parts[1]))}
+		if len(parts) == 3 {
+			token.scopes = strings.Fields(parts[2])
+		}
+		tokens = append(tokens, token)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("token auth needs at least one API token")
+	}
+	return &tokenAuth{tokens: tokens}, nil
+}
+
+func (t *tokenAuth) authenticate(r *http.Request) (*Identity, error) {
+	scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, nil
+	}
+
+	// Comparing digests keeps the comparison constant-time whatever the
+	// token lengths, and every token is checked.
+	digest := sha256.Sum256([]byte(strings.TrimSpace(value)))
+	var match *apiToken
+	for i := range t.tokens {
+		if subtle.ConstantTimeCompare(digest[:], t.tokens[i].digest[:]) == 1 {
+			match = &t.tokens[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("bearer token: %w", errInvalidCredentials)
+	}
+	return &Identity{Subject: match.name, Name: match.name, Method: MethodToken, Scopes: match.scopes}, nil
+}
+
+// withFileLines appends the non-blank, non-comment lines of file to entries.
+func withFileLines(entries []string, file string) ([]string, error) {
+	lines := append([]string(nil), entries...)
+	if file == "" {
+		return lines, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
@@ -1,23 +1,23 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
 )
 
+// DefaultEnvironment names the single environment used when
+// REDIS_ENVIRONMENTS is not set.
+const DefaultEnvironment = "default"
+
 // Config holds application configuration
 type Config struct {
-	RedisAddr                      string
-	RedisUsername                  string
-	RedisPassword                  string
-	RedisDB                        int
-	RedisSentinelMaster            string
-	RedisSentinelAddrs             []string
-	RedisSentinelUsername          string
-	RedisSentinelPassword          string
+	// Environments are the Redis targets shown by the dashboard; the first is
+	// the default one.
+	Environments                   []Environment
 	ServerPort                     string
-	QueuePrefix                    string
+	BasePath                       string
 	MetricsPollSeconds             int
 	DashboardRefreshTimeoutSeconds int
 	WorkloadMetricsEnabled         bool
@@ -26,22 +26,76 @@ type Config struct {
 	WorkloadMetricsBatchSize       int
 	WorkloadMetricsMaxJobNames     int
 	WorkloadMetricsStartID         string
+	EventsMaxStreams               int
 	LogLevel                       string
+	Auth                           Auth
+	Audit                          Audit
+}
+
+// Audit holds the audit log settings.
+type Audit struct {
+	// Sink is none, stdout, file or redis.
+	Sink     string
+	File     string
+	RedisKey string
+}
+
+// Auth holds the authentication settings. With no Methods the dashboard is
+// open, as before.
+type Auth struct {
+	Methods           []string
+	BasicUsers        []string
+	BasicUsersFile    string
+	APITokens         []string
+	APITokensFile     string
+	SessionSecret     string
+	SessionTTLSeconds int
+	// PublicHealth and PublicMetrics keep probes and Prometheus scrapes
+	// working without credentials.
+	PublicHealth      bool
+	PublicMetrics     bool
+	TrustedOrigins    []string
+	OIDCIssuerURL     string
+	OIDCClientID      string
+	OIDCClientSecret  string
+	OIDCRedirectURL   string
+	OIDCScopes        []string
+	OIDCUsernameClaim string
+	OIDCGroupsClaim   string
+	// RBACGrants are "<subject> <role> [<queue pattern> ...]" entries;
+	// RBACGrantsFile has one per line. With none, every user is an admin.
+	RBACGrants     []string
+	RBACGrantsFile string
+}
+
+// Environment holds the connection settings for one named Redis target.
+type Environment struct {
+	Name                       string
+	RedisURL                   string
+	RedisAddr                  string
+	RedisUsername              string
+	RedisPassword              string
+	RedisDB                    int
+	RedisSentinelMaster        string
+	RedisSentinelAddrs         []string
+	RedisSentinelUsername      string
+	RedisSentinelPassword      string
+	RedisClusterAddrs          []string
+	RedisTLS                   bool
+	RedisTLSCAFile             string
+	RedisTLSCertFile           string
+	RedisTLSKeyFile            string
+	RedisTLSServerName         string
+	RedisTLSInsecureSkipVerify bool
+	QueuePrefixes              []string
 }
 
 // Load reads configuration from environment variables with sensible defaults
 func Load() *Config {
 	return &Config{
-		RedisAddr:                      getEnv("REDIS_ADDR", "127.0.0.1:6379"),
-		RedisUsername:                  getEnv("REDIS_USERNAME", ""),
-		RedisPassword:                  getEnv("REDIS_PASSWORD", ""),
-		RedisDB:                        getEnvInt("REDIS_DB", 0),
-		RedisSentinelMaster:            getEnv("REDIS_SENTINEL_MASTER", ""),
-		RedisSentinelAddrs:             getEnvList("REDIS_SENTINEL_ADDRS"),
-		RedisSentinelUsername:          getEnv("REDIS_SENTINEL_USERNAME", ""),
-		RedisSentinelPassword:          getEnv("REDIS_SENTINEL_PASSWORD", ""),
+		Environments:                   loadEnvironments(),
 		ServerPort:                     getEnv("SERVER_PORT", "8080"),
-		QueuePrefix:                    getEnv("QUEUE_PREFIX", "bull"),
+		BasePath:                       normalizeBasePath(getEnv("BASE_PATH", "")),
 		MetricsPollSeconds:             getEnvInt("METRICS_POLL_SECONDS", 10),
 		DashboardRefreshTimeoutSeconds: getEnvInt("DASHBOARD_REFRESH_TIMEOUT_SECONDS", 30),
 		WorkloadMetricsEnabled:         getEnvBool("WORKLOAD_METRICS_ENABLED", false),
@@ -50,10 +104,135 @@ func Load() *Config {
 		WorkloadMetricsBatchSize:       getEnvInt("WORKLOAD_METRICS_BATCH_SIZE", 100),
 		WorkloadMetricsMaxJobNames:     getEnvInt("WORKLOAD_METRICS_MAX_JOB_NAMES_PER_QUEUE", 100),
 		WorkloadMetricsStartID:         getEnv("WORKLOAD_METRICS_START_ID", "$"),
+		EventsMaxStreams:               getEnvInt("EVENTS_MAX_STREAMS", 20),
 		LogLevel:                       getEnv("LOG_LEVEL", "info"),
+		Auth:                           loadAuth(),
+		Audit:                          loadAudit(),
+	}
+}
+
+func loadAuth() Auth {
+	return Auth{
+		Methods:           getEnvList("AUTH_MODE"),
+		BasicUsers:        getEnvList("AUTH_BASIC_USERS"),
+		BasicUsersFile:    getEnv("AUTH_BASIC_USERS_FILE", ""),
+		APITokens:         getEnvList("AUTH_API_TOKENS"),
+		APITokensFile:     getEnv("AUTH_API_TOKENS_FILE", ""),
+		SessionSecret:     getEnv("AUTH_SESSION_SECRET", ""),
+		SessionTTLSeconds: getEnvInt("AUTH_SESSION_TTL_SECONDS", 8*60*60),
+		PublicHealth:      getEnvBool("AUTH_PUBLIC_HEALTH", true),
+		PublicMetrics:     getEnvBool("AUTH_PUBLIC_METRICS", true),
+		TrustedOrigins:    getEnvList("AUTH_TRUSTED_ORIGINS"),
+		OIDCIssuerURL:     getEnv("OIDC_ISSUER_URL", ""),
+		OIDCClientID:      getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:  getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:   getEnv("OIDC_REDIRECT_URL", ""),
+		OIDCScopes:        getEnvList("OIDC_SCOPES"),
+		OIDCUsernameClaim: getEnv("OIDC_USERNAME_CLAIM", "email"),
+		OIDCGroupsClaim:   getEnv("OIDC_GROUPS_CLAIM", "groups"),
+		RBACGrants:        getEnvList("RBAC_GRANTS"),
+		RBACGrantsFile:    getEnv("RBAC_GRANTS_FILE", ""),
+	}
+}
+
+func loadAudit() Audit {
+	return Audit{
+		Sink:     strings.ToLower(getEnv("AUDIT_SINK", "stdout")),
+		File:     getEnv("AUDIT_FILE", ""),
+		RedisKey: getEnv("AUDIT_REDIS_KEY", "bullderdash:audit"),
 	}
 }
 
+// Validate reports configuration that Load cannot fix up on its own.
+func (c *Config) Validate() error {
+	for _, env := range c.Environments {
+		if !isValidEnvironmentName(env.Name) {
+			return fmt.Errorf("invalid environment name %q: use letters, digits, '-' and '_'", env.Name)
+		}
+	}
+	if strings.ContainsAny(c.BasePath, "?#% ") || strings.Contains(c.BasePath, "//") {
+		return fmt.Errorf("invalid BASE_PATH %q: use a plain path such as /tools/bullmq", c.BasePath)
+	}
+	if c.EventsMaxStreams < len(c.Environments) {
+		return fmt.Errorf("invalid EVENTS_MAX_STREAMS %d: the streams are split between %d environments, so allow at least one each", c.EventsMaxStreams, len(c.Environments))
+	}
+	switch c.Audit.Sink {
+	case "none", "stdout", "redis":
+	case "file":
+		if c.Audit.File == "" {
+			return fmt.Errorf("AUDIT_SINK=file needs AUDIT_FILE")
+		}
+	default:
+		return fmt.Errorf("invalid AUDIT_SINK %q: use none, stdout, file or redis", c.Audit.Sink)
+	}
+	return nil
+}
+
+// loadEnvironments reads REDIS_ENVIRONMENTS (e.g. "staging,prod"). Each
+// environment's settings come from variables prefixed with its upper-cased
+// name, such as PROD_REDIS_URL, falling back to the unprefixed variable.
+func loadEnvironments() []Environment {
+	names := uniqueStrings(getEnvList("REDIS_ENVIRONMENTS"))
+	if len(names) == 0 {
+		return []Environment{loadEnvironment(DefaultEnvironment, "")}
+	}
+
+	environments := make([]Environment, 0, len(names))
+	for _, name := range names {
+		environments = append(environments, loadEnvironment(name, environmentVarPrefix(name)))
+	}
+	return environments
+}
+
+func loadEnvironment(name, prefix string) Environment {
+	key := func(key string) string {
+		if prefix != "" && os.Getenv(prefix+key) != "" {
+			return prefix + key
+		}
+		return key
+	}
+
+	return Environment{
+		Name:                       name,
+		RedisURL:                   getEnv(key("REDIS_URL"), ""),
+		RedisAddr:                  getEnv(key("REDIS_ADDR"), "127.0.0.1:6379"),
+		RedisUsername:              getEnv(key("REDIS_USERNAME"), ""),
+		RedisPassword:              getEnv(key("REDIS_PASSWORD"), ""),
+		RedisDB:                    getEnvInt(key("REDIS_DB"), 0),
+		RedisSentinelMaster:        getEnv(key("REDIS_SENTINEL_MASTER"), ""),
+		RedisSentinelAddrs:         getEnvList(key("REDIS_SENTINEL_ADDRS")),
+		RedisSentinelUsername:      getEnv(key("REDIS_SENTINEL_USERNAME"), ""),
+		RedisSentinelPassword:      getEnv(key("REDIS_SENTINEL_PASSWORD"), ""),
+		RedisClusterAddrs:          getEnvList(key("REDIS_CLUSTER_ADDRS")),
+		RedisTLS:                   getEnvBool(key("REDIS_TLS"), false),
+		RedisTLSCAFile:             getEnv(key("REDIS_TLS_CA_FILE"), ""),
+		RedisTLSCertFile:           getEnv(key("REDIS_TLS_CERT_FILE"), ""),
+		RedisTLSKeyFile:            getEnv(key("REDIS_TLS_KEY_FILE"), ""),
+		RedisTLSServerName:         getEnv(key("REDIS_TLS_SERVER_NAME"), ""),
+		RedisTLSInsecureSkipVerify: getEnvBool(key("REDIS_TLS_INSECURE_SKIP_VERIFY"), false),
+		QueuePrefixes:              getEnvPrefixes(key("QUEUE_PREFIX"), "bull"),
+	}
+}
+
+// environmentVarPrefix turns "eu-west" into "EU_WEST_".
+func environmentVarPrefix(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+}
+
+func isValidEnvironmentName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -94,3 +273,36 @@ func getEnvList(key string) []string {
 	}
 	return result
 }
+
+// normalizeBasePath turns "tools/bullmq/" into "/tools/bullmq", and "/" into
+// "".
+func normalizeBasePath(path string) string {
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	if path == "" {
+		return ""
+	}
+	return "/" + path
+}
+
+// getEnvPrefixes reads a comma-separated list of queue prefixes, dropping
+// duplicates. The first prefix is the default one.
+func getEnvPrefixes(key, fallback string) []string {
+	prefixes := uniqueStrings(getEnvList(key))
+	if len(prefixes) == 0 {
+		return []string{fallback}
+	}
+	return prefixes
+}
+
+func uniqueStrings(values []string) []string {
+	unique := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		unique = append(unique, value)
+	}
+	return unique
+}
@@ -0,0 +1,230 @@
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/metrics"
+)
+
+var (
+	ErrJobNotFound    = errors.New("job not found")
+	ErrJobLocked      = errors.New("job is locked by a worker")
+	ErrJobActive      = errors.New("job is active")
+	ErrJobHasChildren = errors.New("job has pending children")
+	ErrJobWrongState  = errors.New("unexpected job state")
+)
+
+// RetryJob moves a failed job back to wait (or paused/prioritized), resets its
+// attempts and emits a "waiting" event, like BullMQ's Job.retry().
+func (e *Explorer) RetryJob(ctx context.Context, queuePrefix, queueName, jobID string) error {
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "retry_job").Observe(time.Since(start).Seconds())
+	}()
+
+	prefix := queueKey(queuePrefix, queueName)
+	keys := []string{
+		prefix + ":" + jobID,
+		prefix + ":failed",
+		prefix + ":wait",
+		prefix + ":paused",
+		prefix + ":meta",
+		prefix + ":active",
+		prefix + ":prioritized",
+		prefix + ":pc",
+		prefix + ":marker",
+		prefix + ":events",
+	}
+
+	code, err := retryJobScript.Run(ctx, e.client, keys, jobID).Int64()
+	if err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "retry_job").Inc()
+		return err
+	}
+	return scriptResultError(code, jobID, "failed")
+}
+
+// RemoveJob deletes a job and its companion keys (logs, lock, dependencies,
+// processed) and drops its ID from every state list, like BullMQ's
+// Job.remove(). Active or locked jobs are refused, as are parents that still
+// have pending children.
+func (e *Explorer) RemoveJob(ctx context.Context, queuePrefix, queueName, jobID string) error {
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "remove_job").Observe(time.Since(start).Seconds())
+	}()
+
+	prefix := queueKey(queuePrefix, queueName)
+	keys := []string{
+		prefix + ":" + jobID,
+		prefix + ":wait",
+		prefix + ":active",
+		prefix + ":paused",
+		prefix + ":delayed",
+		prefix + ":prioritized",
+		prefix + ":waiting-children",
+		prefix + ":completed",
+		prefix + ":failed",
+		prefix + ":stalled",
+		prefix + ":meta",
+		prefix + ":events",
+	}
+	for _, suffix := range jobKeySuffixes {
+		keys = append(keys, prefix+":"+jobID+":"+suffix)
+	}
+
+	code, err := removeJobScript.Run(ctx, e.client, keys, jobID).Int64()
+	if err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "remove_job").Inc()
+		return err
+	}
+	return removeJobResultError(code, jobID)
+}
+
+// removeJobResultError maps removeJobScript's codes, where -3 means active and
+// -4 means the job still has pending children.
+func removeJobResultError(code int64, jobID string) error {
+	switch code {
+	case -3:
+		return fmt.Errorf("%w: %s", ErrJobActive, jobID)
+	case -4:
+		return fmt.Errorf("%w: %s (remove or finish them first)", ErrJobHasChildren, jobID)
+	default:
+		return scriptResultError(code, jobID, "removable")
+	}
+}
+
+// PromoteJob moves a delayed job to wait (or paused/prioritized) right away,
+// like BullMQ's Job.promote().
+func (e *Explorer) PromoteJob(ctx context.Context, queuePrefix, queueName, jobID string) error {
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "promote_job").Observe(time.Since(start).Seconds())
+	}()
+
+	prefix := queueKey(queuePrefix, queueName)
+	keys := []string{
+		prefix + ":" + jobID,
+		prefix + ":delayed",
+		prefix + ":wait",
+		prefix + ":paused",
+		prefix + ":meta",
+		prefix + ":active",
+		prefix + ":prioritized",
+		prefix + ":pc",
+		prefix + ":marker",
+		prefix + ":events",
+	}
+
+	code, err := promoteJobScript.Run(ctx, e.client, keys, jobID).Int64()
+	if err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "promote_job").Inc()
+		return err
+	}
+	return scriptResultError(code, jobID, "delayed")
+}
+
+// PromoteDelayedJobs promotes every delayed job in a queue, in batches so a
+// single script call never blocks Redis for long. Returns the number promoted.
+func (e *Explorer) PromoteDelayedJobs(ctx context.Context, queuePrefix, queueName string) (int64, error) {
+	const batchSize = 1000
+
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "promote_delayed_jobs").Observe(time.Since(start).Seconds())
+	}()
+
+	prefix := queueKey(queuePrefix, queueName)
+	keys := []string{
+		prefix + ":delayed",
+		prefix + ":wait",
+		prefix + ":paused",
+		prefix + ":meta",
+		prefix + ":active",
+		prefix + ":prioritized",
+		prefix + ":pc",
+		prefix + ":marker",
+		prefix + ":events",
+	}
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		result, err := promoteDelayedScript.Run(ctx, e.client, keys, prefix+":", batchSize).Int64Slice()
+		if err != nil {
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "promote_delayed_jobs").Inc()
+			return total, err
+		}
+		if len(result) != 2 {
+			return total, fmt.Errorf("unexpected promote result: %v", result)
+		}
+		total += result[0]
+		if result[1] < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// PauseQueue pauses a queue the way BullMQ's Queue.pause() does.
+func (e *Explorer) PauseQueue(ctx context.Context, queuePrefix, queueName string) error {
+	return e.setQueuePaused(ctx, queuePrefix, queueName, true)
+}
+
+// ResumeQueue resumes a paused queue the way BullMQ's Queue.resume() does.
+func (e *Explorer) ResumeQueue(ctx context.Context, queuePrefix, queueName string) error {
+	return e.setQueuePaused(ctx, queuePrefix, queueName, false)
+}
+
+func (e *Explorer) setQueuePaused(ctx context.Context, queuePrefix, queueName string, paused bool) error {
+	operation, event := "resume_queue", "resumed"
+	if paused {
+		operation, event = "pause_queue", "paused"
+	}
+
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, operation).Observe(time.Since(start).Seconds())
+	}()
+
+	prefix := queueKey(queuePrefix, queueName)
+	src, dst := prefix+":paused", prefix+":wait"
+	if paused {
+		src, dst = dst, src
+	}
+	keys := []string{
+		src,
+		dst,
+		prefix + ":meta",
+		prefix + ":prioritized",
+		prefix + ":delayed",
+		prefix + ":events",
+		prefix + ":marker",
+	}
+
+	if err := pauseScript.Run(ctx, e.client, keys, event).Err(); err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, operation).Inc()
+		return err
+	}
+	return nil
+}
+
+// scriptResultError maps BullMQ-style script return codes to errors.
+func scriptResultError(code int64, jobID, expectedState string) error {
+	switch {
+	case code >= 0:
+		return nil
+	case code == -1:
+		return fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+	case code == -2:
+		return fmt.Errorf("%w: %s", ErrJobLocked, jobID)
+	case code == -3:
+		return fmt.Errorf("%w: job %s is not %s", ErrJobWrongState, jobID, expectedState)
+	default:
+		return fmt.Errorf("unexpected script result %d for job %s", code, jobID)
+	}
+}
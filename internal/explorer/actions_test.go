@@ -0,0 +1,74 @@
+package explorer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScriptResultError(t *testing.T) {
+	tests := []struct {
+		name string
+		code int64
+		want error
+	}{
+		{name: "success", code: 0, want: nil},
+		{name: "count", code: 12, want: nil},
+		{name: "missing job", code: -1, want: ErrJobNotFound},
+		{name: "locked", code: -2, want: ErrJobLocked},
+		{name: "wrong state", code: -3, want: ErrJobWrongState},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := scriptResultError(tc.code, "42", "failed")
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("expected nil error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("scriptResultError(%d) = %v, want %v", tc.code, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestScriptResultErrorUnknownCode(t *testing.T) {
+	err := scriptResultError(-9, "42", "failed")
+	if err == nil {
+		t.Fatal("expected error for unknown script code")
+	}
+	if errors.Is(err, ErrJobNotFound) || errors.Is(err, ErrJobLocked) || errors.Is(err, ErrJobWrongState) {
+		t.Fatalf("unexpected sentinel for unknown code: %v", err)
+	}
+}
+
+func TestRemoveJobResultError(t *testing.T) {
+	tests := []struct {
+		name string
+		code int64
+		want error
+	}{
+		{name: "removed", code: 0, want: nil},
+		{name: "missing job", code: -1, want: ErrJobNotFound},
+		{name: "locked", code: -2, want: ErrJobLocked},
+		{name: "active", code: -3, want: ErrJobActive},
+		{name: "parent with pending children", code: -4, want: ErrJobHasChildren},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := removeJobResultError(tc.code, "parent-1")
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("expected nil error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("removeJobResultError(%d) = %v, want %v", tc.code, err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package explorer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/metrics"
+)
+
+const defaultCleanBatchSize = 1000
+
+// CleanOptions controls a Queue.clean-style bulk removal.
+type CleanOptions struct {
+	// State is the finished state to clean: "completed" or "failed".
+	State string
+	// Grace keeps jobs that finished more recently than this.
+	Grace time.Duration
+	// Limit caps how many jobs are removed; 0 means no cap.
+	Limit int64
+	// BatchSize is the number of jobs removed per script call.
+	BatchSize int64
+	// Pause is slept between batches to throttle Redis load.
+	Pause time.Duration
+	// DryRun only counts matching jobs.
+	DryRun bool
+}
+
+// CleanResult reports what a clean run matched or removed.
+type CleanResult struct {
+	Matched int64
+	Removed int64
+	// JobIDs are the removed jobs, for the audit log.
+	JobIDs []string
+	DryRun bool
+}
+
+// CleanJobs removes completed or failed jobs that finished before the grace
+// period, in bounded batches, like BullMQ's Queue.clean(grace, limit, type).
+func (e *Explorer) CleanJobs(ctx context.Context, queuePrefix, queueName string, opts CleanOptions) (CleanResult, error) {
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "clean_jobs").Observe(time.Since(start).Seconds())
+	}()
+
+	if opts.State != "completed" && opts.State != "failed" {
+		return CleanResult{}, fmt.Errorf("cannot clean state %q: only completed and failed are supported", opts.State)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultCleanBatchSize
+	}
+
+	prefix := queueKey(queuePrefix, queueName)
+	setKey := prefix + ":" + opts.State
+	maxScore := fmt.Sprintf("%d", start.Add(-opts.Grace).UnixMilli())
+
+	if opts.DryRun {
+		matched, err := e.client.ZCount(ctx, setKey, "-inf", maxScore).Result()
+		if err != nil {
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "clean_jobs").Inc()
+			return CleanResult{}, err
+		}
+		if opts.Limit > 0 && matched > opts.Limit {
+			matched = opts.Limit
+		}
+		return CleanResult{Matched: matched, DryRun: true}, nil
+	}
+
+	keys := []string{setKey, prefix + ":meta", prefix + ":events"}
+	var result CleanResult
+	for {
+		batch := opts.BatchSize
+		if opts.Limit > 0 {
+			batch = min(batch, opts.Limit-result.Matched)
+		}
+
+		reply, err := cleanJobsScript.Run(ctx, e.client, keys, prefix+":", maxScore, batch).Slice()
+		if err != nil {
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "clean_jobs").Inc()
+			return result, err
+		}
+		scanned, removed, err := parseRemovedBatch(reply)
+		if err != nil {
+			return result, fmt.Errorf("unexpected clean result: %w", err)
+		}
+		result.Removed += int64(len(removed))
+		result.Matched += scanned
+		result.JobIDs = append(result.JobIDs, removed...)
+
+		if scanned < batch || (opts.Limit > 0 && result.Matched >= opts.Limit) {
+			return result, nil
+		}
+		if !sleepContext(ctx, opts.Pause) {
+			return result, ctx.Err()
+		}
+	}
+}
+
+// parseRemovedBatch reads a {scanned, {removed job IDs}} script reply.
+func parseRemovedBatch(reply []any) (int64, []string, error) {
+	if len(reply) != 2 {
+		return 0, nil, fmt.Errorf("%v", reply)
+	}
+	scanned, ok := reply[0].(int64)
+	if !ok {
+		return 0, nil, fmt.Errorf("scanned count %v", reply[0])
+	}
+	items, ok := reply[1].([]any)
+	if !ok {
+		return 0, nil, fmt.Errorf("removed IDs %v", reply[1])
+	}
+	removed := make([]string, 0, len(items))
+	for _, item := range items {
+		id, ok := item.(string)
+		if !ok {
+			return 0, nil, fmt.Errorf("job ID %v", item)
+		}
+		removed = append(removed, id)
+	}
+	return scanned, removed, nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
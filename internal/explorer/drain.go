@@ -0,0 +1,167 @@
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/metrics"
+)
+
+const (
+	drainBatchSize      = 1000
+	obliterateBatchSize = 1000
+	obliterateScanCount = 500
+)
+
+var (
+	ErrQueueHasActiveJobs = errors.New("queue has active jobs")
+	ErrQueueNotPaused     = errors.New("queue is not paused")
+)
+
+// DrainQueue removes every waiting, paused, delayed and prioritized job, like
+// BullMQ's Queue.drain(true). Active, completed and failed jobs are kept.
+// Returns the IDs of the jobs removed, including on error.
+func (e *Explorer) DrainQueue(ctx context.Context, queuePrefix, queueName string) ([]string, error) {
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "drain_queue").Observe(time.Since(start).Seconds())
+	}()
+
+	prefix := queueKey(queuePrefix, queueName)
+	keys := []string{
+		prefix + ":wait",
+		prefix + ":paused",
+		prefix + ":delayed",
+		prefix + ":prioritized",
+	}
+
+	var drained []string
+	for {
+		if err := ctx.Err(); err != nil {
+			return drained, err
+		}
+		reply, err := drainScript.Run(ctx, e.client, keys, prefix+":", drainBatchSize).Slice()
+		if err != nil {
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "drain_queue").Inc()
+			return drained, err
+		}
+		scanned, removed, err := parseRemovedBatch(reply)
+		if err != nil {
+			return drained, fmt.Errorf("unexpected drain result: %w", err)
+		}
+		drained = append(drained, removed...)
+		if scanned < drainBatchSize {
+			return drained, nil
+		}
+	}
+}
+
+// ObliterateQueue deletes a queue and every key under "<prefix>:<queue>:*", like
+// BullMQ's Queue.obliterate(). The queue is paused first so workers stop
+// picking up jobs. Queues with active jobs are refused unless force is set,
+// and a refused obliterate leaves the queue running as it found it. A
+// cancelled ctx stops between batches with the queue paused and partly
+// deleted; calling it again finishes the job.
+func (e *Explorer) ObliterateQueue(ctx context.Context, queuePrefix, queueName string, force bool) error {
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "obliterate_queue").Observe(time.Since(start).Seconds())
+	}()
+
+	prefix := queueKey(queuePrefix, queueName)
+
+	// Check before pausing so a refused obliterate leaves the queue running.
+	if !force {
+		active, err := e.client.LLen(ctx, prefix+":active").Result()
+		if err != nil {
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "obliterate_queue").Inc()
+			return err
+		}
+		if active > 0 {
+			return fmt.Errorf("%w: %d active in %s", ErrQueueHasActiveJobs, active, queueName)
+		}
+	}
+
+	wasPaused, err := e.client.HExists(ctx, prefix+":meta", "paused").Result()
+	if err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "obliterate_queue").Inc()
+		return err
+	}
+	if err := e.PauseQueue(ctx, queuePrefix, queueName); err != nil {
+		return fmt.Errorf("pause before obliterate: %w", err)
+	}
+	// refused resumes the queue again when the script turns the obliterate
+	// down, unless it was paused before this call.
+	refused := func(err error) error {
+		if wasPaused {
+			return err
+		}
+		if resumeErr := e.ResumeQueue(context.WithoutCancel(ctx), queuePrefix, queueName); resumeErr != nil {
+			return fmt.Errorf("%w (resume after refusal: %v)", err, resumeErr)
+		}
+		return err
+	}
+
+	forceArg := ""
+	if force {
+		forceArg = "1"
+	}
+	keys := []string{prefix + ":meta"}
+	for _, state := range []string{"active", "delayed", "completed", "waiting-children", "failed", "prioritized", "paused", "wait"} {
+		keys = append(keys, prefix+":"+state)
+	}
+	for _, name := range obliterateQueueKeys {
+		keys = append(keys, prefix+":"+name)
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		code, err := obliterateScript.Run(ctx, e.client, keys, prefix+":", obliterateBatchSize, forceArg).Int64()
+		if err != nil {
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "obliterate_queue").Inc()
+			return err
+		}
+		switch code {
+		case 0:
+			return e.deleteKeysMatching(ctx, escapeScanPattern(prefix+":")+"*")
+		case 1:
+			continue
+		case -1:
+			return refused(fmt.Errorf("%w: %s", ErrQueueNotPaused, queueName))
+		case -2:
+			return refused(fmt.Errorf("%w: %s", ErrQueueHasActiveJobs, queueName))
+		default:
+			return fmt.Errorf("unexpected obliterate result %d for queue %s", code, queueName)
+		}
+	}
+}
+
+// deleteKeysMatching removes keys the obliterate script does not know about
+// (repeat and scheduler keys, dangling logs, deduplication keys, ...).
+func (e *Explorer) deleteKeysMatching(ctx context.Context, pattern string) error {
+	err := e.scanKeys(ctx, pattern, obliterateScanCount, func(keys []string) error {
+		return e.client.Unlink(ctx, keys...).Err()
+	})
+	if err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "obliterate_queue").Inc()
+	}
+	return err
+}
+
+// escapeScanPattern escapes glob metacharacters so a queue name is matched
+// literally by SCAN MATCH.
+func escapeScanPattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
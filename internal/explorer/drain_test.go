@@ -0,0 +1,35 @@
+package explorer
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEscapeScanPattern(t *testing.T) {
+	tests := map[string]string{
+		"bull:orders:":     "bull:orders:",
+		"bull:img*resize:": `bull:img\*resize:`,
+		"bull:a?[b]\\c:":   `bull:a\?\[b\]\\c:`,
+	}
+
+	for input, want := range tests {
+		if got := escapeScanPattern(input); got != want {
+			t.Fatalf("escapeScanPattern(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestParseRemovedBatch(t *testing.T) {
+	scanned, removed, err := parseRemovedBatch([]any{int64(3), []any{"1", "3"}})
+	if err != nil || scanned != 3 || fmt.Sprint(removed) != "[1 3]" {
+		t.Fatalf("parseRemovedBatch = %d, %v, %v", scanned, removed, err)
+	}
+	if _, removed, err := parseRemovedBatch([]any{int64(0), []any{}}); err != nil || len(removed) != 0 {
+		t.Fatalf("empty batch = %v, %v", removed, err)
+	}
+	for _, reply := range [][]any{{int64(1)}, {"1", []any{}}, {int64(1), "1"}, {int64(1), []any{int64(1)}}} {
+		if _, _, err := parseRemovedBatch(reply); err == nil {
+			t.Fatalf("parseRemovedBatch(%v) accepted a malformed reply", reply)
+		}
+	}
+}
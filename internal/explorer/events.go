@@ -0,0 +1,120 @@
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// QueueEvent is one entry of a queue's "<prefix>:<queue>:events" stream, as
+// written by BullMQ for QueueEvents listeners.
+type QueueEvent struct {
+	// ID is the stream entry ID, "<millis>-<seq>".
+	ID    string `json:"id"`
+	Event string `json:"event"`
+	JobID string `json:"jobId,omitempty"`
+	// Fields holds the rest of the entry, e.g. returnvalue, failedReason,
+	// prev or data for progress.
+	Fields map[string]string `json:"fields,omitempty"`
+	Time   time.Time         `json:"time"`
+}
+
+// LastQueueEventID returns the ID of the newest entry in a queue's event
+// stream, or "0" when it is empty. Reading after it is what XREAD "$" does,
+// but with an ID that can be carried from one read to the next.
+func (e *Explorer) LastQueueEventID(ctx context.Context, queuePrefix, queueName string) (string, error) {
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "last_queue_event_id").Observe(time.Since(start).Seconds())
+	}()
+
+	entries, err := e.client.XRevRangeN(ctx, queueKey(queuePrefix, queueName)+":events", "+", "-", 1).Result()
+	if err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "last_queue_event_id").Inc()
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "0", nil
+	}
+	return entries[0].ID, nil
+}
+
+// ReadQueueEvents returns up to count events after afterID, waiting up to
+// block for the first one. It returns no events and no error when the wait
+// times out.
+func (e *Explorer) ReadQueueEvents(ctx context.Context, queuePrefix, queueName, afterID string, count int64, block time.Duration) ([]QueueEvent, error) {
+	client := e.client
+	if e.events != nil {
+		client = e.events
+	}
+	results, err := client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{queueKey(queuePrefix, queueName) + ":events", afterID},
+		Count:   count,
+		Block:   block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "read_queue_events").Inc()
+		}
+		return nil, err
+	}
+
+	var events []QueueEvent
+	for _, stream := range results {
+		for _, msg := range stream.Messages {
+			events = append(events, newQueueEvent(msg))
+		}
+	}
+	return events, nil
+}
+
+// ValidStreamID reports whether id can start an XREAD: "$", "0" or a
+// "<millis>[-<seq>]" entry ID.
+func ValidStreamID(id string) bool {
+	if id == "$" {
+		return true
+	}
+	millis, seq, hasSeq := strings.Cut(id, "-")
+	if _, err := strconv.ParseUint(millis, 10, 64); err != nil {
+		return false
+	}
+	if hasSeq {
+		if _, err := strconv.ParseUint(seq, 10, 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func newQueueEvent(msg redis.XMessage) QueueEvent {
+	event := QueueEvent{ID: msg.ID}
+	for field, value := range msg.Values {
+		text := fmt.Sprint(value)
+		switch field {
+		case "event":
+			event.Event = text
+		case "jobId":
+			event.JobID = text
+		default:
+			if event.Fields == nil {
+				event.Fields = make(map[string]string)
+			}
+			event.Fields[field] = text
+		}
+	}
+	if millis, _, ok := strings.Cut(msg.ID, "-"); ok {
+		if ms, err := strconv.ParseInt(millis, 10, 64); err == nil {
+			event.Time = time.UnixMilli(ms)
+		}
+	}
+	return event
+}
@@ -0,0 +1,51 @@
+package explorer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestValidStreamID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: "$", want: true},
+		{id: "0", want: true},
+		{id: "1700000000000-3", want: true},
+		{id: "1700000000000", want: true},
+		{id: "", want: false},
+		{id: "-", want: false},
+		{id: "1700000000000-x", want: false},
+		{id: "now", want: false},
+	}
+	for _, tc := range tests {
+		if got := ValidStreamID(tc.id); got != tc.want {
+			t.Fatalf("ValidStreamID(%q) = %t, want %t", tc.id, got, tc.want)
+		}
+	}
+}
+
+func TestNewQueueEvent(t *testing.T) {
+	event := newQueueEvent(redis.XMessage{
+		ID: "1700000000123-0",
+		Values: map[string]interface{}{
+			"event":       "completed",
+			"jobId":       "42",
+			"returnvalue": `{"ok":true}`,
+			"prev":        "active",
+		},
+	})
+
+	if event.Event != "completed" || event.JobID != "42" {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	if event.Fields["returnvalue"] != `{"ok":true}` || event.Fields["prev"] != "active" || len(event.Fields) != 2 {
+		t.Fatalf("unexpected fields: %v", event.Fields)
+	}
+	if !event.Time.Equal(time.UnixMilli(1700000000123)) {
+		t.Fatalf("unexpected time %v", event.Time)
+	}
+}
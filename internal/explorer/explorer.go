@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,29 +13,54 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// DefaultEnvironment labels the metrics of an Explorer made with New.
+const DefaultEnvironment = "default"
+
 type Explorer struct {
-	client *redis.Client
+	client redis.UniversalClient
+	// events serves ReadQueueEvents' blocking XREADs; see UseEventsClient.
+	events   redis.UniversalClient
+	env      string
+	prefixes []string
+}
+
+// New returns an Explorer for the queues under the given key prefixes. The
+// first prefix is the default; with none, BullMQ's "bull" is used. client may
+// be a single node, a Sentinel failover client or a cluster.
+func New(client redis.UniversalClient, prefixes ...string) *Explorer {
+	return NewEnvironment(DefaultEnvironment, client, prefixes...)
+}
+
+// NewEnvironment is New for one of several Redis targets; env labels the
+// Explorer's metrics.
+func NewEnvironment(env string, client redis.UniversalClient, prefixes ...string) *Explorer {
+	if len(prefixes) == 0 {
+		prefixes = []string{DefaultPrefix}
+	}
+	return &Explorer{client: client, env: env, prefixes: append([]string(nil), prefixes...)}
+}
+
+// UseEventsClient moves ReadQueueEvents onto its own client. Each live
+// events stream holds a connection for up to its block time, which would
+// otherwise come out of the pool every page and action shares.
+func (e *Explorer) UseEventsClient(client redis.UniversalClient) {
+	e.events = client
 }
 
-func New(client *redis.Client) *Explorer {
-	return &Explorer{client: client}
+// Environment returns the name of the Redis target the Explorer reads.
+func (e *Explorer) Environment() string {
+	return e.env
 }
 
 // DiscoverQueues finds all BullMQ queues by looking for the ":id" suffix
 func (e *Explorer) DiscoverQueues(ctx context.Context, prefix string) ([]string, error) {
-	var cursor uint64
 	seen := make(map[string]struct{})
 	const scanCount = 100
 
 	// Default prefix is usually "bull"
 	pattern := prefix + ":*:id"
 
-	for {
-		keys, nextCursor, err := e.client.Scan(ctx, cursor, pattern, scanCount).Result()
-		if err != nil {
-			return nil, err
-		}
-
+	err := e.scanKeys(ctx, pattern, scanCount, func(keys []string) error {
 		for _, key := range keys {
 			queueName, ok := strings.CutPrefix(key, prefix+":")
 			if !ok {
@@ -46,11 +72,10 @@ func (e *Explorer) DiscoverQueues(ctx context.Context, prefix string) ([]string,
 			}
 			seen[queueName] = struct{}{}
 		}
-
-		cursor = nextCursor
-		if cursor == 0 {
-			break
-		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Ensure we never return nil slice, return empty slice instead
@@ -63,7 +88,9 @@ func (e *Explorer) DiscoverQueues(ctx context.Context, prefix string) ([]string,
 }
 
 type QueueStats struct {
+	Prefix          string
 	Name            string
+	IsPaused        bool
 	Wait            int64
 	Active          int64
 	Paused          int64
@@ -73,9 +100,21 @@ type QueueStats struct {
 	Completed       int64
 	Delayed         int64
 	Stalled         int64
-	Orphaned        int64
-	OrphanedKnown   bool
-	Total           int64
+	// ActiveUnlocked counts active jobs whose lock has expired or is missing.
+	ActiveUnlocked      int64
+	ActiveUnlockedKnown bool
+	Orphaned            int64
+	OrphanedKnown       bool
+	Total               int64
+	Limits              QueueLimits
+	Workers             int64
+	WorkersKnown        bool
+}
+
+// NeedsWorkers reports whether jobs are waiting on a running queue that has no
+// connected workers to pick them up.
+func (s QueueStats) NeedsWorkers() bool {
+	return s.WorkersKnown && s.Workers == 0 && !s.IsPaused && s.Wait+s.Prioritized > 0
 }
 
 // Job represents a BullMQ job
@@ -93,8 +132,10 @@ type Job struct {
 	ReturnValue  interface{}            `json:"returnvalue"`
 	FinishedOn   int64                  `json:"finishedOn"`
 	ProcessedOn  int64                  `json:"processedOn"`
+	LogCount     int64                  `json:"logCount"`
 	State        string                 `json:"-"` // We'll set this based on which list it's in
 	Queue        string                 `json:"-"` // Queue name
+	Prefix       string                 `json:"-"` // Queue key prefix
 }
 
 // JobSummary is a lighter weight version for list views
@@ -103,24 +144,28 @@ type JobSummary struct {
 	Name         string
 	State        string
 	Queue        string
+	Prefix       string
 	Timestamp    time.Time
+	ProcessedOn  time.Time
 	AttemptsMade int
 	Data         string
 	Opts         string
 	FailedReason string
+	// Lock is only loaded for active jobs.
+	Lock *JobLock
 }
 
-func (e *Explorer) GetQueueStats(ctx context.Context, queues []string) ([]QueueStats, error) {
+func (e *Explorer) GetQueueStats(ctx context.Context, queuePrefix string, queues []string) ([]QueueStats, error) {
 	start := time.Now()
 	defer func() {
-		metrics.RedisOperationDuration.WithLabelValues("get_queue_stats").Observe(time.Since(start).Seconds())
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "get_queue_stats").Observe(time.Since(start).Seconds())
 	}()
 
 	var stats []QueueStats
 
 	// Get stats for each queue individually to handle errors per-queue
 	for _, q := range queues {
-		prefix := fmt.Sprintf("bull:%s", q)
+		prefix := queueKey(queuePrefix, q)
 
 		// Use individual commands instead of pipeline to handle per-queue errors
 		waitLen, _ := e.client.LLen(ctx, prefix+":wait").Result()
@@ -133,10 +178,15 @@ func (e *Explorer) GetQueueStats(ctx context.Context, queues []string) ([]QueueS
 		completedCard, _ := e.client.ZCard(ctx, prefix+":completed").Result()
 		delayedCard, _ := e.client.ZCard(ctx, prefix+":delayed").Result()
 		stalledCard, _ := e.client.ZCard(ctx, prefix+":stalled").Result()
+		isPaused, _ := e.client.HExists(ctx, prefix+":meta", "paused").Result()
+
+		limitPipe := e.client.Pipeline()
+		limitCmds := newQueueLimitCommands(ctx, limitPipe, prefix)
+		_, _ = limitPipe.Exec(ctx)
+		limits, _ := limitCmds.limits()
 
 		// Count total job hashes (all keys matching the job ID pattern)
 		var totalJobHashes int64
-		cursor := uint64(0)
 		jobIDsInQueues := make(map[string]bool)
 
 		// First, collect all job IDs that are in state lists
@@ -191,12 +241,7 @@ func (e *Explorer) GetQueueStats(ctx context.Context, queues []string) ([]QueueS
 		}
 
 		// Now scan for all job hash keys (exclude metadata keys and ensure hash has "name")
-		for {
-			keys, nextCursor, err := e.client.Scan(ctx, cursor, prefix+":*", 100).Result()
-			if err != nil {
-				break
-			}
-
+		_ = e.scanKeys(ctx, prefix+":*", 100, func(keys []string) error {
 			for _, key := range keys {
 				// Extract the suffix after the queue name
 				suffix := strings.TrimPrefix(key, prefix+":")
@@ -221,12 +266,8 @@ func (e *Explorer) GetQueueStats(ctx context.Context, queues []string) ([]QueueS
 
 				totalJobHashes++
 			}
-
-			cursor = nextCursor
-			if cursor == 0 {
-				break
-			}
-		}
+			return nil
+		})
 
 		// Calculate orphaned jobs (job hashes not in any state list)
 		orphanedCount := totalJobHashes - int64(len(jobIDsInQueues))
@@ -235,7 +276,9 @@ func (e *Explorer) GetQueueStats(ctx context.Context, queues []string) ([]QueueS
 		}
 
 		stat := QueueStats{
+			Prefix:          queuePrefix,
 			Name:            q,
+			IsPaused:        isPaused,
 			Wait:            waitLen,
 			Active:          activeLen,
 			Paused:          pausedLen,
@@ -248,16 +291,20 @@ func (e *Explorer) GetQueueStats(ctx context.Context, queues []string) ([]QueueS
 			Orphaned:        orphanedCount,
 			OrphanedKnown:   true,
 			Total:           waitLen + activeLen + pausedLen + prioritizedLen + waitingChildrenLen + failedCard + completedCard + delayedCard + stalledCard + orphanedCount,
+			Limits:          limits,
 		}
 		stats = append(stats, stat)
-
-		updateQueueMetrics(stat)
 	}
 
 	// Ensure we never return nil slice, return empty slice instead
 	if stats == nil {
 		stats = make([]QueueStats, 0)
 	}
+	e.countUnlockedActive(ctx, queuePrefix, stats)
+	e.countWorkers(ctx, queuePrefix, stats)
+	for _, stat := range stats {
+		updateQueueMetrics(e.env, stat)
+	}
 	return stats, nil
 }
 
@@ -265,11 +312,11 @@ func (e *Explorer) GetQueueStats(ctx context.Context, queues []string) ([]QueueS
 func (e *Explorer) Ping(ctx context.Context) error {
 	start := time.Now()
 	defer func() {
-		metrics.RedisOperationDuration.WithLabelValues("ping").Observe(time.Since(start).Seconds())
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "ping").Observe(time.Since(start).Seconds())
 	}()
 
 	if err := e.client.Ping(ctx).Err(); err != nil {
-		metrics.RedisOperationErrors.WithLabelValues("ping").Inc()
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "ping").Inc()
 		return err
 	}
 	return nil
@@ -279,7 +326,7 @@ func (e *Explorer) Ping(ctx context.Context) error {
 func (e *Explorer) GetQueueStatsFast(ctx context.Context, queuePrefix string, queues []string) ([]QueueStats, error) {
 	start := time.Now()
 	defer func() {
-		metrics.RedisOperationDuration.WithLabelValues("get_queue_stats_fast").Observe(time.Since(start).Seconds())
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "get_queue_stats_fast").Observe(time.Since(start).Seconds())
 	}()
 
 	if len(queues) == 0 {
@@ -296,12 +343,14 @@ func (e *Explorer) GetQueueStatsFast(ctx context.Context, queuePrefix string, qu
 		completed       *redis.IntCmd
 		delayed         *redis.IntCmd
 		stalled         *redis.IntCmd
+		isPaused        *redis.BoolCmd
+		limits          queueLimitCommands
 	}
 
 	cmds := make([]queueCommands, len(queues))
 	pipe := e.client.Pipeline()
 	for i, q := range queues {
-		prefix := fmt.Sprintf("%s:%s", queuePrefix, q)
+		prefix := queueKey(queuePrefix, q)
 		cmds[i] = queueCommands{
 			wait:            pipe.LLen(ctx, prefix+":wait"),
 			active:          pipe.LLen(ctx, prefix+":active"),
@@ -312,6 +361,8 @@ func (e *Explorer) GetQueueStatsFast(ctx context.Context, queuePrefix string, qu
 			completed:       pipe.ZCard(ctx, prefix+":completed"),
 			delayed:         pipe.ZCard(ctx, prefix+":delayed"),
 			stalled:         pipe.ZCard(ctx, prefix+":stalled"),
+			isPaused:        pipe.HExists(ctx, prefix+":meta", "paused"),
+			limits:          newQueueLimitCommands(ctx, pipe, prefix),
 		}
 	}
 
@@ -321,52 +372,64 @@ func (e *Explorer) GetQueueStatsFast(ctx context.Context, queuePrefix string, qu
 	for i, q := range queues {
 		waitLen, err := intCmdValue(cmds[i].wait)
 		if err != nil {
-			metrics.RedisOperationErrors.WithLabelValues("get_queue_stats_fast").Inc()
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_queue_stats_fast").Inc()
 			return nil, err
 		}
 		activeLen, err := intCmdValue(cmds[i].active)
 		if err != nil {
-			metrics.RedisOperationErrors.WithLabelValues("get_queue_stats_fast").Inc()
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_queue_stats_fast").Inc()
 			return nil, err
 		}
 		pausedLen, err := intCmdValue(cmds[i].paused)
 		if err != nil {
-			metrics.RedisOperationErrors.WithLabelValues("get_queue_stats_fast").Inc()
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_queue_stats_fast").Inc()
 			return nil, err
 		}
 		prioritizedLen, err := intCmdValue(cmds[i].prioritized)
 		if err != nil {
-			metrics.RedisOperationErrors.WithLabelValues("get_queue_stats_fast").Inc()
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_queue_stats_fast").Inc()
 			return nil, err
 		}
 		waitingChildrenLen, err := intCmdValue(cmds[i].waitingChildren)
 		if err != nil {
-			metrics.RedisOperationErrors.WithLabelValues("get_queue_stats_fast").Inc()
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_queue_stats_fast").Inc()
 			return nil, err
 		}
 		failedLen, err := intCmdValue(cmds[i].failed)
 		if err != nil {
-			metrics.RedisOperationErrors.WithLabelValues("get_queue_stats_fast").Inc()
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_queue_stats_fast").Inc()
 			return nil, err
 		}
 		completedLen, err := intCmdValue(cmds[i].completed)
 		if err != nil {
-			metrics.RedisOperationErrors.WithLabelValues("get_queue_stats_fast").Inc()
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_queue_stats_fast").Inc()
 			return nil, err
 		}
 		delayedLen, err := intCmdValue(cmds[i].delayed)
 		if err != nil {
-			metrics.RedisOperationErrors.WithLabelValues("get_queue_stats_fast").Inc()
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_queue_stats_fast").Inc()
 			return nil, err
 		}
 		stalledLen, err := intCmdValue(cmds[i].stalled)
 		if err != nil {
-			metrics.RedisOperationErrors.WithLabelValues("get_queue_stats_fast").Inc()
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_queue_stats_fast").Inc()
+			return nil, err
+		}
+		isPaused, err := boolCmdValue(cmds[i].isPaused)
+		if err != nil {
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_queue_stats_fast").Inc()
+			return nil, err
+		}
+		limits, err := cmds[i].limits.limits()
+		if err != nil {
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_queue_stats_fast").Inc()
 			return nil, err
 		}
 
 		stat := QueueStats{
+			Prefix:          queuePrefix,
 			Name:            q,
+			IsPaused:        isPaused,
 			Wait:            waitLen,
 			Active:          activeLen,
 			Paused:          pausedLen,
@@ -378,35 +441,41 @@ func (e *Explorer) GetQueueStatsFast(ctx context.Context, queuePrefix string, qu
 			Stalled:         stalledLen,
 			OrphanedKnown:   false,
 			Total:           waitLen + activeLen + pausedLen + prioritizedLen + waitingChildrenLen + failedLen + completedLen + delayedLen + stalledLen,
+			Limits:          limits,
 		}
 		stats = append(stats, stat)
-		updateQueueMetrics(stat)
 	}
 
+	e.countUnlockedActive(ctx, queuePrefix, stats)
+	e.countWorkers(ctx, queuePrefix, stats)
+	for _, stat := range stats {
+		updateQueueMetrics(e.env, stat)
+	}
 	return stats, nil
 }
 
 // GetJob retrieves a single job by ID from a queue
-func (e *Explorer) GetJob(ctx context.Context, queueName, jobID string) (*Job, error) {
+func (e *Explorer) GetJob(ctx context.Context, prefix, queueName, jobID string) (*Job, error) {
 	start := time.Now()
 	defer func() {
-		metrics.RedisOperationDuration.WithLabelValues("get_job").Observe(time.Since(start).Seconds())
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "get_job").Observe(time.Since(start).Seconds())
 	}()
 
-	key := fmt.Sprintf("bull:%s:%s", queueName, jobID)
+	key := queueKey(prefix, queueName) + ":" + jobID
 	data, err := e.client.HGetAll(ctx, key).Result()
 	if err != nil {
-		metrics.RedisOperationErrors.WithLabelValues("get_job").Inc()
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "get_job").Inc()
 		return nil, err
 	}
 
 	if len(data) == 0 {
-		return nil, fmt.Errorf("job not found: %s", jobID)
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
 	}
 
 	job := &Job{
-		ID:    jobID,
-		Queue: queueName,
+		ID:     jobID,
+		Queue:  queueName,
+		Prefix: prefix,
 	}
 
 	// Parse the JSON fields
@@ -437,6 +506,12 @@ func (e *Explorer) GetJob(ctx context.Context, queueName, jobID string) (*Job, e
 			return nil, err
 		}
 	}
+	if delay, ok := data["delay"]; ok {
+		_, err := fmt.Sscanf(delay, "%d", &job.Delay)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if attemptsMade, ok := data["attemptsMade"]; ok {
 		_, err := fmt.Sscanf(attemptsMade, "%d", &job.AttemptsMade)
 		if err != nil {
@@ -471,15 +546,21 @@ func (e *Explorer) GetJob(ctx context.Context, queueName, jobID string) (*Job, e
 		}
 	}
 
+	job.LogCount, err = e.client.LLen(ctx, key+":logs").Result()
+	if err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "get_job").Inc()
+		return nil, err
+	}
+
 	// Determine job state by checking which list/set it's in
-	job.State = e.determineJobState(ctx, queueName, jobID)
+	job.State = e.determineJobState(ctx, prefix, queueName, jobID)
 
 	return job, nil
 }
 
 // determineJobState checks which list/set a job belongs to
-func (e *Explorer) determineJobState(ctx context.Context, queueName, jobID string) string {
-	prefix := fmt.Sprintf("bull:%s", queueName)
+func (e *Explorer) determineJobState(ctx context.Context, queuePrefix, queueName, jobID string) string {
+	prefix := queueKey(queuePrefix, queueName)
 
 	// Check active list
 	if _, err := e.client.LPos(ctx, prefix+":active", jobID, redis.LPosArgs{}).Result(); err == nil {
@@ -524,16 +605,29 @@ func (e *Explorer) determineJobState(ctx context.Context, queueName, jobID strin
 	return "unknown"
 }
 
+// JobStates are the states GetJobsByStatePage can list.
+var JobStates = []string{
+	"waiting",
+	"active",
+	"paused",
+	"prioritized",
+	"waiting-children",
+	"failed",
+	"completed",
+	"delayed",
+	"stalled",
+}
+
 // GetJobsByState retrieves jobs in a specific state (waiting, active, failed, etc.)
-func (e *Explorer) GetJobsByState(ctx context.Context, queueName, state string, limit int) ([]JobSummary, error) {
-	return e.GetJobsByStatePage(ctx, queueName, state, 0, limit)
+func (e *Explorer) GetJobsByState(ctx context.Context, prefix, queueName, state string, limit int) ([]JobSummary, error) {
+	return e.GetJobsByStatePage(ctx, prefix, queueName, state, 0, limit)
 }
 
 // GetJobsByStatePage retrieves jobs in a specific state with offset/limit pagination.
-func (e *Explorer) GetJobsByStatePage(ctx context.Context, queueName, state string, offset, limit int) ([]JobSummary, error) {
+func (e *Explorer) GetJobsByStatePage(ctx context.Context, queuePrefix, queueName, state string, offset, limit int) ([]JobSummary, error) {
 	start := time.Now()
 	defer func() {
-		metrics.RedisOperationDuration.WithLabelValues("get_jobs_by_state").Observe(time.Since(start).Seconds())
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "get_jobs_by_state").Observe(time.Since(start).Seconds())
 	}()
 
 	if limit <= 0 {
@@ -543,7 +637,7 @@ func (e *Explorer) GetJobsByStatePage(ctx context.Context, queueName, state stri
 		offset = 0
 	}
 
-	prefix := fmt.Sprintf("bull:%s", queueName)
+	prefix := queueKey(queuePrefix, queueName)
 	var jobIDs []string
 	var err error
 	startIdx := int64(offset)
@@ -568,7 +662,7 @@ func (e *Explorer) GetJobsByStatePage(ctx context.Context, queueName, state stri
 		// For delayed jobs, we get them from the sorted set
 		results, err := e.client.ZRangeWithScores(ctx, prefix+":delayed", startIdx, endIdx).Result()
 		if err != nil {
-			metrics.RedisOperationErrors.WithLabelValues("get_jobs_by_state").Inc()
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_jobs_by_state").Inc()
 			return nil, err
 		}
 		for _, z := range results {
@@ -583,36 +677,24 @@ func (e *Explorer) GetJobsByStatePage(ctx context.Context, queueName, state stri
 	}
 
 	if err != nil {
-		metrics.RedisOperationErrors.WithLabelValues("get_jobs_by_state").Inc()
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "get_jobs_by_state").Inc()
 		return nil, err
 	}
 
-	return e.loadJobSummaries(ctx, queueName, state, jobIDs)
+	return e.loadJobSummaries(ctx, queuePrefix, queueName, state, jobIDs)
 }
 
 // GetJobsAcrossStates retrieves jobs from all known states for a queue.
-func (e *Explorer) GetJobsAcrossStates(ctx context.Context, queueName string, limitPerState int) ([]JobSummary, error) {
-	return e.GetJobsAcrossStatesPage(ctx, queueName, 0, limitPerState)
+func (e *Explorer) GetJobsAcrossStates(ctx context.Context, prefix, queueName string, limitPerState int) ([]JobSummary, error) {
+	return e.GetJobsAcrossStatesPage(ctx, prefix, queueName, 0, limitPerState)
 }
 
 // GetJobsAcrossStatesPage retrieves jobs from all known states for a queue with offset/limit pagination per state.
-func (e *Explorer) GetJobsAcrossStatesPage(ctx context.Context, queueName string, offsetPerState, limitPerState int) ([]JobSummary, error) {
-	states := []string{
-		"waiting",
-		"active",
-		"paused",
-		"prioritized",
-		"waiting-children",
-		"failed",
-		"completed",
-		"delayed",
-		"stalled",
-	}
-
+func (e *Explorer) GetJobsAcrossStatesPage(ctx context.Context, prefix, queueName string, offsetPerState, limitPerState int) ([]JobSummary, error) {
 	seen := make(map[string]bool)
 	var summaries []JobSummary
-	for _, state := range states {
-		jobs, err := e.GetJobsByStatePage(ctx, queueName, state, offsetPerState, limitPerState)
+	for _, state := range JobStates {
+		jobs, err := e.GetJobsByStatePage(ctx, prefix, queueName, state, offsetPerState, limitPerState)
 		if err != nil {
 			return nil, err
 		}
@@ -635,6 +717,13 @@ func intCmdValue(cmd *redis.IntCmd) (int64, error) {
 	return cmd.Val(), nil
 }
 
+func boolCmdValue(cmd *redis.BoolCmd) (bool, error) {
+	if err := cmd.Err(); err != nil && !isBenignCountError(err) {
+		return false, err
+	}
+	return cmd.Val(), nil
+}
+
 func isBenignCountError(err error) bool {
 	if err == nil || err == redis.Nil {
 		return true
@@ -642,35 +731,59 @@ func isBenignCountError(err error) bool {
 	return strings.Contains(err.Error(), "WRONGTYPE")
 }
 
-func updateQueueMetrics(stat QueueStats) {
-	metrics.QueueWaiting.WithLabelValues(stat.Name).Set(float64(stat.Wait))
-	metrics.QueueActive.WithLabelValues(stat.Name).Set(float64(stat.Active))
-	metrics.QueuePaused.WithLabelValues(stat.Name).Set(float64(stat.Paused))
-	metrics.QueuePrioritized.WithLabelValues(stat.Name).Set(float64(stat.Prioritized))
-	metrics.QueueWaitingChildren.WithLabelValues(stat.Name).Set(float64(stat.WaitingChildren))
-	metrics.QueueFailed.WithLabelValues(stat.Name).Set(float64(stat.Failed))
-	metrics.QueueCompleted.WithLabelValues(stat.Name).Set(float64(stat.Completed))
-	metrics.QueueDelayed.WithLabelValues(stat.Name).Set(float64(stat.Delayed))
-	metrics.QueueStalled.WithLabelValues(stat.Name).Set(float64(stat.Stalled))
+func updateQueueMetrics(env string, stat QueueStats) {
+	metrics.QueueWaiting.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Wait))
+	metrics.QueueActive.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Active))
+	metrics.QueueGlobalConcurrency.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Limits.GlobalConcurrency))
+	metrics.QueueRateLimitMax.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Limits.RateLimitMax))
+	metrics.QueueRateLimitDuration.WithLabelValues(env, stat.Prefix, stat.Name).Set(stat.Limits.RateLimitDuration.Seconds())
+	metrics.QueueRateLimiterCount.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Limits.LimiterCount))
+	metrics.QueueRateLimitReset.WithLabelValues(env, stat.Prefix, stat.Name).Set(stat.Limits.LimiterResetIn.Seconds())
+	metrics.QueueRateLimited.WithLabelValues(env, stat.Prefix, stat.Name).Set(boolGauge(stat.Limits.RateLimited))
+	metrics.QueuePriorityCounter.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Limits.PriorityCounter))
+	metrics.QueuePaused.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Paused))
+	metrics.QueuePrioritized.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Prioritized))
+	metrics.QueueWaitingChildren.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.WaitingChildren))
+	metrics.QueueFailed.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Failed))
+	metrics.QueueCompleted.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Completed))
+	metrics.QueueDelayed.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Delayed))
+	metrics.QueueStalled.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Stalled))
+	if stat.ActiveUnlockedKnown {
+		metrics.QueueActiveUnlocked.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.ActiveUnlocked))
+	}
 	if stat.OrphanedKnown {
-		metrics.QueueOrphaned.WithLabelValues(stat.Name).Set(float64(stat.Orphaned))
+		metrics.QueueOrphaned.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Orphaned))
 	}
+	if stat.WorkersKnown {
+		metrics.QueueWorkers.WithLabelValues(env, stat.Prefix, stat.Name).Set(float64(stat.Workers))
+	}
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
 }
 
-func (e *Explorer) loadJobSummaries(ctx context.Context, queueName, state string, jobIDs []string) ([]JobSummary, error) {
+func (e *Explorer) loadJobSummaries(ctx context.Context, prefix, queueName, state string, jobIDs []string) ([]JobSummary, error) {
 	if len(jobIDs) == 0 {
 		return make([]JobSummary, 0), nil
 	}
 
 	pipe := e.client.Pipeline()
 	cmds := make([]*redis.MapStringStringCmd, 0, len(jobIDs))
+	var lockCmds []jobLockCommands
 	for _, jobID := range jobIDs {
-		key := fmt.Sprintf("bull:%s:%s", queueName, jobID)
+		key := queueKey(prefix, queueName) + ":" + jobID
 		cmds = append(cmds, pipe.HGetAll(ctx, key))
+		if state == "active" {
+			lockCmds = append(lockCmds, newJobLockCommands(ctx, pipe, key))
+		}
 	}
 
 	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
-		metrics.RedisOperationErrors.WithLabelValues("get_jobs_by_state").Inc()
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "get_jobs_by_state").Inc()
 		return nil, err
 	}
 
@@ -687,10 +800,11 @@ func (e *Explorer) loadJobSummaries(ctx context.Context, queueName, state string
 
 		jobID := jobIDs[idx]
 		summary := JobSummary{
-			ID:    jobID,
-			Name:  data["name"],
-			State: state,
-			Queue: queueName,
+			ID:     jobID,
+			Name:   data["name"],
+			State:  state,
+			Queue:  queueName,
+			Prefix: prefix,
 		}
 
 		if timestamp := data["timestamp"]; timestamp != "" {
@@ -699,9 +813,15 @@ func (e *Explorer) loadJobSummaries(ctx context.Context, queueName, state string
 				summary.Timestamp = time.Unix(ts/1000, 0)
 			}
 		}
+		if processedOn, err := strconv.ParseInt(data["processedOn"], 10, 64); err == nil && processedOn > 0 {
+			summary.ProcessedOn = time.UnixMilli(processedOn)
+		}
 		if attemptsMade := data["attemptsMade"]; attemptsMade != "" {
 			_, _ = fmt.Sscanf(attemptsMade, "%d", &summary.AttemptsMade)
 		}
+		if lockCmds != nil {
+			summary.Lock = lockCmds[idx].lock()
+		}
 		if failedReason, ok := data["failedReason"]; ok {
 			summary.FailedReason = failedReason
 		}
@@ -0,0 +1,269 @@
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	flowMaxDepth    = 5
+	flowMaxChildren = 100
+	flowMaxParents  = 20
+)
+
+// FlowNode is a job in a FlowProducer tree. Children may live in other queues.
+type FlowNode struct {
+	Prefix string `json:"prefix"`
+	Queue  string `json:"queue"`
+	ID     string `json:"id"`
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	// State is the job's state, or "missing" when its hash no longer exists.
+	State string `json:"state"`
+	// Relation is how the parent sees this job: "pending" while it is still
+	// in the parent's :dependencies set, then "processed" or "failed".
+	Relation string `json:"relation,omitempty"`
+
+	Pending   int64 `json:"pending"`
+	Processed int64 `json:"processed"`
+	Failed    int64 `json:"failed"`
+
+	Children []*FlowNode `json:"children,omitempty"`
+	// OmittedChildren counts children left out by the depth or width limits.
+	OmittedChildren int64 `json:"omittedChildren,omitempty"`
+}
+
+// JobFlow is a job's place in a flow: its parent chain, root first, and the
+// tree of its descendants.
+type JobFlow struct {
+	Parents []*FlowNode `json:"parents"`
+	Tree    *FlowNode   `json:"tree"`
+}
+
+// IsFlow reports whether the job has a parent or any children.
+func (f *JobFlow) IsFlow() bool {
+	return len(f.Parents) > 0 || f.Tree.Pending+f.Tree.Processed+f.Tree.Failed > 0
+}
+
+// GetJobFlow resolves a job's parents (via parentKey) and its children (via
+// the :dependencies set and the :processed, :failed and :unsuccessful
+// records), with each job's state. Pending children are listed first so the
+// ones holding up a waiting-children parent are easy to spot.
+func (e *Explorer) GetJobFlow(ctx context.Context, queuePrefix, queueName, jobID string) (*JobFlow, error) {
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "get_job_flow").Observe(time.Since(start).Seconds())
+	}()
+
+	root := &FlowNode{Prefix: queuePrefix, Queue: queueName, ID: jobID, Key: queueKey(queuePrefix, queueName) + ":" + jobID}
+	if err := e.loadFlowNodes(ctx, []*FlowNode{root}); err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "get_job_flow").Inc()
+		return nil, err
+	}
+	if root.State == "missing" {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+	}
+
+	parents, err := e.loadFlowParents(ctx, root.Key)
+	if err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "get_job_flow").Inc()
+		return nil, err
+	}
+
+	level := []*FlowNode{root}
+	for depth := 0; len(level) > 0; depth++ {
+		level, err = e.loadFlowChildren(ctx, level, depth < flowMaxDepth)
+		if err != nil {
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "get_job_flow").Inc()
+			return nil, err
+		}
+	}
+
+	return &JobFlow{Parents: parents, Tree: root}, nil
+}
+
+// loadFlowParents follows parentKey links upwards and returns the chain root
+// first.
+func (e *Explorer) loadFlowParents(ctx context.Context, key string) ([]*FlowNode, error) {
+	var parents []*FlowNode
+	seen := map[string]struct{}{key: {}}
+	for len(parents) < flowMaxParents {
+		parentKey, err := e.client.HGet(ctx, key, "parentKey").Result()
+		if errors.Is(err, redis.Nil) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[parentKey]; ok || parentKey == "" {
+			break
+		}
+		seen[parentKey] = struct{}{}
+
+		parent := &FlowNode{Key: parentKey}
+		if err := e.loadFlowNodes(ctx, []*FlowNode{parent}); err != nil {
+			return nil, err
+		}
+		parents = append([]*FlowNode{parent}, parents...)
+		key = parentKey
+	}
+	return parents, nil
+}
+
+// loadFlowChildren records child counts for every node in a level and, when
+// expand is set, loads up to flowMaxChildren children per node. Returns the
+// next level.
+func (e *Explorer) loadFlowChildren(ctx context.Context, level []*FlowNode, expand bool) ([]*FlowNode, error) {
+	type childCmds struct {
+		pending      *redis.StringSliceCmd
+		processed    *redis.StringSliceCmd
+		failed       *redis.StringSliceCmd
+		unsuccessful *redis.StringSliceCmd
+	}
+
+	cmds := make([]childCmds, len(level))
+	pipe := e.client.Pipeline()
+	for i, node := range level {
+		cmds[i] = childCmds{
+			pending:      pipe.SMembers(ctx, node.Key+":dependencies"),
+			processed:    pipe.HKeys(ctx, node.Key+":processed"),
+			failed:       pipe.HKeys(ctx, node.Key+":failed"),
+			unsuccessful: pipe.ZRange(ctx, node.Key+":unsuccessful", 0, -1),
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	var next []*FlowNode
+	for i, node := range level {
+		pending := cmds[i].pending.Val()
+		processed := cmds[i].processed.Val()
+		// A child can be in both the failed and the ignored-failure sets.
+		failed := append(cmds[i].failed.Val(), cmds[i].unsuccessful.Val()...)
+		slices.Sort(failed)
+		failed = slices.Compact(failed)
+		sort.Strings(pending)
+		sort.Strings(processed)
+
+		node.Pending = int64(len(pending))
+		node.Processed = int64(len(processed))
+		node.Failed = int64(len(failed))
+		total := node.Pending + node.Processed + node.Failed
+		if !expand {
+			node.OmittedChildren = total
+			continue
+		}
+
+		for _, group := range []struct {
+			relation string
+			keys     []string
+		}{
+			{"pending", pending},
+			{"failed", failed},
+			{"processed", processed},
+		} {
+			for _, key := range group.keys {
+				if len(node.Children) >= flowMaxChildren {
+					break
+				}
+				node.Children = append(node.Children, &FlowNode{Key: key, Relation: group.relation})
+			}
+		}
+		node.OmittedChildren = total - int64(len(node.Children))
+		next = append(next, node.Children...)
+	}
+
+	if err := e.loadFlowNodes(ctx, next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// loadFlowNodes fills in prefix, queue, ID, name and state for nodes with a Key, in a
+// single pipeline. State checks follow determineJobState's order.
+func (e *Explorer) loadFlowNodes(ctx context.Context, nodes []*FlowNode) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	type stateCheck struct {
+		state string
+		cmd   redis.Cmder
+	}
+	type nodeCmds struct {
+		exists *redis.IntCmd
+		name   *redis.StringCmd
+		checks []stateCheck
+	}
+
+	cmds := make([]nodeCmds, len(nodes))
+	pipe := e.client.Pipeline()
+	for i, node := range nodes {
+		queueKey, jobID := splitJobKey(node.Key)
+		node.ID = jobID
+		if node.Queue == "" {
+			i := strings.LastIndex(queueKey, ":")
+			node.Prefix, node.Queue = queueKey[:max(i, 0)], queueKey[i+1:]
+		}
+
+		cmds[i] = nodeCmds{
+			exists: pipe.Exists(ctx, node.Key),
+			name:   pipe.HGet(ctx, node.Key, "name"),
+			checks: []stateCheck{
+				{"active", pipe.LPos(ctx, queueKey+":active", jobID, redis.LPosArgs{})},
+				{"waiting", pipe.LPos(ctx, queueKey+":wait", jobID, redis.LPosArgs{})},
+				{"paused", pipe.LPos(ctx, queueKey+":paused", jobID, redis.LPosArgs{})},
+				{"prioritized", pipe.ZScore(ctx, queueKey+":prioritized", jobID)},
+				{"waiting-children", pipe.ZScore(ctx, queueKey+":waiting-children", jobID)},
+				{"failed", pipe.ZScore(ctx, queueKey+":failed", jobID)},
+				{"completed", pipe.ZScore(ctx, queueKey+":completed", jobID)},
+				{"delayed", pipe.ZScore(ctx, queueKey+":delayed", jobID)},
+			},
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	for i, node := range nodes {
+		if err := cmds[i].exists.Err(); err != nil {
+			return err
+		}
+		if cmds[i].exists.Val() == 0 {
+			node.State = "missing"
+			continue
+		}
+		node.Name = cmds[i].name.Val()
+		node.State = "unknown"
+		for _, check := range cmds[i].checks {
+			err := check.cmd.Err()
+			if err == nil {
+				node.State = check.state
+				break
+			}
+			if !errors.Is(err, redis.Nil) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// splitJobKey splits "<prefix>:<queue>:<id>" into the queue key and job ID.
+// BullMQ job IDs cannot contain ":", so the ID is everything after the last one.
+func splitJobKey(key string) (queueKey, jobID string) {
+	i := strings.LastIndex(key, ":")
+	if i < 0 {
+		return "", key
+	}
+	return key[:i], key[i+1:]
+}
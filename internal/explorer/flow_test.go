@@ -0,0 +1,34 @@
+package explorer
+
+import "testing"
+
+func TestSplitJobKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		queueKey string
+		jobID    string
+	}{
+		{key: "bull:orders:42", queueKey: "bull:orders", jobID: "42"},
+		{key: "{app}:bull:orders:ship-1", queueKey: "{app}:bull:orders", jobID: "ship-1"},
+		{key: "42", queueKey: "", jobID: "42"},
+	}
+
+	for _, tc := range tests {
+		queueKey, jobID := splitJobKey(tc.key)
+		if queueKey != tc.queueKey || jobID != tc.jobID {
+			t.Fatalf("splitJobKey(%q) = (%q, %q), want (%q, %q)", tc.key, queueKey, jobID, tc.queueKey, tc.jobID)
+		}
+	}
+}
+
+func TestJobFlowIsFlow(t *testing.T) {
+	if (&JobFlow{Tree: &FlowNode{}}).IsFlow() {
+		t.Fatal("expected a job without parents or children not to be a flow")
+	}
+	if !(&JobFlow{Tree: &FlowNode{Pending: 1}}).IsFlow() {
+		t.Fatal("expected a job with children to be a flow")
+	}
+	if !(&JobFlow{Parents: []*FlowNode{{ID: "1"}}, Tree: &FlowNode{}}).IsFlow() {
+		t.Fatal("expected a job with a parent to be a flow")
+	}
+}
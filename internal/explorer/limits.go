@@ -0,0 +1,95 @@
+package explorer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// manualRateLimitCount is what Worker.rateLimit() writes to the limiter key to
+// hold every worker off until the key expires.
+const manualRateLimitCount = 999999999
+
+// QueueLimits is a queue's rate limiter and global concurrency state.
+type QueueLimits struct {
+	// GlobalConcurrency is Queue.setGlobalConcurrency(); 0 means unlimited.
+	GlobalConcurrency int64
+	// RateLimitMax and RateLimitDuration are Queue.setGlobalRateLimit().
+	// Worker-level limiter options are not stored in Redis, so both are 0
+	// for queues limited only by their workers.
+	RateLimitMax      int64
+	RateLimitDuration time.Duration
+	// LimiterCount is the number of jobs counted in the current window.
+	LimiterCount int64
+	// LimiterResetIn is how long until the current window ends.
+	LimiterResetIn time.Duration
+	// RateLimited is set while workers are being held off by the limiter.
+	RateLimited bool
+	// PriorityCounter is the :pc counter BullMQ uses to order prioritized jobs.
+	PriorityCounter int64
+}
+
+type queueLimitCommands struct {
+	meta     *redis.SliceCmd
+	limiter  *redis.StringCmd
+	resetIn  *redis.DurationCmd
+	priority *redis.StringCmd
+}
+
+func newQueueLimitCommands(ctx context.Context, pipe redis.Pipeliner, prefix string) queueLimitCommands {
+	return queueLimitCommands{
+		meta:     pipe.HMGet(ctx, prefix+":meta", "concurrency", "max", "duration"),
+		limiter:  pipe.Get(ctx, prefix+":limiter"),
+		resetIn:  pipe.PTTL(ctx, prefix+":limiter"),
+		priority: pipe.Get(ctx, prefix+":pc"),
+	}
+}
+
+func (c queueLimitCommands) limits() (QueueLimits, error) {
+	for _, cmd := range []redis.Cmder{c.meta, c.limiter, c.resetIn, c.priority} {
+		if err := cmd.Err(); err != nil && !isBenignCountError(err) {
+			return QueueLimits{}, err
+		}
+	}
+
+	meta := c.meta.Val()
+	limits := QueueLimits{
+		GlobalConcurrency: parseLimitValue(meta[0]),
+		RateLimitMax:      parseLimitValue(meta[1]),
+		RateLimitDuration: time.Duration(parseLimitValue(meta[2])) * time.Millisecond,
+		PriorityCounter:   parseLimitValue(c.priority.Val()),
+	}
+
+	// PTTL reports -1 (no expiry) and -2 (no key) as raw values; only a
+	// positive TTL means a window is open.
+	if resetIn := c.resetIn.Val(); resetIn > 0 {
+		limits.LimiterCount = parseLimitValue(c.limiter.Val())
+		limits.LimiterResetIn = resetIn
+		limits.RateLimited = isRateLimited(limits.LimiterCount, limits.RateLimitMax)
+	}
+	return limits, nil
+}
+
+// isRateLimited mirrors BullMQ's getRateLimitTTL: workers stop fetching once
+// the window's count reaches max. A manual Worker.rateLimit() is recognisable
+// without knowing max.
+func isRateLimited(count, maxJobs int64) bool {
+	if count >= manualRateLimitCount {
+		return true
+	}
+	return maxJobs > 0 && count >= maxJobs
+}
+
+func parseLimitValue(value any) int64 {
+	if value == nil {
+		return 0
+	}
+	n, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return int64(n)
+}
@@ -0,0 +1,65 @@
+package explorer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestQueueLimitCommandsLimits(t *testing.T) {
+	cmds := queueLimitCommands{
+		meta:     redis.NewSliceResult([]any{"5", "100", "1000"}, nil),
+		limiter:  redis.NewStringResult("100", nil),
+		resetIn:  redis.NewDurationResult(350*time.Millisecond, nil),
+		priority: redis.NewStringResult("42", nil),
+	}
+
+	limits, err := cmds.limits()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limits.GlobalConcurrency != 5 || limits.RateLimitMax != 100 || limits.RateLimitDuration != time.Second {
+		t.Fatalf("unexpected configured limits: %+v", limits)
+	}
+	if !limits.RateLimited || limits.LimiterCount != 100 || limits.LimiterResetIn != 350*time.Millisecond {
+		t.Fatalf("expected an exhausted window, got %+v", limits)
+	}
+	if limits.PriorityCounter != 42 {
+		t.Fatalf("expected priority counter 42, got %d", limits.PriorityCounter)
+	}
+}
+
+func TestQueueLimitCommandsWithoutLimiterKey(t *testing.T) {
+	cmds := queueLimitCommands{
+		meta:     redis.NewSliceResult([]any{nil, nil, nil}, nil),
+		limiter:  redis.NewStringResult("", redis.Nil),
+		resetIn:  redis.NewDurationResult(-2, nil),
+		priority: redis.NewStringResult("", redis.Nil),
+	}
+
+	limits, err := cmds.limits()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limits != (QueueLimits{}) {
+		t.Fatalf("expected zero limits, got %+v", limits)
+	}
+}
+
+func TestIsRateLimited(t *testing.T) {
+	tests := []struct {
+		count, max int64
+		want       bool
+	}{
+		{count: 3, max: 10, want: false},
+		{count: 10, max: 10, want: true},
+		{count: 10, max: 0, want: false},
+		{count: manualRateLimitCount, max: 0, want: true},
+	}
+	for _, tt := range tests {
+		if got := isRateLimited(tt.count, tt.max); got != tt.want {
+			t.Errorf("isRateLimited(%d, %d) = %v, want %v", tt.count, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package explorer
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// activeLockScanLimit bounds how many active jobs per queue are checked for a
+// lock when collecting stats.
+const activeLockScanLimit = 1000
+
+// JobLock is the lock a worker holds on an active job, read from
+// "<queue>:<id>:lock". Workers renew it while processing; when it lapses the
+// job stays in the active list until the stalled-job checker moves it.
+type JobLock struct {
+	// Token identifies the worker holding the lock. It is empty when the lock
+	// is missing.
+	Token string
+	// ExpiresIn is the lock's remaining TTL.
+	ExpiresIn time.Duration
+}
+
+// Missing reports whether the job has no lock: a stalled-job candidate when
+// it is still active.
+func (l JobLock) Missing() bool {
+	return l.Token == ""
+}
+
+type jobLockCommands struct {
+	token     *redis.StringCmd
+	expiresIn *redis.DurationCmd
+}
+
+func newJobLockCommands(ctx context.Context, pipe redis.Pipeliner, jobKey string) jobLockCommands {
+	return jobLockCommands{
+		token:     pipe.Get(ctx, jobKey+":lock"),
+		expiresIn: pipe.PTTL(ctx, jobKey+":lock"),
+	}
+}
+
+func (c jobLockCommands) lock() *JobLock {
+	lock := &JobLock{Token: c.token.Val()}
+	if expiresIn := c.expiresIn.Val(); expiresIn > 0 && lock.Token != "" {
+		lock.ExpiresIn = expiresIn.Round(time.Millisecond)
+	}
+	return lock
+}
+
+// countUnlockedActive fills in ActiveUnlocked for stats with active jobs,
+// running the count script for every queue in one pipeline. When the count
+// fails the stats are still good; ActiveUnlockedKnown stays false.
+func (e *Explorer) countUnlockedActive(ctx context.Context, queuePrefix string, stats []QueueStats) {
+	if err := e.runCountUnlockedActive(ctx, queuePrefix, stats); err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "count_unlocked_active").Inc()
+		log.Printf("⚠️ counting unlocked active jobs failed (prefix=%s): %v", queuePrefix, err)
+		return
+	}
+	for i := range stats {
+		stats[i].ActiveUnlockedKnown = true
+	}
+}
+
+func (e *Explorer) runCountUnlockedActive(ctx context.Context, queuePrefix string, stats []QueueStats) error {
+	run := func() (map[int]*redis.Cmd, error) {
+		cmds := make(map[int]*redis.Cmd)
+		pipe := e.client.Pipeline()
+		for i, stat := range stats {
+			if stat.Active == 0 {
+				continue
+			}
+			prefix := queuePrefix + ":" + stat.Name
+			cmds[i] = countUnlockedActiveScript.EvalSha(ctx, pipe, []string{prefix + ":active"}, prefix+":", activeLockScanLimit)
+		}
+		if len(cmds) == 0 {
+			return cmds, nil
+		}
+		_, err := pipe.Exec(ctx)
+		return cmds, err
+	}
+
+	cmds, err := run()
+	if err != nil && redis.HasErrorPrefix(err, "NOSCRIPT") {
+		if err := countUnlockedActiveScript.Load(ctx, e.client).Err(); err != nil {
+			return err
+		}
+		cmds, err = run()
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, cmd := range cmds {
+		stats[i].ActiveUnlocked, _ = cmd.Int64()
+	}
+	return nil
+}
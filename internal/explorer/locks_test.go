@@ -0,0 +1,26 @@
+package explorer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestJobLockCommandsLock(t *testing.T) {
+	held := jobLockCommands{
+		token:     redis.NewStringResult("worker-1:42", nil),
+		expiresIn: redis.NewDurationResult(29871400*time.Microsecond, nil),
+	}.lock()
+	if held.Missing() || held.Token != "worker-1:42" || held.ExpiresIn != 29871*time.Millisecond {
+		t.Fatalf("unexpected held lock: %+v", held)
+	}
+
+	missing := jobLockCommands{
+		token:     redis.NewStringResult("", redis.Nil),
+		expiresIn: redis.NewDurationResult(-2, nil),
+	}.lock()
+	if !missing.Missing() || missing.ExpiresIn != 0 {
+		t.Fatalf("unexpected missing lock: %+v", missing)
+	}
+}
@@ -0,0 +1,57 @@
+package explorer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// JobLogs is a page of the lines a job wrote with job.log().
+type JobLogs struct {
+	Logs []string `json:"logs"`
+	// Count is the total number of lines currently stored for the job.
+	Count int64 `json:"count"`
+	// Offset is the index of the first returned line.
+	Offset int64 `json:"offset"`
+}
+
+// GetJobLogs returns up to limit lines from "<prefix>:<queue>:<id>:logs" starting
+// at offset, like BullMQ's Queue.getJobLogs(). With tail set the last limit
+// lines are returned and offset is ignored.
+func (e *Explorer) GetJobLogs(ctx context.Context, queuePrefix, queueName, jobID string, offset, limit int64, tail bool) (JobLogs, error) {
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "get_job_logs").Observe(time.Since(start).Seconds())
+	}()
+
+	if limit <= 0 {
+		return JobLogs{}, fmt.Errorf("invalid log limit %d", limit)
+	}
+	offset = max(offset, 0)
+
+	key := queueKey(queuePrefix, queueName) + ":" + jobID + ":logs"
+	var countCmd *redis.IntCmd
+	var linesCmd *redis.StringSliceCmd
+	_, err := e.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		countCmd = pipe.LLen(ctx, key)
+		if tail {
+			linesCmd = pipe.LRange(ctx, key, -limit, -1)
+		} else {
+			linesCmd = pipe.LRange(ctx, key, offset, offset+limit-1)
+		}
+		return nil
+	})
+	if err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "get_job_logs").Inc()
+		return JobLogs{}, err
+	}
+
+	logs := JobLogs{Logs: linesCmd.Val(), Count: countCmd.Val(), Offset: offset}
+	if tail {
+		logs.Offset = max(logs.Count-int64(len(logs.Logs)), 0)
+	}
+	return logs, nil
+}
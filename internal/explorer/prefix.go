@@ -0,0 +1,72 @@
+package explorer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultPrefix is BullMQ's default queue key prefix.
+const DefaultPrefix = "bull"
+
+var ErrUnknownPrefix = errors.New("unknown queue prefix")
+
+// QueueRef identifies a queue. BullMQ keeps a queue's keys under
+// "<Prefix>:<Name>:", so the same name under two prefixes is two queues.
+type QueueRef struct {
+	Prefix string `json:"prefix"`
+	Name   string `json:"name"`
+}
+
+// Prefixes returns the configured queue key prefixes, default first.
+func (e *Explorer) Prefixes() []string {
+	return append([]string(nil), e.prefixes...)
+}
+
+// ResolvePrefix returns prefix when it is configured, or the default prefix
+// when it is empty. Other prefixes are refused so callers cannot reach keys
+// outside the configured queues.
+func (e *Explorer) ResolvePrefix(prefix string) (string, error) {
+	if prefix == "" {
+		return e.prefixes[0], nil
+	}
+	if !slices.Contains(e.prefixes, prefix) {
+		return "", fmt.Errorf("%w: %s", ErrUnknownPrefix, prefix)
+	}
+	return prefix, nil
+}
+
+// CheckClusterPrefix refuses a prefix whose queue keys could land in
+// different Redis Cluster slots. The action scripts build job and parent keys
+// from the IDs they read, as BullMQ's own scripts do, so on a cluster every
+// key under the prefix must share its slot. Redis hashes only the first
+// non-empty "{...}" of a key, so the prefix has to carry one, as in "{bull}".
+func CheckClusterPrefix(prefix string) error {
+	if open := strings.IndexByte(prefix, '{'); open >= 0 {
+		if end := strings.IndexByte(prefix[open+1:], '}'); end > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("queue prefix %q has no hash tag: in cluster mode use one such as %q, as your BullMQ workers must, so every queue key hashes to one slot", prefix, "{"+prefix+"}")
+}
+
+// queueKey returns the key prefix shared by a queue's keys, "<prefix>:<queue>".
+func queueKey(prefix, queueName string) string {
+	return prefix + ":" + queueName
+}
+
+// Key returns the queue's key prefix, "<prefix>:<queue>".
+func (q QueueRef) Key() string {
+	return queueKey(q.Prefix, q.Name)
+}
+
+// ParseQueueRef splits a "<prefix>:<queue>" key. BullMQ queue names cannot
+// contain ":", so the name is everything after the last one.
+func ParseQueueRef(key string) (QueueRef, bool) {
+	i := strings.LastIndex(key, ":")
+	if i <= 0 || i == len(key)-1 {
+		return QueueRef{}, false
+	}
+	return QueueRef{Prefix: key[:i], Name: key[i+1:]}, true
+}
@@ -0,0 +1,65 @@
+package explorer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolvePrefix(t *testing.T) {
+	exp := New(nil, "bull", "billing")
+
+	if got, err := exp.ResolvePrefix(""); err != nil || got != "bull" {
+		t.Fatalf("expected the first prefix as default, got %q, %v", got, err)
+	}
+	if got, err := exp.ResolvePrefix("billing"); err != nil || got != "billing" {
+		t.Fatalf("expected configured prefix, got %q, %v", got, err)
+	}
+	if _, err := exp.ResolvePrefix("other"); !errors.Is(err, ErrUnknownPrefix) {
+		t.Fatalf("expected ErrUnknownPrefix, got %v", err)
+	}
+}
+
+func TestCheckClusterPrefix(t *testing.T) {
+	for _, prefix := range []string{"{bull}", "app:{jobs}", "{bull}:eu", "{a}{b}"} {
+		if err := CheckClusterPrefix(prefix); err != nil {
+			t.Fatalf("CheckClusterPrefix(%q) = %v, want nil", prefix, err)
+		}
+	}
+	// "{}" is ignored by Redis, and a tag closed only inside the queue name
+	// would give every queue its own slot.
+	for _, prefix := range []string{"bull", "{}bull", "{bull", "bull}", "}{bull"} {
+		if err := CheckClusterPrefix(prefix); err == nil {
+			t.Fatalf("CheckClusterPrefix(%q) accepted a prefix without a hash tag", prefix)
+		}
+	}
+}
+
+func TestNewDefaultsToBullPrefix(t *testing.T) {
+	if got := New(nil).Prefixes(); len(got) != 1 || got[0] != DefaultPrefix {
+		t.Fatalf("expected [%s], got %v", DefaultPrefix, got)
+	}
+}
+
+func TestParseQueueRef(t *testing.T) {
+	tests := []struct {
+		key  string
+		want QueueRef
+		ok   bool
+	}{
+		{key: "bull:orders", want: QueueRef{Prefix: "bull", Name: "orders"}, ok: true},
+		{key: "{app}:bull:orders", want: QueueRef{Prefix: "{app}:bull", Name: "orders"}, ok: true},
+		{key: "orders"},
+		{key: ":orders"},
+		{key: "bull:"},
+	}
+
+	for _, tc := range tests {
+		got, ok := ParseQueueRef(tc.key)
+		if ok != tc.ok || got != tc.want {
+			t.Fatalf("ParseQueueRef(%q) = %+v, %t; want %+v, %t", tc.key, got, ok, tc.want, tc.ok)
+		}
+		if ok && got.Key() != tc.key {
+			t.Fatalf("Key() = %q, want %q", got.Key(), tc.key)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package explorer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scanKeys runs SCAN MATCH pattern to completion and hands each page of keys
+// to fn. A cluster's keyspace is split across its masters, so against a
+// ClusterClient every master is scanned; fn is never called concurrently.
+func (e *Explorer) scanKeys(ctx context.Context, pattern string, count int64, fn func(keys []string) error) error {
+	cluster, ok := e.client.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(ctx, e.client, pattern, count, fn)
+	}
+
+	var mu sync.Mutex
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+		return scanNode(ctx, master, pattern, count, func(keys []string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return fn(keys)
+		})
+	})
+}
+
+func scanNode(ctx context.Context, client redis.Cmdable, pattern string, count int64, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, nextCursor, err := client.Scan(ctx, cursor, pattern, count).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		cursor = nextCursor
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
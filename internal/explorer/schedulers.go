@@ -0,0 +1,154 @@
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrJobSchedulerNotFound = errors.New("job scheduler not found")
+
+// JobScheduler is a repeatable job or job scheduler from the "<queue>:repeat"
+// zset and its "<queue>:repeat:<key>" hash.
+type JobScheduler struct {
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	Pattern string `json:"pattern,omitempty"`
+	// Every is the repeat interval in milliseconds.
+	Every int64  `json:"every,omitempty"`
+	TZ    string `json:"tz,omitempty"`
+	// NextRun comes from the zset score: when the next instance is due.
+	NextRun    time.Time `json:"nextRun"`
+	Iterations int64     `json:"iterations"`
+	Limit      int64     `json:"limit,omitempty"`
+	StartDate  int64     `json:"startDate,omitempty"`
+	EndDate    int64     `json:"endDate,omitempty"`
+	// Data and Opts are the template the scheduler stamps onto each job.
+	Data string `json:"data,omitempty"`
+	Opts string `json:"opts,omitempty"`
+	// Legacy marks repeatable jobs created before job schedulers, whose
+	// options are encoded in the key itself.
+	Legacy bool `json:"legacy,omitempty"`
+}
+
+// GetJobSchedulers lists a page of job schedulers ordered by next run, like
+// BullMQ's Queue.getJobSchedulers(), and returns the total count.
+func (e *Explorer) GetJobSchedulers(ctx context.Context, queuePrefix, queueName string, offset, limit int) ([]JobScheduler, int64, error) {
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "get_job_schedulers").Observe(time.Since(start).Seconds())
+	}()
+
+	repeatKey := queueKey(queuePrefix, queueName) + ":repeat"
+	var totalCmd *redis.IntCmd
+	var entriesCmd *redis.ZSliceCmd
+	_, err := e.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		totalCmd = pipe.ZCard(ctx, repeatKey)
+		entriesCmd = pipe.ZRangeWithScores(ctx, repeatKey, int64(offset), int64(offset+limit-1))
+		return nil
+	})
+	if err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "get_job_schedulers").Inc()
+		return nil, 0, err
+	}
+
+	entries := entriesCmd.Val()
+	if len(entries) == 0 {
+		return []JobScheduler{}, totalCmd.Val(), nil
+	}
+
+	hashCmds := make([]*redis.MapStringStringCmd, len(entries))
+	pipe := e.client.Pipeline()
+	for i, entry := range entries {
+		hashCmds[i] = pipe.HGetAll(ctx, repeatKey+":"+fmt.Sprint(entry.Member))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "get_job_schedulers").Inc()
+		return nil, 0, err
+	}
+
+	schedulers := make([]JobScheduler, 0, len(entries))
+	for i, entry := range entries {
+		key := fmt.Sprint(entry.Member)
+		scheduler := parseJobScheduler(key, hashCmds[i].Val())
+		scheduler.NextRun = time.UnixMilli(int64(entry.Score))
+		schedulers = append(schedulers, scheduler)
+	}
+	return schedulers, totalCmd.Val(), nil
+}
+
+// RemoveJobScheduler removes a scheduler and its next delayed instance, like
+// BullMQ's Queue.removeJobScheduler().
+func (e *Explorer) RemoveJobScheduler(ctx context.Context, queuePrefix, queueName, key string) error {
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "remove_job_scheduler").Observe(time.Since(start).Seconds())
+	}()
+
+	prefix := queueKey(queuePrefix, queueName)
+	keys := []string{
+		prefix + ":repeat",
+		prefix + ":delayed",
+		prefix + ":meta",
+		prefix + ":events",
+		prefix + ":repeat:" + key,
+	}
+
+	code, err := removeJobSchedulerScript.Run(ctx, e.client, keys, key, prefix+":").Int64()
+	if err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "remove_job_scheduler").Inc()
+		return err
+	}
+	if code != 0 {
+		return fmt.Errorf("%w: %s", ErrJobSchedulerNotFound, key)
+	}
+	return nil
+}
+
+func parseJobScheduler(key string, fields map[string]string) JobScheduler {
+	if len(fields) == 0 {
+		return parseLegacyRepeatKey(key)
+	}
+
+	scheduler := JobScheduler{
+		Key:     key,
+		Name:    fields["name"],
+		Pattern: fields["pattern"],
+		TZ:      fields["tz"],
+		Data:    compactJSON(fields["data"]),
+		Opts:    compactJSON(fields["opts"]),
+	}
+	scheduler.Every, _ = strconv.ParseInt(fields["every"], 10, 64)
+	scheduler.Iterations, _ = strconv.ParseInt(fields["ic"], 10, 64)
+	scheduler.Limit, _ = strconv.ParseInt(fields["limit"], 10, 64)
+	scheduler.StartDate, _ = strconv.ParseInt(fields["startDate"], 10, 64)
+	scheduler.EndDate, _ = strconv.ParseInt(fields["endDate"], 10, 64)
+	return scheduler
+}
+
+// parseLegacyRepeatKey decodes "name:jobId:endDate:tz:pattern|every", the key
+// format repeatable jobs used before they had their own hash.
+func parseLegacyRepeatKey(key string) JobScheduler {
+	scheduler := JobScheduler{Key: key, Name: key, Legacy: true}
+	parts := strings.Split(key, ":")
+	if len(parts) < 5 {
+		return scheduler
+	}
+
+	n := len(parts)
+	scheduler.Name = strings.Join(parts[:n-4], ":")
+	scheduler.EndDate, _ = strconv.ParseInt(parts[n-3], 10, 64)
+	scheduler.TZ = parts[n-2]
+	if every, err := strconv.ParseInt(parts[n-1], 10, 64); err == nil {
+		scheduler.Every = every
+	} else {
+		scheduler.Pattern = parts[n-1]
+	}
+	return scheduler
+}
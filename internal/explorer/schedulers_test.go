@@ -0,0 +1,44 @@
+package explorer
+
+import "testing"
+
+func TestParseJobScheduler(t *testing.T) {
+	scheduler := parseJobScheduler("nightly-report", map[string]string{
+		"name":    "report",
+		"pattern": "0 3 * * *",
+		"tz":      "Europe/Berlin",
+		"ic":      "12",
+		"limit":   "100",
+		"data":    `{"format": "csv"}`,
+	})
+
+	if scheduler.Key != "nightly-report" || scheduler.Name != "report" || scheduler.Legacy {
+		t.Fatalf("unexpected scheduler identity: %+v", scheduler)
+	}
+	if scheduler.Pattern != "0 3 * * *" || scheduler.TZ != "Europe/Berlin" {
+		t.Fatalf("unexpected schedule: %+v", scheduler)
+	}
+	if scheduler.Iterations != 12 || scheduler.Limit != 100 {
+		t.Fatalf("unexpected counters: %+v", scheduler)
+	}
+	if scheduler.Data != `{"format":"csv"}` {
+		t.Fatalf("expected compacted template data, got %q", scheduler.Data)
+	}
+}
+
+func TestParseLegacyRepeatKey(t *testing.T) {
+	every := parseJobScheduler("ping:::UTC:60000", nil)
+	if !every.Legacy || every.Name != "ping" || every.Every != 60000 || every.TZ != "UTC" {
+		t.Fatalf("unexpected legacy every scheduler: %+v", every)
+	}
+
+	cron := parseLegacyRepeatKey("sync:job-1:1800000000000::*/5 * * * *")
+	if cron.Name != "sync" || cron.Pattern != "*/5 * * * *" || cron.EndDate != 1800000000000 {
+		t.Fatalf("unexpected legacy cron scheduler: %+v", cron)
+	}
+
+	opaque := parseLegacyRepeatKey("3f2b9c")
+	if opaque.Name != "3f2b9c" || opaque.Pattern != "" {
+		t.Fatalf("unexpected opaque legacy key: %+v", opaque)
+	}
+}
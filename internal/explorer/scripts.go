@@ -0,0 +1,491 @@
+package explorer
+
+import "github.com/redis/go-redis/v9"
+
+// Lua ports of the BullMQ scripts bullderdash needs for job and queue actions.
+// They follow the BullMQ v5 key layout: jobs are pushed to wait (or paused when
+// the queue meta has the paused flag), prioritized jobs are scored with the
+// :pc counter, and workers are woken up through the :marker zset.
+//
+// Every key a script can name up front is passed in KEYS. Job keys found by
+// reading a list or zset (clean, drain, promote, obliterate) and a parent's
+// keys read from the child's hash can only be built inside the script, as in
+// BullMQ's own scripts, so Redis Cluster needs a hash-tagged prefix; see
+// CheckClusterPrefix.
+//
+// Script return codes mirror BullMQ's:
+//
+//	 0  success
+//	-1  missing job key
+//	-2  job is locked
+//	-3  job is not in the expected state (for removal: the job is active)
+//	-4  job has pending dependencies (children that have not finished)
+
+const luaHelpers = `
+local rcall = redis.call
+
+local function getMaxEvents(metaKey)
+  local maxEvents = rcall("HGET", metaKey, "opts.maxLenEvents")
+  if not maxEvents then
+    return 10000
+  end
+  return maxEvents
+end
+
+local function getTargetQueueList(metaKey, activeKey, waitKey, pausedKey)
+  local meta = rcall("HMGET", metaKey, "paused", "concurrency")
+  if meta[1] then
+    return pausedKey, true
+  end
+  if meta[2] then
+    local activeCount = rcall("LLEN", activeKey)
+    return waitKey, activeCount >= tonumber(meta[2])
+  end
+  return waitKey, false
+end
+
+local function addBaseMarkerIfNeeded(markerKey, isPausedOrMaxed)
+  if not isPausedOrMaxed then
+    rcall("ZADD", markerKey, 0, "0")
+  end
+end
+
+local function getJobPriorityAndPushCmd(jobKey)
+  local fields = rcall("HMGET", jobKey, "priority", "opts")
+  local priority = tonumber(fields[1]) or 0
+  local pushCmd = "LPUSH"
+  if fields[2] then
+    local ok, opts = pcall(cjson.decode, fields[2])
+    if ok and type(opts) == "table" then
+      if priority == 0 and tonumber(opts["priority"]) then
+        priority = tonumber(opts["priority"])
+      end
+      if opts["lifo"] == true then
+        pushCmd = "RPUSH"
+      end
+    end
+  end
+  return priority, pushCmd
+end
+
+local function addJobToTarget(jobKey, jobId, metaKey, activeKey, waitKey, pausedKey, prioritizedKey, priorityCounterKey, markerKey)
+  local target, isPausedOrMaxed = getTargetQueueList(metaKey, activeKey, waitKey, pausedKey)
+  local priority, pushCmd = getJobPriorityAndPushCmd(jobKey)
+  if priority > 0 then
+    local prioCounter = rcall("INCR", priorityCounterKey)
+    rcall("ZADD", prioritizedKey, priority * 0x100000000 + prioCounter % 0x100000000, jobId)
+  else
+    rcall(pushCmd, target, jobId)
+  end
+  addBaseMarkerIfNeeded(markerKey, isPausedOrMaxed)
+end
+-- releaseParentDependency drops a child from its parent's :dependencies set
+-- and moves the parent out of waiting-children once nothing is pending.
+local function releaseParentDependency(jobKey)
+  local parent = rcall("HMGET", jobKey, "parentKey", "parent")
+  local parentKey = parent[1]
+  if parentKey then
+    local parentId, parentQueueKey
+    if parent[2] then
+      local ok, decoded = pcall(cjson.decode, parent[2])
+      if ok and type(decoded) == "table" then
+        parentId = decoded["id"]
+        parentQueueKey = decoded["queueKey"]
+      end
+    end
+    if not parentId or not parentQueueKey then
+      parentQueueKey, parentId = string.match(parentKey, "^(.*):([^:]+)$")
+    end
+
+    rcall("SREM", parentKey .. ":dependencies", jobKey)
+    if parentId and rcall("SCARD", parentKey .. ":dependencies") == 0 and
+        rcall("ZREM", parentQueueKey .. ":waiting-children", parentId) == 1 then
+      local parentMeta = parentQueueKey .. ":meta"
+      addJobToTarget(parentKey, parentId, parentMeta, parentQueueKey .. ":active",
+        parentQueueKey .. ":wait", parentQueueKey .. ":paused", parentQueueKey .. ":prioritized",
+        parentQueueKey .. ":pc", parentQueueKey .. ":marker")
+      rcall("XADD", parentQueueKey .. ":events", "MAXLEN", "~", getMaxEvents(parentMeta), "*",
+        "event", "waiting", "jobId", parentId, "prev", "waiting-children")
+    end
+  end
+end
+
+-- deleteJobKeys deletes a job found by ID inside a script; the suffixes match
+-- jobKeySuffixes.
+local function deleteJobKeys(jobKey)
+  rcall("DEL", jobKey, jobKey .. ":lock", jobKey .. ":dependencies", jobKey .. ":logs",
+    jobKey .. ":processed", jobKey .. ":failed", jobKey .. ":unsuccessful")
+end
+`
+
+// jobKeySuffixes are a job's companion keys, lock and dependencies first.
+// Scripts that know the job up front take them as KEYS.
+var jobKeySuffixes = []string{"lock", "dependencies", "logs", "processed", "failed", "unsuccessful"}
+
+// retryJobScript mirrors BullMQ's reprocessJob for failed jobs.
+//
+// KEYS: job, failed, wait, paused, meta, active, prioritized, pc, marker, events
+// ARGV: jobId
+var retryJobScript = redis.NewScript(luaHelpers + `
+local jobKey = KEYS[1]
+local jobId = ARGV[1]
+
+if rcall("EXISTS", jobKey) ~= 1 then
+  return -1
+end
+if rcall("ZREM", KEYS[2], jobId) ~= 1 then
+  return -3
+end
+
+rcall("HDEL", jobKey, "finishedOn", "processedOn", "failedReason")
+for _, field in ipairs({"attemptsMade", "atm"}) do
+  if rcall("HEXISTS", jobKey, field) == 1 then
+    rcall("HSET", jobKey, field, 0)
+  end
+end
+
+addJobToTarget(jobKey, jobId, KEYS[5], KEYS[6], KEYS[3], KEYS[4], KEYS[7], KEYS[8], KEYS[9])
+
+rcall("XADD", KEYS[10], "MAXLEN", "~", getMaxEvents(KEYS[5]), "*", "event", "waiting", "jobId", jobId, "prev", "failed")
+return 0
+`)
+
+// removeJobScript mirrors BullMQ's removeJob for a single job without
+// pending children. It refuses locked or active jobs and parents whose
+// :dependencies set is not empty, since deleting the parent would leave those
+// children pointing at a missing parentKey. Otherwise it removes the ID from
+// every state list/zset, deletes the job hash and its companion keys, and
+// releases the parent when this was its last pending dependency.
+//
+// KEYS: job, wait, active, paused, delayed, prioritized, waiting-children,
+// completed, failed, stalled, meta, events, then the job's own keys (see
+// jobKeySuffixes)
+// ARGV: jobId
+var removeJobScript = redis.NewScript(luaHelpers + `
+local jobKey = KEYS[1]
+local jobId = ARGV[1]
+
+if rcall("EXISTS", jobKey) ~= 1 then
+  return -1
+end
+if rcall("EXISTS", KEYS[13]) == 1 then
+  return -2
+end
+if rcall("LPOS", KEYS[3], jobId) then
+  return -3
+end
+if rcall("SCARD", KEYS[14]) > 0 then
+  return -4
+end
+
+local prev = "unknown"
+local states = {
+  {KEYS[2], "list", "waiting"},
+  {KEYS[4], "list", "paused"},
+  {KEYS[5], "zset", "delayed"},
+  {KEYS[6], "zset", "prioritized"},
+  {KEYS[7], "zset", "waiting-children"},
+  {KEYS[8], "zset", "completed"},
+  {KEYS[9], "zset", "failed"},
+}
+for _, state in ipairs(states) do
+  local removed
+  if state[2] == "list" then
+    removed = rcall("LREM", state[1], 0, jobId)
+  else
+    removed = rcall("ZREM", state[1], jobId)
+  end
+  if removed > 0 and prev == "unknown" then
+    prev = state[3]
+  end
+end
+
+local stalledType = rcall("TYPE", KEYS[10])["ok"]
+if stalledType == "set" then
+  rcall("SREM", KEYS[10], jobId)
+elseif stalledType == "zset" then
+  rcall("ZREM", KEYS[10], jobId)
+end
+
+releaseParentDependency(jobKey)
+rcall("DEL", jobKey, unpack(KEYS, 13))
+
+rcall("XADD", KEYS[12], "MAXLEN", "~", getMaxEvents(KEYS[11]), "*", "event", "removed", "jobId", jobId, "prev", prev)
+return 0
+`)
+
+// promoteJobScript mirrors BullMQ's promote: a delayed job is moved to wait
+// (or paused/prioritized) immediately.
+//
+// KEYS: job, delayed, wait, paused, meta, active, prioritized, pc, marker, events
+// ARGV: jobId
+var promoteJobScript = redis.NewScript(luaHelpers + `
+local jobKey = KEYS[1]
+local jobId = ARGV[1]
+
+if rcall("EXISTS", jobKey) ~= 1 then
+  return -1
+end
+if rcall("ZREM", KEYS[2], jobId) ~= 1 then
+  return -3
+end
+
+addJobToTarget(jobKey, jobId, KEYS[5], KEYS[6], KEYS[3], KEYS[4], KEYS[7], KEYS[8], KEYS[9])
+rcall("HSET", jobKey, "delay", 0)
+
+rcall("XADD", KEYS[10], "MAXLEN", "~", getMaxEvents(KEYS[5]), "*", "event", "waiting", "jobId", jobId, "prev", "delayed")
+return 0
+`)
+
+// promoteDelayedScript mirrors BullMQ's promoteJobs: up to ARGV[2] jobs are
+// promoted from the head of the delayed zset. Returns {promoted, scanned} so
+// callers can tell an exhausted zset from a batch of dangling IDs.
+//
+// KEYS: delayed, wait, paused, meta, active, prioritized, pc, marker, events
+// ARGV: queue key prefix (e.g. "bull:orders:"), batch size
+var promoteDelayedScript = redis.NewScript(luaHelpers + `
+local jobIds = rcall("ZRANGE", KEYS[1], 0, tonumber(ARGV[2]) - 1)
+local maxEvents = getMaxEvents(KEYS[4])
+local promoted = 0
+
+for _, jobId in ipairs(jobIds) do
+  rcall("ZREM", KEYS[1], jobId)
+  local jobKey = ARGV[1] .. jobId
+  if rcall("EXISTS", jobKey) == 1 then
+    addJobToTarget(jobKey, jobId, KEYS[4], KEYS[5], KEYS[2], KEYS[3], KEYS[6], KEYS[7], KEYS[8])
+    rcall("HSET", jobKey, "delay", 0)
+    rcall("XADD", KEYS[9], "MAXLEN", "~", maxEvents, "*", "event", "waiting", "jobId", jobId, "prev", "delayed")
+    promoted = promoted + 1
+  end
+end
+
+return {promoted, #jobIds}
+`)
+
+// pauseScript mirrors BullMQ's pause: job IDs move between wait and paused,
+// the meta paused flag is set or cleared, and a paused/resumed event is
+// published. If the destination list already has IDs (e.g. a worker raced the
+// pause) the source is appended behind them rather than overwritten.
+//
+// KEYS: source list, destination list, meta, prioritized, delayed, events, marker
+// ARGV: "paused" | "resumed"
+var pauseScript = redis.NewScript(luaHelpers + `
+if rcall("EXISTS", KEYS[1]) == 1 then
+  if rcall("EXISTS", KEYS[2]) == 1 then
+    while rcall("RPOPLPUSH", KEYS[1], KEYS[2]) do end
+  else
+    rcall("RENAME", KEYS[1], KEYS[2])
+  end
+end
+
+if ARGV[1] == "paused" then
+  rcall("HSET", KEYS[3], "paused", 1)
+  rcall("DEL", KEYS[7])
+else
+  rcall("HDEL", KEYS[3], "paused")
+  if rcall("EXISTS", KEYS[2]) == 1 or rcall("ZCARD", KEYS[4]) > 0 then
+    rcall("ZADD", KEYS[7], 0, "0")
+  else
+    local nextDelayed = rcall("ZRANGE", KEYS[5], 0, 0, "WITHSCORES")
+    if nextDelayed[2] then
+      rcall("ZADD", KEYS[7], math.floor(tonumber(nextDelayed[2]) / 0x1000), "1")
+    end
+  end
+end
+
+rcall("XADD", KEYS[6], "MAXLEN", "~", getMaxEvents(KEYS[3]), "*", "event", ARGV[1])
+return 0
+`)
+
+// cleanJobsScript mirrors BullMQ's cleanJobsInSet for the completed and
+// failed zsets, whose scores are finishedOn. Up to ARGV[3] jobs finished at or
+// before ARGV[2] are removed. Returns {scanned, removed job IDs}.
+//
+// KEYS: set, meta, events
+// ARGV: queue key prefix (e.g. "bull:orders:"), max finishedOn (ms), batch size
+var cleanJobsScript = redis.NewScript(luaHelpers + `
+local jobIds = rcall("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[2], "LIMIT", 0, tonumber(ARGV[3]))
+local removed = {}
+
+for _, jobId in ipairs(jobIds) do
+  local jobKey = ARGV[1] .. jobId
+  rcall("ZREM", KEYS[1], jobId)
+  if rcall("EXISTS", jobKey) == 1 then
+    releaseParentDependency(jobKey)
+    deleteJobKeys(jobKey)
+    removed[#removed + 1] = jobId
+  end
+end
+
+if #removed > 0 then
+  rcall("XADD", KEYS[3], "MAXLEN", "~", getMaxEvents(KEYS[2]), "*", "event", "cleaned", "count", #removed)
+end
+return {#jobIds, removed}
+`)
+
+// drainScript mirrors BullMQ's drain(delayed=true): jobs in wait, paused,
+// delayed and prioritized are deleted, up to ARGV[2] per call. Returns
+// {scanned, removed job IDs}; scanned below the batch size means the queue is
+// drained.
+//
+// KEYS: wait, paused, delayed, prioritized
+// ARGV: queue key prefix (e.g. "bull:orders:"), batch size
+var drainScript = redis.NewScript(luaHelpers + `
+local remaining = tonumber(ARGV[2])
+local removed = {}
+local scanned = 0
+
+local function removeJobs(jobIds)
+  for _, jobId in ipairs(jobIds) do
+    local jobKey = ARGV[1] .. jobId
+    if rcall("EXISTS", jobKey) == 1 then
+      releaseParentDependency(jobKey)
+      deleteJobKeys(jobKey)
+      removed[#removed + 1] = jobId
+    end
+  end
+  scanned = scanned + #jobIds
+  remaining = remaining - #jobIds
+end
+
+for i = 1, 2 do
+  if remaining > 0 then
+    local jobIds = rcall("LRANGE", KEYS[i], 0, remaining - 1)
+    if #jobIds > 0 then
+      rcall("LTRIM", KEYS[i], #jobIds, -1)
+      removeJobs(jobIds)
+    end
+  end
+end
+for i = 3, 4 do
+  if remaining > 0 then
+    local jobIds = rcall("ZRANGE", KEYS[i], 0, remaining - 1)
+    if #jobIds > 0 then
+      rcall("ZREMRANGEBYRANK", KEYS[i], 0, #jobIds - 1)
+      removeJobs(jobIds)
+    end
+  end
+end
+
+return {scanned, removed}
+`)
+
+// obliterateScript mirrors BullMQ's obliterate: up to ARGV[2] jobs are deleted
+// per call across every state, and once all states are empty the queue's own
+// keys are deleted too. The queue must be paused, and active jobs are only
+// removed when ARGV[3] is non-empty (force).
+//
+// Returns 0 when done, 1 when more batches are needed, -1 when the queue is
+// not paused and -2 when it has active jobs and force was not given.
+//
+// KEYS: meta, active, delayed, completed, waiting-children, failed,
+// prioritized, paused, wait, then the queue keys deleted at the end (see
+// obliterateQueueKeys)
+// ARGV: queue key prefix (e.g. "bull:orders:"), batch size, force
+var obliterateScript = redis.NewScript(luaHelpers + `
+local baseKey = ARGV[1]
+local maxCount = tonumber(ARGV[2])
+
+if rcall("HEXISTS", KEYS[1], "paused") ~= 1 then
+  return -1
+end
+
+local activeKey = KEYS[2]
+local activeJobs = rcall("LRANGE", activeKey, 0, maxCount - 1)
+if #activeJobs > 0 and ARGV[3] == "" then
+  return -2
+end
+
+local function removeJobs(jobIds)
+  for _, jobId in ipairs(jobIds) do
+    local jobKey = baseKey .. jobId
+    releaseParentDependency(jobKey)
+    deleteJobKeys(jobKey)
+  end
+  maxCount = maxCount - #jobIds
+end
+
+removeJobs(activeJobs)
+rcall("LTRIM", activeKey, #activeJobs, -1)
+if maxCount <= 0 then
+  return 1
+end
+
+for i = 3, 7 do
+  local jobIds = rcall("ZRANGE", KEYS[i], 0, maxCount - 1)
+  if #jobIds > 0 then
+    rcall("ZREMRANGEBYRANK", KEYS[i], 0, #jobIds - 1)
+    removeJobs(jobIds)
+    if maxCount <= 0 then
+      return 1
+    end
+  end
+end
+
+for i = 8, 9 do
+  local jobIds = rcall("LRANGE", KEYS[i], 0, maxCount - 1)
+  if #jobIds > 0 then
+    rcall("LTRIM", KEYS[i], #jobIds, -1)
+    removeJobs(jobIds)
+    if maxCount <= 0 then
+      return 1
+    end
+  end
+end
+
+rcall("DEL", unpack(KEYS))
+return 0
+`)
+
+// obliterateQueueKeys are the queue's own keys obliterateScript deletes once
+// every job is gone, after the state keys it empties first.
+var obliterateQueueKeys = []string{
+	"events", "delay", "stalled-check", "stalled", "id", "pc", "marker", "limiter", "repeat",
+	"metrics:completed", "metrics:completed:data", "metrics:failed", "metrics:failed:data",
+}
+
+// removeJobSchedulerScript mirrors BullMQ's removeJobScheduler: the scheduler
+// leaves the repeat zset, its hash is deleted, and the next delayed instance
+// ("repeat:<key>:<nextMillis>") is removed if it has not been promoted yet.
+// Returns 0 when removed and 1 when the scheduler does not exist.
+//
+// KEYS: repeat, delayed, meta, events, scheduler hash
+// ARGV: scheduler key, queue key prefix (e.g. "bull:orders:")
+var removeJobSchedulerScript = redis.NewScript(luaHelpers + `
+local schedulerKey = ARGV[1]
+local millis = rcall("ZSCORE", KEYS[1], schedulerKey)
+
+if millis then
+  local delayedJobId = "repeat:" .. schedulerKey .. ":" .. string.format("%.0f", tonumber(millis))
+  if rcall("ZREM", KEYS[2], delayedJobId) == 1 then
+    local jobKey = ARGV[2] .. delayedJobId
+    releaseParentDependency(jobKey)
+    deleteJobKeys(jobKey)
+    rcall("XADD", KEYS[4], "MAXLEN", "~", getMaxEvents(KEYS[3]), "*", "event", "removed", "jobId", delayedJobId, "prev", "delayed")
+  end
+end
+
+if rcall("ZREM", KEYS[1], schedulerKey) == 1 then
+  rcall("DEL", KEYS[5])
+  return 0
+end
+return 1
+`)
+
+// countUnlockedActiveScript counts jobs in the active list whose lock key is
+// gone. It only reads, but runs as a script so a job finishing between the
+// LRANGE and the EXISTS checks cannot be miscounted. BullMQ's moveToActive and
+// moveToFinished add and remove the lock in the same script as the list entry.
+//
+// KEYS: active
+// ARGV: queue key prefix (e.g. "bull:orders:"), max jobs to check
+var countUnlockedActiveScript = redis.NewScript(`
+local jobIds = redis.call("LRANGE", KEYS[1], 0, tonumber(ARGV[2]) - 1)
+local unlocked = 0
+for _, jobId in ipairs(jobIds) do
+  if redis.call("EXISTS", ARGV[1] .. jobId .. ":lock") == 0 then
+    unlocked = unlocked + 1
+  end
+end
+return unlocked
+`)
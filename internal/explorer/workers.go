@@ -0,0 +1,161 @@
+package explorer
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// Worker is a connected BullMQ worker, found by the client name BullMQ gives
+// its connections: "<prefix>:<base64(queue)>", or
+// "<prefix>:<base64(queue)>:w:<name>" when the worker has a name.
+type Worker struct {
+	Prefix     string `json:"prefix"`
+	Queue      string `json:"queue"`
+	Name       string `json:"name,omitempty"`
+	ClientName string `json:"clientName"`
+	ClientID   string `json:"clientId"`
+	Addr       string `json:"addr"`
+	// AgeSeconds and IdleSeconds are the connection's age and the time since
+	// its last command, as reported by CLIENT LIST.
+	AgeSeconds  int64 `json:"ageSeconds"`
+	IdleSeconds int64 `json:"idleSeconds"`
+}
+
+// GetWorkers returns the workers connected to a queue, like BullMQ's
+// Queue.getWorkers().
+func (e *Explorer) GetWorkers(ctx context.Context, prefix, queueName string) ([]Worker, error) {
+	workers, err := e.listWorkers(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if queueWorkers := workers[queueName]; queueWorkers != nil {
+		return queueWorkers, nil
+	}
+	return []Worker{}, nil
+}
+
+// listWorkers runs CLIENT LIST (on every master of a cluster) and groups the BullMQ workers it finds by
+// queue.
+func (e *Explorer) listWorkers(ctx context.Context, prefix string) (map[string][]Worker, error) {
+	start := time.Now()
+	defer func() {
+		metrics.RedisOperationDuration.WithLabelValues(e.env, "client_list").Observe(time.Since(start).Seconds())
+	}()
+
+	cluster, ok := e.client.(*redis.ClusterClient)
+	if !ok {
+		list, err := e.client.ClientList(ctx).Result()
+		if err != nil {
+			metrics.RedisOperationErrors.WithLabelValues(e.env, "client_list").Inc()
+			return nil, err
+		}
+		return parseClientList(list, prefix), nil
+	}
+
+	// A clustered worker holds a connection to each master it has touched,
+	// so like BullMQ's getWorkers() each queue reports the master that sees
+	// the most of its workers rather than the sum.
+	var mu sync.Mutex
+	workers := make(map[string][]Worker)
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+		list, err := master.ClientList(ctx).Result()
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for queueName, queueWorkers := range parseClientList(list, prefix) {
+			if len(queueWorkers) > len(workers[queueName]) {
+				workers[queueName] = queueWorkers
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		metrics.RedisOperationErrors.WithLabelValues(e.env, "client_list").Inc()
+		return nil, err
+	}
+	return workers, nil
+}
+
+// countWorkers fills in Workers for each stat. CLIENT LIST is often disabled
+// on managed Redis, so failures leave WorkersKnown unset instead of failing
+// the stats.
+func (e *Explorer) countWorkers(ctx context.Context, prefix string, stats []QueueStats) {
+	workers, err := e.listWorkers(ctx, prefix)
+	if err != nil {
+		return
+	}
+	for i := range stats {
+		stats[i].Workers = int64(len(workers[stats[i].Name]))
+		stats[i].WorkersKnown = true
+	}
+}
+
+func parseClientList(list, prefix string) map[string][]Worker {
+	workers := make(map[string][]Worker)
+	for _, line := range strings.Split(list, "\n") {
+		fields := make(map[string]string)
+		for _, pair := range strings.Fields(line) {
+			if key, value, ok := strings.Cut(pair, "="); ok {
+				fields[key] = value
+			}
+		}
+
+		queueName, workerName, ok := parseWorkerClientName(fields["name"], prefix)
+		if !ok {
+			continue
+		}
+		worker := Worker{
+			Prefix:     prefix,
+			Queue:      queueName,
+			Name:       workerName,
+			ClientName: fields["name"],
+			ClientID:   fields["id"],
+			Addr:       fields["addr"],
+		}
+		worker.AgeSeconds, _ = strconv.ParseInt(fields["age"], 10, 64)
+		worker.IdleSeconds, _ = strconv.ParseInt(fields["idle"], 10, 64)
+		workers[queueName] = append(workers[queueName], worker)
+	}
+
+	for _, queueWorkers := range workers {
+		sort.Slice(queueWorkers, func(i, j int) bool {
+			if queueWorkers[i].Name != queueWorkers[j].Name {
+				return queueWorkers[i].Name < queueWorkers[j].Name
+			}
+			return queueWorkers[i].Addr < queueWorkers[j].Addr
+		})
+	}
+	return workers
+}
+
+// parseWorkerClientName decodes a BullMQ worker client name. Base64 never
+// contains ":", so the first ":" after the prefix starts the ":w:<name>" part.
+func parseWorkerClientName(clientName, prefix string) (queueName, workerName string, ok bool) {
+	rest, ok := strings.CutPrefix(clientName, prefix+":")
+	if !ok || rest == "" {
+		return "", "", false
+	}
+	encoded, suffix, hasSuffix := strings.Cut(rest, ":")
+	if hasSuffix {
+		workerName, ok = strings.CutPrefix(suffix, "w:")
+		if !ok {
+			return "", "", false
+		}
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(decoded) == 0 {
+		return "", "", false
+	}
+	return string(decoded), workerName, true
+}
@@ -0,0 +1,59 @@
+package explorer
+
+import "testing"
+
+func TestParseClientList(t *testing.T) {
+	// "ZW1haWw=" is base64("email"), "cmVwb3J0cw==" is base64("reports").
+	list := "id=7 addr=10.0.0.5:51234 laddr=10.0.0.2:6379 fd=8 name=bull:ZW1haWw= age=120 idle=3 flags=N db=0 cmd=bzpopmin\n" +
+		"id=8 addr=10.0.0.6:40100 laddr=10.0.0.2:6379 fd=9 name=bull:ZW1haWw=:w:mailer-2 age=30 idle=0 flags=N db=0 cmd=evalsha\n" +
+		"id=9 addr=10.0.0.6:40101 laddr=10.0.0.2:6379 fd=10 name=bull:ZW1haWw=:qe age=30 idle=0 flags=N db=0 cmd=xread\n" +
+		"id=10 addr=10.0.0.7:40000 laddr=10.0.0.2:6379 fd=11 name=bull:cmVwb3J0cw== age=5 idle=5 flags=N db=0 cmd=bzpopmin\n" +
+		"id=11 addr=10.0.0.8:40000 laddr=10.0.0.2:6379 fd=12 name= age=1 idle=0 flags=N db=0 cmd=client|list\n" +
+		"id=12 addr=10.0.0.9:40000 laddr=10.0.0.2:6379 fd=13 name=other:ZW1haWw= age=1 idle=0 flags=N db=0 cmd=get\n"
+
+	workers := parseClientList(list, "bull")
+	if len(workers) != 2 {
+		t.Fatalf("expected workers for 2 queues, got %+v", workers)
+	}
+
+	email := workers["email"]
+	if len(email) != 2 {
+		t.Fatalf("expected 2 email workers (queue events excluded), got %+v", email)
+	}
+	if email[0].Name != "" || email[0].Addr != "10.0.0.5:51234" || email[0].AgeSeconds != 120 || email[0].IdleSeconds != 3 {
+		t.Fatalf("unexpected unnamed worker: %+v", email[0])
+	}
+	if email[1].Name != "mailer-2" || email[1].ClientID != "8" {
+		t.Fatalf("unexpected named worker: %+v", email[1])
+	}
+	if len(workers["reports"]) != 1 {
+		t.Fatalf("expected 1 reports worker, got %+v", workers["reports"])
+	}
+}
+
+func TestParseWorkerClientName(t *testing.T) {
+	for _, name := range []string{"", "bull:", "bull:not base64!", "bull:ZW1haWw=:qe", "bullmq:ZW1haWw="} {
+		if _, _, ok := parseWorkerClientName(name, "bull"); ok {
+			t.Errorf("expected %q not to be a worker", name)
+		}
+	}
+
+	queueName, workerName, ok := parseWorkerClientName("{bull}:ZW1haWw=:w:a:b", "{bull}")
+	if !ok || queueName != "email" || workerName != "a:b" {
+		t.Fatalf("unexpected parse: %q %q %v", queueName, workerName, ok)
+	}
+}
+
+func TestQueueStatsNeedsWorkers(t *testing.T) {
+	stat := QueueStats{Wait: 5, WorkersKnown: true}
+	if !stat.NeedsWorkers() {
+		t.Fatal("expected waiting jobs without workers to need workers")
+	}
+	stat.IsPaused = true
+	if stat.NeedsWorkers() {
+		t.Fatal("paused queues do not need workers")
+	}
+	if (QueueStats{Wait: 5}).NeedsWorkers() {
+		t.Fatal("unknown worker counts should not be flagged")
+	}
+}
@@ -12,7 +12,7 @@ var (
 			Name: "bullmq_queue_waiting",
 			Help: "Number of jobs waiting in queue",
 		},
-		[]string{"queue"},
+		[]string{"env", "prefix", "queue"},
 	)
 
 	QueueActive = promauto.NewGaugeVec(
@@ -20,7 +20,79 @@ var (
 			Name: "bullmq_queue_active",
 			Help: "Number of jobs currently being processed",
 		},
-		[]string{"queue"},
+		[]string{"env", "prefix", "queue"},
+	)
+
+	QueueActiveUnlocked = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bullmq_queue_active_unlocked",
+			Help: "Number of active jobs whose lock has expired or is missing",
+		},
+		[]string{"env", "prefix", "queue"},
+	)
+
+	QueueWorkers = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bullmq_queue_workers",
+			Help: "Number of BullMQ workers connected to the queue",
+		},
+		[]string{"env", "prefix", "queue"},
+	)
+
+	QueueGlobalConcurrency = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bullmq_queue_global_concurrency",
+			Help: "Configured global concurrency for the queue (0 when unlimited)",
+		},
+		[]string{"env", "prefix", "queue"},
+	)
+
+	QueueRateLimitMax = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bullmq_queue_rate_limit_max",
+			Help: "Configured global rate limit: max jobs per window (0 when unset)",
+		},
+		[]string{"env", "prefix", "queue"},
+	)
+
+	QueueRateLimitDuration = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bullmq_queue_rate_limit_duration_seconds",
+			Help: "Configured global rate limit window length",
+		},
+		[]string{"env", "prefix", "queue"},
+	)
+
+	QueueRateLimiterCount = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bullmq_queue_rate_limiter_count",
+			Help: "Number of jobs counted in the current rate limit window",
+		},
+		[]string{"env", "prefix", "queue"},
+	)
+
+	QueueRateLimitReset = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bullmq_queue_rate_limit_reset_seconds",
+			Help: "Time until the current rate limit window resets",
+		},
+		[]string{"env", "prefix", "queue"},
+	)
+
+	QueueRateLimited = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bullmq_queue_rate_limited",
+			Help: "Whether workers are currently held off by the rate limiter (1 or 0)",
+		},
+		[]string{"env", "prefix", "queue"},
+	)
+
+	QueuePriorityCounter = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bullmq_queue_priority_counter",
+			Help: "Value of the queue's priority counter (:pc)",
+		},
+		[]string{"env", "prefix", "queue"},
 	)
 
 	QueuePaused = promauto.NewGaugeVec(
@@ -28,7 +100,7 @@ var (
 			Name: "bullmq_queue_paused",
 			Help: "Number of jobs paused in queue",
 		},
-		[]string{"queue"},
+		[]string{"env", "prefix", "queue"},
 	)
 
 	QueuePrioritized = promauto.NewGaugeVec(
@@ -36,7 +108,7 @@ var (
 			Name: "bullmq_queue_prioritized",
 			Help: "Number of prioritized jobs in queue",
 		},
-		[]string{"queue"},
+		[]string{"env", "prefix", "queue"},
 	)
 
 	QueueWaitingChildren = promauto.NewGaugeVec(
@@ -44,7 +116,7 @@ var (
 			Name: "bullmq_queue_waiting_children",
 			Help: "Number of jobs waiting on children in queue",
 		},
-		[]string{"queue"},
+		[]string{"env", "prefix", "queue"},
 	)
 
 	QueueFailed = promauto.NewGaugeVec(
@@ -52,7 +124,7 @@ var (
 			Name: "bullmq_queue_failed",
 			Help: "Number of failed jobs in queue",
 		},
-		[]string{"queue"},
+		[]string{"env", "prefix", "queue"},
 	)
 
 	QueueCompleted = promauto.NewGaugeVec(
@@ -60,7 +132,7 @@ var (
 			Name: "bullmq_queue_completed",
 			Help: "Number of completed jobs in queue",
 		},
-		[]string{"queue"},
+		[]string{"env", "prefix", "queue"},
 	)
 
 	QueueDelayed = promauto.NewGaugeVec(
@@ -68,7 +140,7 @@ var (
 			Name: "bullmq_queue_delayed",
 			Help: "Number of delayed jobs in queue",
 		},
-		[]string{"queue"},
+		[]string{"env", "prefix", "queue"},
 	)
 
 	QueueStalled = promauto.NewGaugeVec(
@@ -76,7 +148,7 @@ var (
 			Name: "bullmq_queue_stalled",
 			Help: "Number of stalled jobs in queue",
 		},
-		[]string{"queue"},
+		[]string{"env", "prefix", "queue"},
 	)
 
 	QueueOrphaned = promauto.NewGaugeVec(
@@ -84,7 +156,7 @@ var (
 			Name: "bullmq_queue_orphaned",
 			Help: "Number of orphaned job hashes not in any state list",
 		},
-		[]string{"queue"},
+		[]string{"env", "prefix", "queue"},
 	)
 
 	// Workload metrics are derived from BullMQ event streams in a background
@@ -94,7 +166,7 @@ var (
 			Name: "bullmq_jobs_finished_total",
 			Help: "Total number of BullMQ jobs observed finishing",
 		},
-		[]string{"queue", "name", "result"},
+		[]string{"env", "prefix", "queue", "name", "result"},
 	)
 
 	WorkloadJobCompletionDuration = promauto.NewHistogramVec(
@@ -109,7 +181,7 @@ var (
 				30, 60, 120, 300,
 			},
 		},
-		[]string{"queue", "name", "result"},
+		[]string{"env", "prefix", "queue", "name", "result"},
 	)
 
 	WorkloadEventLag = promauto.NewGaugeVec(
@@ -117,7 +189,7 @@ var (
 			Name: "bullmq_workload_event_lag_seconds",
 			Help: "Approximate age of the latest BullMQ event stream entry observed by the workload metrics collector",
 		},
-		[]string{"queue"},
+		[]string{"env", "prefix", "queue"},
 	)
 
 	WorkloadEventsRead = promauto.NewCounterVec(
@@ -125,7 +197,7 @@ var (
 			Name: "bullmq_workload_events_read_total",
 			Help: "Total number of BullMQ event stream entries read by the workload metrics collector",
 		},
-		[]string{"queue", "event"},
+		[]string{"env", "prefix", "queue", "event"},
 	)
 
 	WorkloadEventsDropped = promauto.NewCounterVec(
@@ -133,7 +205,7 @@ var (
 			Name: "bullmq_workload_events_dropped_total",
 			Help: "Total number of BullMQ terminal events dropped by the workload metrics collector",
 		},
-		[]string{"queue", "reason"},
+		[]string{"env", "prefix", "queue", "reason"},
 	)
 
 	WorkloadJobLookupErrors = promauto.NewCounterVec(
@@ -141,7 +213,7 @@ var (
 			Name: "bullmq_workload_job_lookup_errors_total",
 			Help: "Total number of job hash lookup errors from the workload metrics collector",
 		},
-		[]string{"queue", "reason"},
+		[]string{"env", "prefix", "queue", "reason"},
 	)
 
 	// HTTP metrics
@@ -161,7 +233,7 @@ var (
 			Help:    "Redis operation latency",
 			Buckets: prometheus.DefBuckets,
 		},
-		[]string{"operation"},
+		[]string{"env", "operation"},
 	)
 
 	RedisOperationErrors = promauto.NewCounterVec(
@@ -169,6 +241,6 @@ var (
 			Name: "redis_operation_errors_total",
 			Help: "Total number of Redis operation errors",
 		},
-		[]string{"operation"},
+		[]string{"env", "operation"},
 	)
 )
@@ -0,0 +1,172 @@
+// Package redisconn builds the Redis/Valkey client shared by the server and
+// cmd/redis-cli from direct, Sentinel, cluster and TLS settings.
+package redisconn
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Options are the connection settings. When URL is set it replaces Addr,
+// Username, Password and DB, and a rediss:// URL turns TLS on.
+type Options struct {
+	URL      string
+	Addr     string
+	Username string
+	Password string
+	DB       int
+
+	SentinelMaster   string
+	SentinelAddrs    []string
+	SentinelUsername string
+	SentinelPassword string
+
+	ClusterAddrs []string
+
+	TLS TLSOptions
+
+	// PoolSize caps the client's connections (per node for a cluster).
+	// Zero keeps go-redis's default of ten per CPU.
+	PoolSize int
+}
+
+// TLSOptions configure TLS, and mutual TLS when a client certificate is given.
+type TLSOptions struct {
+	Enabled  bool
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name the server certificate is checked
+	// against, for connecting through an IP or a tunnel.
+	ServerName string
+	// InsecureSkipVerify disables certificate verification. Development only.
+	InsecureSkipVerify bool
+}
+
+// Mode reports which kind of client NewClient builds: "cluster",
+// "sentinel" or "direct".
+func (o Options) Mode() string {
+	switch {
+	case len(o.ClusterAddrs) > 0:
+		return "cluster"
+	case o.SentinelMaster != "" && len(o.SentinelAddrs) > 0:
+		return "sentinel"
+	default:
+		return "direct"
+	}
+}
+
+// NewClient returns a cluster client when ClusterAddrs is set, a Sentinel
+// failover client when both SentinelMaster and SentinelAddrs are set, and a
+// direct client otherwise.
+func NewClient(opts Options) (redis.UniversalClient, error) {
+	opts, err := opts.ApplyURL()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := opts.TLS.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	switch opts.Mode() {
+	case "cluster":
+		// A cluster has no numbered databases, so DB does not apply.
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:     opts.ClusterAddrs,
+			Username:  opts.Username,
+			Password:  opts.Password,
+			TLSConfig: tlsConfig,
+			PoolSize:  opts.PoolSize,
+		}), nil
+	case "sentinel":
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       opts.SentinelMaster,
+			SentinelAddrs:    opts.SentinelAddrs,
+			Username:         opts.Username,
+			Password:         opts.Password,
+			SentinelUsername: opts.SentinelUsername,
+			SentinelPassword: opts.SentinelPassword,
+			DB:               opts.DB,
+			TLSConfig:        tlsConfig,
+			PoolSize:         opts.PoolSize,
+		}), nil
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:      opts.Addr,
+			Username:  opts.Username,
+			Password:  opts.Password,
+			DB:        opts.DB,
+			TLSConfig: tlsConfig,
+			PoolSize:  opts.PoolSize,
+		}), nil
+	}
+}
+
+// ApplyURL folds a redis:// or rediss:// URL into the other options.
+// NewClient does this itself; it is exported for logging the resolved
+// settings.
+func (o Options) ApplyURL() (Options, error) {
+	if o.URL == "" {
+		return o, nil
+	}
+	parsed, err := redis.ParseURL(o.URL)
+	if err != nil {
+		return o, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+
+	o.Addr = parsed.Addr
+	o.Username = parsed.Username
+	o.Password = parsed.Password
+	o.DB = parsed.DB
+	if parsed.TLSConfig != nil {
+		o.TLS.Enabled = true
+		if o.TLS.ServerName == "" {
+			o.TLS.ServerName = parsed.TLSConfig.ServerName
+		}
+		o.TLS.InsecureSkipVerify = o.TLS.InsecureSkipVerify || parsed.TLSConfig.InsecureSkipVerify
+	}
+	return o, nil
+}
+
+// Config returns the tls.Config for these options, or nil when TLS is off.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	if !o.Enabled {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read TLS CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("TLS CA file %s contains no PEM certificates", o.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		if o.CertFile == "" || o.KeyFile == "" {
+			return nil, errors.New("TLS client certificate and key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
@@ -0,0 +1,67 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+// errForbidden marks requests whose identity lacks the role they need; it
+// maps to 403.
+var errForbidden = errors.New("forbidden")
+
+// queueAccess returns an errForbidden error unless the request holds at
+// least role on queueName.
+func queueAccess(r *http.Request, queueName string, role auth.Role) error {
+	if auth.Allowed(r.Context(), queueName, role) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s role required on queue %q", errForbidden, role, queueName)
+}
+
+// requireQueueRole answers 403 for pages and fragments the request may not
+// see.
+func requireQueueRole(w http.ResponseWriter, r *http.Request, queueName string, role auth.Role) bool {
+	if err := queueAccess(r, queueName, role); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// visibleQueueStats drops the queues the request may not view.
+func visibleQueueStats(r *http.Request, stats []explorer.QueueStats) []explorer.QueueStats {
+	visible := make([]explorer.QueueStats, 0, len(stats))
+	for _, stat := range stats {
+		if auth.Allowed(r.Context(), stat.Name, auth.RoleViewer) {
+			visible = append(visible, stat)
+		}
+	}
+	return visible
+}
+
+// visibleQueueRefs drops the queues the request may not view.
+func visibleQueueRefs(r *http.Request, queues []explorer.QueueRef) []explorer.QueueRef {
+	visible := make([]explorer.QueueRef, 0, len(queues))
+	for _, queue := range queues {
+		if auth.Allowed(r.Context(), queue.Name, auth.RoleViewer) {
+			visible = append(visible, queue)
+		}
+	}
+	return visible
+}
+
+// canFunc is the "can" template func: {{if can "operator" .Queue}} shows
+// an action only to those allowed to use it.
+func canFunc(r *http.Request) func(role, queueName string) bool {
+	return func(role, queueName string) bool {
+		parsed, err := auth.ParseRole(role)
+		if err != nil {
+			return false
+		}
+		return auth.Allowed(r.Context(), queueName, parsed)
+	}
+}
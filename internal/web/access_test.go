@@ -0,0 +1,127 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+// asUser returns r as name, under grants.
+func asUser(t *testing.T, r *http.Request, name string, grants ...string) *http.Request {
+	t.Helper()
+	policy, err := auth.ParsePolicy(grants, "")
+	if err != nil {
+		t.Fatalf("ParsePolicy returned error: %v", err)
+	}
+	ctx := auth.WithIdentity(auth.WithPolicy(r.Context(), policy), &auth.Identity{Subject: name, Name: name, Method: auth.MethodBasic})
+	return r.WithContext(ctx)
+}
+
+func TestAPIHandlerEnforcesQueueRoles(t *testing.T) {
+	grants := []string{"user:basic:viewer viewer", "user:basic:operator operator", "user:basic:admin admin", "user:basic:other admin payments"}
+	tests := []struct {
+		name       string
+		user       string
+		method     string
+		target     string
+		wantStatus int
+	}{
+		{name: "no grant on queue", user: "other", method: http.MethodGet, target: "/api/v1/queues/orders?prefix=other", wantStatus: http.StatusForbidden},
+		{name: "viewer reads", user: "viewer", method: http.MethodGet, target: "/api/v1/queues/orders?prefix=other", wantStatus: http.StatusBadRequest},
+		{name: "viewer cannot pause", user: "viewer", method: http.MethodPost, target: "/api/v1/queues/orders/pause", wantStatus: http.StatusForbidden},
+		{name: "viewer cannot retry", user: "viewer", method: http.MethodPost, target: "/api/v1/queues/orders/jobs/1/retry", wantStatus: http.StatusForbidden},
+		{name: "operator cannot drain", user: "operator", method: http.MethodPost, target: "/api/v1/queues/orders/drain", wantStatus: http.StatusForbidden},
+		{name: "admin drains", user: "admin", method: http.MethodPost, target: "/api/v1/queues/orders/drain", wantStatus: http.StatusBadRequest},
+	}
+
+	handler := newTestAPIHandler()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, asUser(t, httptest.NewRequest(tc.method, tc.target, nil), tc.user, grants...))
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status mismatch: got %d want %d (%s)", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.wantStatus == http.StatusForbidden {
+				if detail := decodeAPIError(t, rec); detail.Code != "forbidden" {
+					t.Fatalf("unexpected error body: %+v", detail)
+				}
+			}
+		})
+	}
+}
+
+func TestActionHandlersEnforceQueueRoles(t *testing.T) {
+	exp := explorer.New(nil, "bull")
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		target  string
+	}{
+		{name: "retry job", handler: RetryJobHandler(exp, nil), target: "/job/retry?queue=orders&id=1"},
+		{name: "pause queue", handler: PauseQueueHandler(exp, nil), target: "/queue/pause?queue=orders"},
+		{name: "obliterate queue", handler: ObliterateQueueHandler(exp, nil), target: "/queue/obliterate?queue=orders&confirm=orders"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tc.handler(rec, asUser(t, httptest.NewRequest(http.MethodPost, tc.target, nil), "alice", "user:basic:alice viewer"))
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("status mismatch: got %d want 403 (%s)", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestReadHandlersRequireViewer(t *testing.T) {
+	req := asUser(t, httptest.NewRequest(http.MethodGet, "/job/detail?queue=orders&id=1", nil), "alice", "user:basic:alice viewer payments")
+	rec := httptest.NewRecorder()
+	JobDetailHandler(explorer.New(nil, "bull"))(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status mismatch: got %d want 403 (%s)", rec.Code, rec.Body.String())
+	}
+}
+
+func TestVisibleQueueStats(t *testing.T) {
+	stats := []explorer.QueueStats{{Name: "orders"}, {Name: "payments"}, {Name: "payments-retry"}}
+	req := asUser(t, httptest.NewRequest(http.MethodGet, "/", nil), "alice", "user:basic:alice viewer payments*")
+
+	visible := visibleQueueStats(req, stats)
+	if len(visible) != 2 || visible[0].Name != "payments" || visible[1].Name != "payments-retry" {
+		t.Fatalf("visible queues = %+v", visible)
+	}
+	if got := visibleQueueStats(httptest.NewRequest(http.MethodGet, "/", nil), stats); len(got) != len(stats) {
+		t.Fatalf("without a policy got %d queues, want %d", len(got), len(stats))
+	}
+}
+
+func TestJobButtonsFollowRole(t *testing.T) {
+	tmpl := newTemplate("buttons")
+	if _, err := tmpl.Parse(actionPartialsTmpl + `{{template "job-buttons" .}}`); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	job := explorer.JobSummary{ID: "1", Queue: "orders", Prefix: "bull", State: "failed"}
+
+	render := func(r *http.Request) string {
+		var b strings.Builder
+		if err := executeTemplate(&b, r, tmpl, job); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+		return b.String()
+	}
+
+	viewer := render(asUser(t, httptest.NewRequest(http.MethodGet, "/", nil), "alice", "user:basic:alice viewer"))
+	if strings.Contains(viewer, "Retry") || !strings.Contains(viewer, "Logs") {
+		t.Fatalf("viewer buttons = %s", viewer)
+	}
+	operator := render(asUser(t, httptest.NewRequest(http.MethodGet, "/", nil), "alice", "user:basic:alice operator"))
+	if !strings.Contains(operator, "Retry") {
+		t.Fatalf("operator buttons = %s", operator)
+	}
+}
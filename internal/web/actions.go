@@ -0,0 +1,455 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/audit"
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+// actionResult is returned by every mutating endpoint. HTMX callers get a
+// small HTML fragment that replaces the button; everyone else gets JSON.
+type actionResult struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+	Count   *int64 `json:"count,omitempty"`
+	// Jobs are the jobs a queue action changed, for the audit log.
+	Jobs []string `json:"-"`
+}
+
+func countResult(message string, count int64) actionResult {
+	return actionResult{OK: true, Message: message, Count: &count}
+}
+
+// jobsResult counts the jobs a queue action changed and keeps their IDs for
+// the audit log.
+func jobsResult(message string, jobs []string) actionResult {
+	result := countResult(message, int64(len(jobs)))
+	result.Jobs = jobs
+	return result
+}
+
+const actionResultTmpl = `<span class="text-xs font-medium {{if .OK}}text-green-700{{else}}text-red-700{{end}}">{{.Message}}</span>`
+
+var actionResultTemplate = template.Must(newTemplate("action-result").Parse(actionResultTmpl))
+
+// actionPartialsTmpl holds the action and status partials for job rows and
+// queues. It is parsed alongside every page template that shows actions.
+const actionPartialsTmpl = `
+{{define "job-buttons"}}
+<a href="{{basePath}}/job/logs?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}" class="ml-3 font-medium text-gray-600 hover:text-gray-900" target="_blank">Logs</a>
+{{if can "operator" .Queue}}
+{{if eq .State "failed"}}
+<button hx-post="{{basePath}}/job/retry?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}" hx-confirm="Retry job {{.ID}}?" hx-swap="outerHTML" class="ml-3 font-medium text-amber-600 hover:text-amber-800">Retry</button>
+{{end}}
+{{if eq .State "delayed"}}
+<button hx-post="{{basePath}}/job/promote?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}" hx-confirm="Promote delayed job {{.ID}} now?" hx-swap="outerHTML" class="ml-3 font-medium text-purple-600 hover:text-purple-800">Promote</button>
+{{end}}
+{{if ne .State "active"}}
+<button hx-post="{{basePath}}/job/remove?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}" hx-confirm="Remove job {{.ID}} and its logs/dependency keys? This cannot be undone." hx-swap="outerHTML" class="ml-3 font-medium text-red-600 hover:text-red-800">Remove</button>
+{{end}}
+{{end}}
+{{end}}
+
+{{define "job-lock"}}
+{{with .Lock}}
+{{if .Missing}}
+<span class="rounded-full bg-red-100 px-2 py-0.5 text-xs font-semibold text-red-800" title="Still in the active list without a lock: a stalled-job candidate">No lock</span>
+{{else}}
+<span class="rounded-full bg-green-100 px-2 py-0.5 text-xs font-semibold text-green-800" title="{{.Token}}">Locked</span>
+{{if .ExpiresIn}}<span class="ml-1 text-xs text-gray-500">{{.ExpiresIn}} left</span>{{end}}
+{{end}}
+{{end}}
+{{if not .ProcessedOn.IsZero}}<div class="mt-1 text-xs text-gray-500">since {{.ProcessedOn.Format "15:04:05"}}</div>{{end}}
+{{end}}
+
+{{define "queue-pause-button"}}
+{{if can "operator" .Name}}
+{{if .IsPaused}}
+<button hx-post="{{basePath}}/queue/resume?prefix={{urlquery .Prefix}}&queue={{urlquery .Name}}" hx-confirm="Resume queue {{.Name}}?" hx-swap="outerHTML" class="rounded-md border border-green-300 px-3 py-1 text-xs font-semibold text-green-700 hover:bg-green-50">Resume</button>
+{{else}}
+<button hx-post="{{basePath}}/queue/pause?prefix={{urlquery .Prefix}}&queue={{urlquery .Name}}" hx-confirm="Pause queue {{.Name}}? Workers stop picking up new jobs." hx-swap="outerHTML" class="rounded-md border border-slate-300 px-3 py-1 text-xs font-semibold text-slate-700 hover:bg-slate-50">Pause</button>
+{{end}}
+{{end}}
+{{end}}
+
+{{define "queue-paused-badge"}}
+{{if .IsPaused}}<span class="rounded-full bg-slate-800 px-2 py-0.5 text-xs font-semibold uppercase tracking-wide text-white">Paused</span>{{end}}
+{{end}}
+`
+
+// RetryJobHandler retries a failed job.
+func RetryJobHandler(exp *explorer.Explorer, auditLog *audit.Logger) http.HandlerFunc {
+	return jobActionHandler("retryJob", "Retried", auth.RoleOperator, exp, auditLog, exp.RetryJob)
+}
+
+// RemoveJobHandler removes a job that is not active or locked.
+func RemoveJobHandler(exp *explorer.Explorer, auditLog *audit.Logger) http.HandlerFunc {
+	return jobActionHandler("removeJob", "Removed", auth.RoleOperator, exp, auditLog, exp.RemoveJob)
+}
+
+// PromoteJobHandler moves a delayed job to wait immediately.
+func PromoteJobHandler(exp *explorer.Explorer, auditLog *audit.Logger) http.HandlerFunc {
+	return jobActionHandler("promoteJob", "Promoted", auth.RoleOperator, exp, auditLog, exp.PromoteJob)
+}
+
+// jobAction is a mutating job endpoint's work.
+type jobAction func(ctx context.Context, queuePrefix, queueName, jobID string) error
+
+// queueAction is a mutating queue endpoint's work, with its options read
+// from the request. The dashboard and the JSON API share them.
+type queueAction func(r *http.Request, queue explorer.QueueRef) (actionResult, error)
+
+// PromoteDelayedHandler promotes every delayed job in a queue.
+func PromoteDelayedHandler(exp *explorer.Explorer, auditLog *audit.Logger) http.HandlerFunc {
+	return queueActionHandler("promoteDelayedJobs", auth.RoleOperator, exp, auditLog, promoteDelayedAction(exp))
+}
+
+func promoteDelayedAction(exp *explorer.Explorer) queueAction {
+	return func(r *http.Request, queue explorer.QueueRef) (actionResult, error) {
+		promoted, err := exp.PromoteDelayedJobs(r.Context(), queue.Prefix, queue.Name)
+		if err != nil {
+			return actionResult{}, fmt.Errorf("promoted %d delayed jobs before error: %w", promoted, err)
+		}
+		return countResult(fmt.Sprintf("Promoted %d delayed jobs", promoted), promoted), nil
+	}
+}
+
+// PauseQueueHandler pauses a queue.
+func PauseQueueHandler(exp *explorer.Explorer, auditLog *audit.Logger) http.HandlerFunc {
+	return queueActionHandler("pauseQueue", auth.RoleOperator, exp, auditLog, pauseQueueAction(exp))
+}
+
+func pauseQueueAction(exp *explorer.Explorer) queueAction {
+	return func(r *http.Request, queue explorer.QueueRef) (actionResult, error) {
+		return actionResult{OK: true, Message: "Paused"}, exp.PauseQueue(r.Context(), queue.Prefix, queue.Name)
+	}
+}
+
+// ResumeQueueHandler resumes a paused queue.
+func ResumeQueueHandler(exp *explorer.Explorer, auditLog *audit.Logger) http.HandlerFunc {
+	return queueActionHandler("resumeQueue", auth.RoleOperator, exp, auditLog, resumeQueueAction(exp))
+}
+
+func resumeQueueAction(exp *explorer.Explorer) queueAction {
+	return func(r *http.Request, queue explorer.QueueRef) (actionResult, error) {
+		return actionResult{OK: true, Message: "Resumed"}, exp.ResumeQueue(r.Context(), queue.Prefix, queue.Name)
+	}
+}
+
+// actionTimeBudget bounds how long an action that works through a queue in
+// batches may run inside one request, well under the server's 15s
+// WriteTimeout. One that runs out reports what it did so far and can be run
+// again for the rest.
+const actionTimeBudget = 10 * time.Second
+
+// withActionBudget returns the context a batched action runs under.
+func withActionBudget(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), actionTimeBudget)
+}
+
+// outOfBudget reports whether an action failed because its budget ran out,
+// rather than because Redis failed or the client went away.
+func outOfBudget(r *http.Request, ctx context.Context, err error) bool {
+	return err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && r.Context().Err() == nil
+}
+
+// CleanQueueHandler removes completed or failed jobs older than a grace
+// period in throttled batches. With dryrun=true it only counts them.
+func CleanQueueHandler(exp *explorer.Explorer, auditLog *audit.Logger) http.HandlerFunc {
+	return queueActionHandler("cleanQueue", auth.RoleAdmin, exp, auditLog, cleanQueueAction(exp))
+}
+
+func cleanQueueAction(exp *explorer.Explorer) queueAction {
+	const (
+		cleanBatchSize    = 500
+		cleanBatchPause   = 50 * time.Millisecond
+		cleanDefaultLimit = 10000
+		// cleanMaxLimit keeps a full clean, 20 batches and their pauses,
+		// within actionTimeBudget.
+		cleanMaxLimit = 10000
+	)
+
+	return func(r *http.Request, queue explorer.QueueRef) (actionResult, error) {
+		state := strings.TrimSpace(r.FormValue("state"))
+		if state != "completed" && state != "failed" {
+			return actionResult{}, errBadActionRequest("state must be completed or failed")
+		}
+		graceValue := strings.TrimSpace(r.FormValue("grace"))
+		grace, err := parseGrace(graceValue)
+		if err != nil {
+			return actionResult{}, errBadActionRequest(err.Error())
+		}
+		if graceValue == "" {
+			graceValue = "0s"
+		}
+		limit := parsePositiveInt(r.FormValue("limit"), cleanDefaultLimit)
+		if limit > cleanMaxLimit {
+			limit = cleanMaxLimit
+		}
+		dryRun, _ := strconv.ParseBool(r.FormValue("dryrun"))
+
+		ctx, cancel := withActionBudget(r)
+		defer cancel()
+		result, err := exp.CleanJobs(ctx, queue.Prefix, queue.Name, explorer.CleanOptions{
+			State:     state,
+			Grace:     grace,
+			Limit:     int64(limit),
+			BatchSize: cleanBatchSize,
+			Pause:     cleanBatchPause,
+			DryRun:    dryRun,
+		})
+		if outOfBudget(r, ctx, err) {
+			return jobsResult(fmt.Sprintf("Removed %d %s jobs finished more than %s ago before the %s time limit; run it again for the rest", result.Removed, state, graceValue, actionTimeBudget), result.JobIDs), nil
+		}
+		if err != nil {
+			return actionResult{Jobs: result.JobIDs}, fmt.Errorf("removed %d %s jobs before error: %w", result.Removed, state, err)
+		}
+
+		if result.DryRun {
+			return countResult(fmt.Sprintf("Would remove %d %s jobs finished more than %s ago", result.Matched, state, graceValue), result.Matched), nil
+		}
+		return jobsResult(fmt.Sprintf("Removed %d %s jobs finished more than %s ago", result.Removed, state, graceValue), result.JobIDs), nil
+	}
+}
+
+// DrainQueueHandler removes every waiting, paused, delayed and prioritized
+// job. The queue name must be typed back as confirm=<queue>.
+func DrainQueueHandler(exp *explorer.Explorer, auditLog *audit.Logger) http.HandlerFunc {
+	return queueActionHandler("drainQueue", auth.RoleAdmin, exp, auditLog, drainQueueAction(exp))
+}
+
+func drainQueueAction(exp *explorer.Explorer) queueAction {
+	return func(r *http.Request, queue explorer.QueueRef) (actionResult, error) {
+		if err := requireTypedConfirmation(r, queue.Name); err != nil {
+			return actionResult{}, err
+		}
+		ctx, cancel := withActionBudget(r)
+		defer cancel()
+		removed, err := exp.DrainQueue(ctx, queue.Prefix, queue.Name)
+		if outOfBudget(r, ctx, err) {
+			return jobsResult(fmt.Sprintf("Drained %d jobs before the %s time limit; run it again for the rest", len(removed), actionTimeBudget), removed), nil
+		}
+		if err != nil {
+			return actionResult{Jobs: removed}, fmt.Errorf("drained %d jobs before error: %w", len(removed), err)
+		}
+		return jobsResult(fmt.Sprintf("Drained %d jobs", len(removed)), removed), nil
+	}
+}
+
+// ObliterateQueueHandler deletes a queue and all of its keys. The queue name
+// must be typed back as confirm=<queue>; queues with active jobs are refused
+// unless force=true.
+func ObliterateQueueHandler(exp *explorer.Explorer, auditLog *audit.Logger) http.HandlerFunc {
+	return queueActionHandler("obliterateQueue", auth.RoleAdmin, exp, auditLog, obliterateQueueAction(exp))
+}
+
+func obliterateQueueAction(exp *explorer.Explorer) queueAction {
+	return func(r *http.Request, queue explorer.QueueRef) (actionResult, error) {
+		if err := requireTypedConfirmation(r, queue.Name); err != nil {
+			return actionResult{}, err
+		}
+		force, _ := strconv.ParseBool(r.FormValue("force"))
+		ctx, cancel := withActionBudget(r)
+		defer cancel()
+		err := exp.ObliterateQueue(ctx, queue.Prefix, queue.Name, force)
+		if outOfBudget(r, ctx, err) {
+			log.Printf("🗑️ obliterate of %s:%s stopped at the %s time limit (force=%t)", queue.Prefix, queue.Name, actionTimeBudget, force)
+			return actionResult{OK: true, Message: fmt.Sprintf("%s is paused and partly deleted: the obliterate stopped at the %s time limit; run it again to finish", queue.Name, actionTimeBudget)}, nil
+		}
+		if err != nil {
+			return actionResult{}, err
+		}
+		log.Printf("🗑️ obliterated queue %s:%s (force=%t)", queue.Prefix, queue.Name, force)
+		return actionResult{OK: true, Message: fmt.Sprintf("Obliterated %s", queue.Name)}, nil
+	}
+}
+
+// queueActionHandler serves a queue action for the dashboard. operation is
+// the API operation ID, used in logs and audit events so both name an action
+// the same way.
+func queueActionHandler(operation string, role auth.Role, exp *explorer.Explorer, auditLog *audit.Logger, action queueAction) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requirePost(w, r) {
+			return
+		}
+
+		// Requests turned away before the action runs are audited too.
+		queueName := strings.TrimSpace(r.FormValue("queue"))
+		if queueName == "" {
+			recordAction(r, auditLog, operation, explorer.QueueRef{Prefix: r.FormValue("prefix")}, nil, actionResult{}, errBadActionRequest("queue parameter required"))
+			http.Error(w, "queue parameter required", http.StatusBadRequest)
+			return
+		}
+		if err := queueAccess(r, queueName, role); err != nil {
+			recordAction(r, auditLog, operation, explorer.QueueRef{Prefix: r.FormValue("prefix"), Name: queueName}, nil, actionResult{}, err)
+			writeActionError(w, r, err)
+			return
+		}
+		prefix, err := exp.ResolvePrefix(strings.TrimSpace(r.FormValue("prefix")))
+		if err != nil {
+			recordAction(r, auditLog, operation, explorer.QueueRef{Prefix: r.FormValue("prefix"), Name: queueName}, nil, actionResult{}, err)
+			writeActionError(w, r, err)
+			return
+		}
+
+		queue := explorer.QueueRef{Prefix: prefix, Name: queueName}
+		result, err := action(r, queue)
+		recordAction(r, auditLog, operation, queue, result.Jobs, result, err)
+		if err != nil {
+			log.Printf("❌ %s error (prefix=%s queue=%s): %v", operation, prefix, queueName, err)
+			writeActionError(w, r, err)
+			return
+		}
+
+		writeActionResult(w, r, http.StatusOK, result)
+	}
+}
+
+// jobActionHandler serves a job action for the dashboard; operation is as
+// for queueActionHandler.
+func jobActionHandler(operation, successMessage string, role auth.Role, exp *explorer.Explorer, auditLog *audit.Logger, action jobAction) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requirePost(w, r) {
+			return
+		}
+
+		queueName := strings.TrimSpace(r.FormValue("queue"))
+		jobID := strings.TrimSpace(r.FormValue("id"))
+		var jobs []string
+		if jobID != "" {
+			jobs = []string{jobID}
+		}
+		if queueName == "" || jobID == "" {
+			recordAction(r, auditLog, operation, explorer.QueueRef{Prefix: r.FormValue("prefix"), Name: queueName}, jobs, actionResult{}, errBadActionRequest("queue and id parameters required"))
+			http.Error(w, "queue and id parameters required", http.StatusBadRequest)
+			return
+		}
+		if err := queueAccess(r, queueName, role); err != nil {
+			recordAction(r, auditLog, operation, explorer.QueueRef{Prefix: r.FormValue("prefix"), Name: queueName}, jobs, actionResult{}, err)
+			writeActionError(w, r, err)
+			return
+		}
+		prefix, err := exp.ResolvePrefix(strings.TrimSpace(r.FormValue("prefix")))
+		if err != nil {
+			recordAction(r, auditLog, operation, explorer.QueueRef{Prefix: r.FormValue("prefix"), Name: queueName}, jobs, actionResult{}, err)
+			writeActionError(w, r, err)
+			return
+		}
+
+		result := actionResult{OK: true, Message: successMessage}
+		err = action(r.Context(), prefix, queueName, jobID)
+		recordAction(r, auditLog, operation, explorer.QueueRef{Prefix: prefix, Name: queueName}, []string{jobID}, result, err)
+		if err != nil {
+			log.Printf("❌ %s error (prefix=%s queue=%s id=%s): %v", operation, prefix, queueName, jobID, err)
+			writeActionError(w, r, err)
+			return
+		}
+
+		writeActionResult(w, r, http.StatusOK, result)
+	}
+}
+
+func requirePost(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodPost {
+		return true
+	}
+	w.Header().Set("Allow", http.MethodPost)
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+// requireTypedConfirmation guards destructive queue actions: the caller must
+// repeat the queue name in the confirm parameter.
+func requireTypedConfirmation(r *http.Request, queueName string) error {
+	if strings.TrimSpace(r.FormValue("confirm")) != queueName {
+		return errBadActionRequest(fmt.Sprintf("type the queue name %q to confirm", queueName))
+	}
+	return nil
+}
+
+// badActionRequest marks validation failures so they map to 400.
+type badActionRequest struct {
+	message string
+}
+
+func (e badActionRequest) Error() string {
+	return e.message
+}
+
+func errBadActionRequest(message string) error {
+	return badActionRequest{message: message}
+}
+
+// parseGrace accepts Go durations ("90m", "24h") plus a whole-day suffix
+// ("7d"). An empty value means no grace period.
+func parseGrace(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid grace period %q", raw)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	grace, err := time.ParseDuration(raw)
+	if err != nil || grace < 0 {
+		return 0, fmt.Errorf("invalid grace period %q", raw)
+	}
+	return grace, nil
+}
+
+func actionErrorStatus(err error) int {
+	var badRequest badActionRequest
+	switch {
+	case errors.As(err, &badRequest), errors.Is(err, explorer.ErrUnknownPrefix):
+		return http.StatusBadRequest
+	case errors.Is(err, errForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, explorer.ErrJobNotFound), errors.Is(err, explorer.ErrJobSchedulerNotFound), errors.Is(err, errBulkRetryTaskNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, explorer.ErrJobLocked), errors.Is(err, explorer.ErrJobActive), errors.Is(err, explorer.ErrJobWrongState),
+		errors.Is(err, explorer.ErrJobHasChildren), errors.Is(err, explorer.ErrQueueHasActiveJobs), errors.Is(err, explorer.ErrQueueNotPaused):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeActionError(w http.ResponseWriter, r *http.Request, err error) {
+	writeActionResult(w, r, actionErrorStatus(err), actionResult{OK: false, Message: err.Error()})
+}
+
+func writeActionResult(w http.ResponseWriter, r *http.Request, status int, result actionResult) {
+	if r.Header.Get("HX-Request") != "" {
+		// HTMX only swaps 2xx responses, so failures are reported in-band.
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := executeTemplate(w, r, actionResultTemplate, result); err != nil {
+			log.Printf("❌ Template execution error (action result): %v", err)
+		}
+		return
+	}
+
+	writeJSON(w, status, result)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("❌ JSON encode error: %v", err)
+	}
+}
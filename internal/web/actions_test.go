@@ -0,0 +1,173 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+func TestActionErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "not found", err: fmt.Errorf("%w: 1", explorer.ErrJobNotFound), want: http.StatusNotFound},
+		{name: "locked", err: fmt.Errorf("%w: 1", explorer.ErrJobLocked), want: http.StatusConflict},
+		{name: "active", err: fmt.Errorf("%w: 1", explorer.ErrJobActive), want: http.StatusConflict},
+		{name: "wrong state", err: fmt.Errorf("%w: 1", explorer.ErrJobWrongState), want: http.StatusConflict},
+		{name: "pending children", err: fmt.Errorf("%w: 1", explorer.ErrJobHasChildren), want: http.StatusConflict},
+		{name: "queue has active jobs", err: fmt.Errorf("%w: orders", explorer.ErrQueueHasActiveJobs), want: http.StatusConflict},
+		{name: "queue not paused", err: fmt.Errorf("%w: orders", explorer.ErrQueueNotPaused), want: http.StatusConflict},
+		{name: "bad request", err: errBadActionRequest("state must be completed or failed"), want: http.StatusBadRequest},
+		{name: "unknown prefix", err: fmt.Errorf("%w: other", explorer.ErrUnknownPrefix), want: http.StatusBadRequest},
+		{name: "forbidden", err: fmt.Errorf("%w: admin role required", errForbidden), want: http.StatusForbidden},
+		{name: "other", err: fmt.Errorf("redis down"), want: http.StatusInternalServerError},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := actionErrorStatus(tc.err); got != tc.want {
+				t.Fatalf("actionErrorStatus(%v) = %d, want %d", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWriteActionResultJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/job/retry", nil)
+	rec := httptest.NewRecorder()
+
+	writeActionResult(rec, req, http.StatusConflict, actionResult{OK: false, Message: "nope"})
+
+	if got, want := rec.Code, http.StatusConflict; got != want {
+		t.Fatalf("status mismatch: got %d want %d", got, want)
+	}
+	var got actionResult
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.OK || got.Message != "nope" {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+}
+
+func TestWriteActionResultHTMXAlwaysSwaps(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/job/retry", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+
+	writeActionResult(rec, req, http.StatusConflict, actionResult{OK: false, Message: "<nope>"})
+
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("status mismatch: got %d want %d", got, want)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "text-red-700") || !strings.Contains(body, "&lt;nope&gt;") {
+		t.Fatalf("unexpected fragment: %s", body)
+	}
+}
+
+func TestRequirePostRejectsGet(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/job/retry", nil)
+	rec := httptest.NewRecorder()
+
+	if requirePost(rec, req) {
+		t.Fatal("expected GET to be rejected")
+	}
+	if got, want := rec.Code, http.StatusMethodNotAllowed; got != want {
+		t.Fatalf("status mismatch: got %d want %d", got, want)
+	}
+}
+
+func TestParseGrace(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{raw: "", want: 0},
+		{raw: "90m", want: 90 * time.Minute},
+		{raw: "24h", want: 24 * time.Hour},
+		{raw: "7d", want: 7 * 24 * time.Hour},
+		{raw: "-1h", wantErr: true},
+		{raw: "xd", wantErr: true},
+		{raw: "soon", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.raw, func(t *testing.T) {
+			got, err := parseGrace(tc.raw)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tc.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseGrace(%q) returned error: %v", tc.raw, err)
+			}
+			if got != tc.want {
+				t.Fatalf("parseGrace(%q) = %v, want %v", tc.raw, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRequireTypedConfirmation(t *testing.T) {
+	tests := []struct {
+		confirm string
+		wantErr bool
+	}{
+		{confirm: "orders", wantErr: false},
+		{confirm: " orders ", wantErr: false},
+		{confirm: "Orders", wantErr: true},
+		{confirm: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/queue/obliterate?queue=orders&confirm="+url.QueryEscape(tc.confirm), nil)
+		err := requireTypedConfirmation(req, "orders")
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("requireTypedConfirmation(%q) error = %v, wantErr %t", tc.confirm, err, tc.wantErr)
+		}
+		if err != nil && actionErrorStatus(err) != http.StatusBadRequest {
+			t.Fatalf("expected confirmation failure to map to 400, got %d", actionErrorStatus(err))
+		}
+	}
+}
+
+func TestOutOfBudget(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/queue/clean", nil)
+	expired, cancel := context.WithTimeout(req.Context(), 0)
+	defer cancel()
+	<-expired.Done()
+
+	if !outOfBudget(req, expired, expired.Err()) {
+		t.Fatal("an expired budget should count as out of budget")
+	}
+	if outOfBudget(req, expired, nil) {
+		t.Fatal("an action that finished is not out of budget")
+	}
+	if outOfBudget(req, req.Context(), fmt.Errorf("redis down")) {
+		t.Fatal("a Redis error within budget is a failure")
+	}
+
+	// A client that hung up is not the budget running out.
+	gone, hangUp := context.WithCancel(req.Context())
+	hangUp()
+	req = req.WithContext(gone)
+	budget, cancelBudget := withActionBudget(req)
+	defer cancelBudget()
+	if outOfBudget(req, budget, budget.Err()) {
+		t.Fatal("a cancelled request is not out of budget")
+	}
+}
@@ -0,0 +1,767 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/audit"
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+// apiPathPrefix is where the versioned JSON API is mounted. Unlike the HTML
+// fragments the dashboard swaps in, its response shapes are a contract.
+const apiPathPrefix = "/api/v1"
+
+const (
+	apiDefaultPageSize = 100
+	apiMaxPageSize     = 1000
+)
+
+// apiError is the body of every failed API response.
+type apiError struct {
+	Error apiErrorDetail `json:"error"`
+}
+
+type apiErrorDetail struct {
+	Status int `json:"status"`
+	// Code is a stable, machine-readable form of Status, e.g. "not_found".
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type apiQueue struct {
+	Prefix   string         `json:"prefix"`
+	Name     string         `json:"name"`
+	IsPaused bool           `json:"isPaused"`
+	Counts   apiJobCounts   `json:"counts"`
+	Limits   apiQueueLimits `json:"limits"`
+	// Workers is omitted when CLIENT LIST is unavailable.
+	Workers *int64 `json:"workers,omitempty"`
+}
+
+type apiJobCounts struct {
+	Waiting         int64 `json:"waiting"`
+	Active          int64 `json:"active"`
+	Paused          int64 `json:"paused"`
+	Prioritized     int64 `json:"prioritized"`
+	WaitingChildren int64 `json:"waitingChildren"`
+	Completed       int64 `json:"completed"`
+	Failed          int64 `json:"failed"`
+	Delayed         int64 `json:"delayed"`
+	Stalled         int64 `json:"stalled"`
+	// ActiveUnlocked is omitted when the lock check failed.
+	ActiveUnlocked *int64 `json:"activeUnlocked,omitempty"`
+	Total          int64  `json:"total"`
+}
+
+type apiQueueLimits struct {
+	GlobalConcurrency   int64 `json:"globalConcurrency"`
+	RateLimitMax        int64 `json:"rateLimitMax"`
+	RateLimitDurationMs int64 `json:"rateLimitDurationMs"`
+	LimiterCount        int64 `json:"limiterCount"`
+	LimiterResetInMs    int64 `json:"limiterResetInMs"`
+	RateLimited         bool  `json:"rateLimited"`
+}
+
+type apiQueueList struct {
+	Queues    []apiQueue `json:"queues"`
+	UpdatedAt time.Time  `json:"updatedAt,omitzero"`
+}
+
+type apiJobSummary struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	State        string          `json:"state"`
+	Prefix       string          `json:"prefix"`
+	Queue        string          `json:"queue"`
+	Timestamp    time.Time       `json:"timestamp,omitzero"`
+	ProcessedOn  time.Time       `json:"processedOn,omitzero"`
+	AttemptsMade int             `json:"attemptsMade"`
+	Data         json.RawMessage `json:"data,omitempty"`
+	Opts         json.RawMessage `json:"opts,omitempty"`
+	FailedReason string          `json:"failedReason,omitempty"`
+	Lock         *apiJobLock     `json:"lock,omitempty"`
+}
+
+type apiJobLock struct {
+	Token       string `json:"token,omitempty"`
+	ExpiresInMs int64  `json:"expiresInMs"`
+	Missing     bool   `json:"missing"`
+}
+
+type apiJobPage struct {
+	Prefix      string          `json:"prefix"`
+	Queue       string          `json:"queue"`
+	State       string          `json:"state"`
+	Page        int             `json:"page"`
+	Limit       int             `json:"limit"`
+	HasNextPage bool            `json:"hasNextPage"`
+	Jobs        []apiJobSummary `json:"jobs"`
+}
+
+// apiJob is a job's full hash. explorer.Job leaves its location out of JSON.
+type apiJob struct {
+	explorer.Job
+	State  string `json:"state"`
+	Queue  string `json:"queue"`
+	Prefix string `json:"prefix"`
+}
+
+type apiSearchResults struct {
+	Prefix       string          `json:"prefix"`
+	Queue        string          `json:"queue"`
+	Query        string          `json:"query"`
+	Since        string          `json:"since,omitempty"`
+	Page         int             `json:"page"`
+	SearchedJobs int             `json:"searchedJobs"`
+	HasNextPage  bool            `json:"hasNextPage"`
+	Jobs         []apiJobSummary `json:"jobs"`
+}
+
+type apiBulkRetryPreview struct {
+	Prefix      string          `json:"prefix"`
+	Queue       string          `json:"queue"`
+	Matched     int             `json:"matched"`
+	Scanned     int             `json:"scanned"`
+	Truncated   bool            `json:"truncated"`
+	ScanLimited bool            `json:"scanLimited"`
+	Sample      []apiJobSummary `json:"sample"`
+}
+
+type apiSchedulerPage struct {
+	Prefix     string                  `json:"prefix"`
+	Queue      string                  `json:"queue"`
+	Total      int64                   `json:"total"`
+	Page       int                     `json:"page"`
+	Schedulers []explorer.JobScheduler `json:"schedulers"`
+}
+
+type apiWorkerList struct {
+	Prefix  string            `json:"prefix"`
+	Queue   string            `json:"queue"`
+	Workers []explorer.Worker `json:"workers"`
+}
+
+// apiHandlerFunc returns the success body, or an error that is mapped to a
+// status the same way action errors are.
+type apiHandlerFunc func(r *http.Request) (any, error)
+
+// apiRoute is one API operation. The OpenAPI document is generated from the
+// same routes that are served, so the two cannot drift apart.
+type apiRoute struct {
+	Method string
+	// Path is relative to apiPathPrefix and may hold {wildcards}.
+	Path        string
+	OperationID string
+	Summary     string
+	Params      []apiParam
+	// Status is the success status; 0 means 200.
+	Status int
+	// Response is a zero value of the success body, for its schema.
+	Response any
+	// Role is what the request needs on the {queue} in the path; viewer when
+	// unset.
+	Role   auth.Role
+	handle apiHandlerFunc
+}
+
+type apiParam struct {
+	Name        string
+	In          string
+	Type        string
+	Required    bool
+	Enum        []string
+	Description string
+}
+
+var (
+	apiPrefixParam = apiParam{Name: "prefix", In: "query", Type: "string", Description: "Queue key prefix; the default prefix when omitted."}
+	apiQueueParam  = apiParam{Name: "queue", In: "path", Type: "string", Required: true}
+	apiJobIDParam  = apiParam{Name: "id", In: "path", Type: "string", Required: true}
+	apiPageParam   = apiParam{Name: "page", In: "query", Type: "integer", Description: "1-based page number."}
+)
+
+// APIHandler serves the JSON API under /api/v1/ for one environment.
+type APIHandler struct {
+	routes []apiRoute
+	mux    *http.ServeMux
+}
+
+func NewAPIHandler(exp *explorer.Explorer, cache *DashboardCache, bulkRetries *BulkRetryManager, auditLog *audit.Logger) *APIHandler {
+	h := &APIHandler{routes: apiRoutes(exp, cache, bulkRetries, auditLog), mux: http.NewServeMux()}
+	for _, route := range h.routes {
+		h.mux.HandleFunc(route.Method+" "+apiPathPrefix+route.Path, serveAPIRoute(route, auditLog))
+	}
+	h.mux.HandleFunc("GET "+apiPathPrefix+"/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, newOpenAPIDocument(h.routes, basePath(r)+apiPathPrefix))
+	})
+	return h
+}
+
+func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := h.mux.Handler(r); pattern == "" {
+		// Let the mux decide between 404 and 405, but answer in JSON.
+		rec := &discardResponseWriter{header: make(http.Header), status: http.StatusOK}
+		h.mux.ServeHTTP(rec, r)
+		if allow := rec.header.Get("Allow"); allow != "" {
+			w.Header().Set("Allow", allow)
+		}
+		writeAPIError(w, rec.status, errors.New(strings.ToLower(http.StatusText(rec.status))))
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// NormalizeAPIPath maps an API request path to its route pattern, for
+// metrics labels that must not carry queue names or job IDs.
+func NormalizeAPIPath(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, apiPathPrefix)
+	if !ok {
+		return "", false
+	}
+	if rest == "/openapi.json" {
+		return path, true
+	}
+	for _, pattern := range apiRoutePaths() {
+		if matchAPIPath(pattern, rest) {
+			return apiPathPrefix + pattern, true
+		}
+	}
+	return "", false
+}
+
+// apiRoutePaths lists the distinct route paths. The routes are built without
+// an explorer; only their paths are read.
+var apiRoutePaths = sync.OnceValue(func() []string {
+	var paths []string
+	for _, route := range apiRoutes(nil, nil, nil, nil) {
+		if !slices.Contains(paths, route.Path) {
+			paths = append(paths, route.Path)
+		}
+	}
+	return paths
+})
+
+func matchAPIPath(pattern, path string) bool {
+	patternParts := strings.Split(pattern, "/")
+	pathParts := strings.Split(path, "/")
+	if len(patternParts) != len(pathParts) {
+		return false
+	}
+	for i, part := range patternParts {
+		if strings.HasPrefix(part, "{") {
+			if pathParts[i] == "" {
+				return false
+			}
+			continue
+		}
+		if part != pathParts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// serveAPIRoute runs route behind its role check. Refused writes are
+// audited here; the rest are audited by the routes themselves.
+func serveAPIRoute(route apiRoute, auditLog *audit.Logger) http.HandlerFunc {
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	role := max(route.Role, auth.RoleViewer)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if queue := r.PathValue("queue"); queue != "" {
+			if err := queueAccess(r, queue, role); err != nil {
+				if route.Method != http.MethodGet {
+					var jobs []string
+					if id := r.PathValue("id"); id != "" {
+						jobs = []string{id}
+					}
+					recordAction(r, auditLog, route.OperationID, explorer.QueueRef{Prefix: r.FormValue("prefix"), Name: queue}, jobs, actionResult{}, err)
+				}
+				writeAPIError(w, http.StatusForbidden, err)
+				return
+			}
+		}
+		body, err := route.handle(r)
+		if err != nil {
+			log.Printf("❌ api %s error (path=%s): %v", route.OperationID, r.URL.Path, err)
+			writeAPIError(w, actionErrorStatus(err), err)
+			return
+		}
+		writeJSON(w, status, body)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, apiError{Error: apiErrorDetail{
+		Status:  status,
+		Code:    apiErrorCode(status),
+		Message: err.Error(),
+	}})
+}
+
+func apiErrorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusMethodNotAllowed:
+		return "method_not_allowed"
+	case http.StatusConflict:
+		return "conflict"
+	default:
+		return "internal"
+	}
+}
+
+// discardResponseWriter records the status and headers of a response and
+// drops its body.
+type discardResponseWriter struct {
+	header http.Header
+	status int
+}
+
+func (d *discardResponseWriter) Header() http.Header         { return d.header }
+func (d *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (d *discardResponseWriter) WriteHeader(status int)      { d.status = status }
+
+func apiRoutes(exp *explorer.Explorer, cache *DashboardCache, bulkRetries *BulkRetryManager, auditLog *audit.Logger) []apiRoute {
+	queueParams := []apiParam{apiQueueParam, apiPrefixParam}
+	jobParams := []apiParam{apiQueueParam, apiJobIDParam, apiPrefixParam}
+	confirmParam := apiParam{Name: "confirm", In: "query", Type: "string", Required: true, Description: "The queue name, typed back to confirm."}
+	bulkRetryParams := append(slices.Clone(queueParams),
+		apiParam{Name: "q", In: "query", Type: "string", Description: "Matches the job name or failed reason; every failed job when empty."},
+		apiParam{Name: "regex", In: "query", Type: "boolean", Description: "Treat q as a regular expression."},
+	)
+
+	return []apiRoute{
+		{
+			Method: http.MethodGet, Path: "/queues", OperationID: "listQueues",
+			Summary:  "List queues with job counts from the dashboard snapshot",
+			Response: apiQueueList{},
+			handle: func(r *http.Request) (any, error) {
+				snapshot := cache.Get()
+				if len(snapshot.Stats) == 0 {
+					if err := RefreshDashboardCache(r.Context(), exp, exp.Prefixes(), cache); err != nil {
+						return nil, fmt.Errorf("dashboard snapshot unavailable: %w", err)
+					}
+					snapshot = cache.Get()
+				}
+				stats := visibleQueueStats(r, snapshot.Stats)
+				queues := make([]apiQueue, 0, len(stats))
+				for _, stat := range stats {
+					queues = append(queues, newAPIQueue(stat))
+				}
+				return apiQueueList{Queues: queues, UpdatedAt: snapshot.UpdatedAt}, nil
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/queues/{queue}", OperationID: "getQueue",
+			Summary: "Get a queue's current job counts, limits and workers",
+			Params:  queueParams, Response: apiQueue{},
+			handle: func(r *http.Request) (any, error) {
+				queue, err := apiQueueRef(r, exp)
+				if err != nil {
+					return nil, err
+				}
+				stat, err := loadFastQueueStat(r.Context(), exp, queue.Prefix, queue.Name)
+				if err != nil {
+					return nil, err
+				}
+				return newAPIQueue(stat), nil
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/queues/{queue}/jobs", OperationID: "listJobs",
+			Summary: "List a page of jobs in one state",
+			Params: append(slices.Clone(queueParams),
+				apiParam{Name: "state", In: "query", Type: "string", Required: true, Enum: explorer.JobStates},
+				apiPageParam,
+				apiParam{Name: "limit", In: "query", Type: "integer", Description: fmt.Sprintf("Jobs per page, at most %d.", apiMaxPageSize)},
+			),
+			Response: apiJobPage{},
+			handle: func(r *http.Request) (any, error) {
+				queue, err := apiQueueRef(r, exp)
+				if err != nil {
+					return nil, err
+				}
+				state := r.URL.Query().Get("state")
+				if !slices.Contains(explorer.JobStates, state) {
+					return nil, errBadActionRequest("state must be one of " + strings.Join(explorer.JobStates, ", "))
+				}
+				page := parsePositiveInt(r.URL.Query().Get("page"), 1)
+				limit := min(parsePositiveInt(r.URL.Query().Get("limit"), apiDefaultPageSize), apiMaxPageSize)
+
+				jobs, err := exp.GetJobsByStatePage(r.Context(), queue.Prefix, queue.Name, state, (page-1)*limit, limit)
+				if err != nil {
+					return nil, err
+				}
+				return apiJobPage{
+					Prefix:      queue.Prefix,
+					Queue:       queue.Name,
+					State:       state,
+					Page:        page,
+					Limit:       limit,
+					HasNextPage: len(jobs) == limit,
+					Jobs:        newAPIJobSummaries(jobs),
+				}, nil
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/queues/{queue}/jobs/{id}", OperationID: "getJob",
+			Summary: "Get a job with its data, options, progress and result",
+			Params:  jobParams, Response: apiJob{},
+			handle: func(r *http.Request) (any, error) {
+				queue, err := apiQueueRef(r, exp)
+				if err != nil {
+					return nil, err
+				}
+				job, err := exp.GetJob(r.Context(), queue.Prefix, queue.Name, r.PathValue("id"))
+				if err != nil {
+					return nil, err
+				}
+				return apiJob{Job: *job, State: job.State, Queue: job.Queue, Prefix: job.Prefix}, nil
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/queues/{queue}/search", OperationID: "searchJobs",
+			Summary: "Search a paged window of every state by substring",
+			Params: append(slices.Clone(queueParams),
+				apiParam{Name: "q", In: "query", Type: "string", Required: true, Description: "Matches the job ID, name, data, options or failed reason."},
+				apiParam{Name: "since", In: "query", Type: "string", Enum: searchWindowValues(), Description: "Only jobs created within this window."},
+				apiPageParam,
+			),
+			Response: apiSearchResults{},
+			handle: func(r *http.Request) (any, error) {
+				queue, err := apiQueueRef(r, exp)
+				if err != nil {
+					return nil, err
+				}
+				query := strings.TrimSpace(r.URL.Query().Get("q"))
+				if query == "" {
+					return nil, errBadActionRequest("q parameter required")
+				}
+				page := parsePositiveInt(r.URL.Query().Get("page"), 1)
+				window := parseSearchWindow(r.URL.Query().Get("since"), time.Now())
+
+				results, err := searchJobsAcrossStates(r.Context(), exp, queue.Prefix, queue.Name, query, page, window)
+				if err != nil {
+					return nil, err
+				}
+				return apiSearchResults{
+					Prefix:       queue.Prefix,
+					Queue:        queue.Name,
+					Query:        query,
+					Since:        window.Value,
+					Page:         page,
+					SearchedJobs: results.SearchedJobs,
+					HasNextPage:  results.HasNextPage,
+					Jobs:         newAPIJobSummaries(results.Jobs),
+				}, nil
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/queues/{queue}/workers", OperationID: "listWorkers",
+			Summary: "List the workers connected to a queue",
+			Params:  queueParams, Response: apiWorkerList{},
+			handle: func(r *http.Request) (any, error) {
+				queue, err := apiQueueRef(r, exp)
+				if err != nil {
+					return nil, err
+				}
+				workers, err := exp.GetWorkers(r.Context(), queue.Prefix, queue.Name)
+				if err != nil {
+					return nil, err
+				}
+				return apiWorkerList{Prefix: queue.Prefix, Queue: queue.Name, Workers: workers}, nil
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/queues/{queue}/schedulers", OperationID: "listJobSchedulers",
+			Summary: "List a page of job schedulers and repeatable jobs",
+			Params:  append(slices.Clone(queueParams), apiPageParam), Response: apiSchedulerPage{},
+			handle: func(r *http.Request) (any, error) {
+				queue, err := apiQueueRef(r, exp)
+				if err != nil {
+					return nil, err
+				}
+				page := parsePositiveInt(r.URL.Query().Get("page"), 1)
+				schedulers, total, err := exp.GetJobSchedulers(r.Context(), queue.Prefix, queue.Name, (page-1)*schedulersPageSize, schedulersPageSize)
+				if err != nil {
+					return nil, err
+				}
+				return apiSchedulerPage{Prefix: queue.Prefix, Queue: queue.Name, Total: total, Page: page, Schedulers: schedulers}, nil
+			},
+		},
+		apiQueueActionRoute("/queues/{queue}/pause", "pauseQueue", "Pause a queue", auth.RoleOperator, queueParams, exp, auditLog, pauseQueueAction(exp)),
+		apiQueueActionRoute("/queues/{queue}/resume", "resumeQueue", "Resume a paused queue", auth.RoleOperator, queueParams, exp, auditLog, resumeQueueAction(exp)),
+		apiQueueActionRoute("/queues/{queue}/promote", "promoteDelayedJobs", "Promote every delayed job", auth.RoleOperator, queueParams, exp, auditLog, promoteDelayedAction(exp)),
+		apiQueueActionRoute("/queues/{queue}/clean", "cleanQueue", "Remove completed or failed jobs older than a grace period", auth.RoleAdmin,
+			append(slices.Clone(queueParams),
+				apiParam{Name: "state", In: "query", Type: "string", Required: true, Enum: []string{"completed", "failed"}},
+				apiParam{Name: "grace", In: "query", Type: "string", Description: `Minimum age, e.g. "90m" or "7d".`},
+				apiParam{Name: "limit", In: "query", Type: "integer", Description: "Maximum number of jobs to remove."},
+				apiParam{Name: "dryrun", In: "query", Type: "boolean", Description: "Only count the matching jobs."},
+			), exp, auditLog, cleanQueueAction(exp)),
+		apiQueueActionRoute("/queues/{queue}/drain", "drainQueue", "Remove every waiting, paused, delayed and prioritized job", auth.RoleAdmin,
+			append(slices.Clone(queueParams), confirmParam), exp, auditLog, drainQueueAction(exp)),
+		apiQueueActionRoute("/queues/{queue}/obliterate", "obliterateQueue", "Delete a queue and all of its keys", auth.RoleAdmin,
+			append(slices.Clone(queueParams), confirmParam,
+				apiParam{Name: "force", In: "query", Type: "boolean", Description: "Obliterate even with active jobs."},
+			), exp, auditLog, obliterateQueueAction(exp)),
+		apiQueueActionRoute("/queues/{queue}/schedulers/remove", "removeJobScheduler", "Remove a job scheduler and its next delayed job", auth.RoleAdmin,
+			append(slices.Clone(queueParams),
+				apiParam{Name: "key", In: "query", Type: "string", Required: true},
+			), exp, auditLog, removeJobSchedulerAction(exp)),
+		apiJobActionRoute("/queues/{queue}/jobs/{id}/retry", "retryJob", "Retry a failed job", "Retried", jobParams, exp, auditLog, exp.RetryJob),
+		apiJobActionRoute("/queues/{queue}/jobs/{id}/remove", "removeJob", "Remove a job that is not active", "Removed", jobParams, exp, auditLog, exp.RemoveJob),
+		apiJobActionRoute("/queues/{queue}/jobs/{id}/promote", "promoteJob", "Promote a delayed job", "Promoted", jobParams, exp, auditLog, exp.PromoteJob),
+		{
+			Method: http.MethodGet, Path: "/queues/{queue}/bulk-retry", OperationID: "previewBulkRetry",
+			Summary: "Count the failed jobs a bulk retry would retry, with a sample",
+			Params:  bulkRetryParams, Response: apiBulkRetryPreview{},
+			handle: func(r *http.Request) (any, error) {
+				queue, matcher, err := apiBulkRetryRequest(r, exp)
+				if err != nil {
+					return nil, err
+				}
+				matches, err := collectRetryMatches(r.Context(), exp, queue, matcher, bulkRetryPreviewMaxScan, nil)
+				if err != nil {
+					return nil, err
+				}
+				return newAPIBulkRetryPreview(queue, matches), nil
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/queues/{queue}/bulk-retry", OperationID: "startBulkRetry",
+			Summary: "Scan for and retry every matching failed job in the background",
+			Params:  bulkRetryParams, Status: http.StatusAccepted, Response: BulkRetryProgress{},
+			Role: auth.RoleOperator,
+			handle: func(r *http.Request) (any, error) {
+				queue, matcher, err := apiBulkRetryRequest(r, exp)
+				if err != nil {
+					recordAction(r, auditLog, "startBulkRetry", queue, nil, actionResult{}, err)
+					return nil, err
+				}
+				filter := strings.TrimSpace(r.FormValue("q"))
+				regex, _ := strconv.ParseBool(r.FormValue("regex"))
+				progress, err := bulkRetries.Start(exp, queue, filter, regex, matcher, auditBulkRetry(r, auditLog, queue))
+				if err != nil {
+					recordAction(r, auditLog, "startBulkRetry", queue, nil, actionResult{}, err)
+					return nil, err
+				}
+				log.Printf("🔁 bulk retry %s started via api (prefix=%s queue=%s filter=%q regex=%t)", progress.ID, queue.Prefix, queue.Name, filter, regex)
+				return progress, nil
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/bulk-retries/{task}", OperationID: "getBulkRetry",
+			Summary:  "Get the progress of a bulk retry",
+			Params:   []apiParam{{Name: "task", In: "path", Type: "string", Required: true}},
+			Response: BulkRetryProgress{},
+			handle: func(r *http.Request) (any, error) {
+				progress, ok := bulkRetries.Get(r.PathValue("task"))
+				if !ok {
+					return nil, errBulkRetryTaskNotFound
+				}
+				if err := queueAccess(r, progress.Queue, auth.RoleViewer); err != nil {
+					return nil, err
+				}
+				return progress, nil
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/bulk-retries/{task}/cancel", OperationID: "cancelBulkRetry",
+			Summary:  "Stop a running bulk retry",
+			Params:   []apiParam{{Name: "task", In: "path", Type: "string", Required: true}},
+			Response: BulkRetryProgress{}, Role: auth.RoleOperator,
+			handle: func(r *http.Request) (any, error) {
+				return cancelBulkRetry(r, bulkRetries, auditLog, r.PathValue("task"))
+			},
+		},
+	}
+}
+
+func apiQueueActionRoute(path, operationID, summary string, role auth.Role, params []apiParam, exp *explorer.Explorer, auditLog *audit.Logger, action queueAction) apiRoute {
+	return apiRoute{
+		Method: http.MethodPost, Path: path, OperationID: operationID,
+		Summary: summary, Params: params, Response: actionResult{}, Role: role,
+		handle: func(r *http.Request) (any, error) {
+			queue, err := apiQueueRef(r, exp)
+			if err != nil {
+				recordAction(r, auditLog, operationID, queue, nil, actionResult{}, err)
+				return nil, err
+			}
+			result, err := action(r, queue)
+			recordAction(r, auditLog, operationID, queue, result.Jobs, result, err)
+			if err != nil {
+				return nil, err
+			}
+			return result, nil
+		},
+	}
+}
+
+func apiJobActionRoute(path, operationID, summary, successMessage string, params []apiParam, exp *explorer.Explorer, auditLog *audit.Logger, action jobAction) apiRoute {
+	return apiRoute{
+		Method: http.MethodPost, Path: path, OperationID: operationID,
+		Summary: summary, Params: params, Response: actionResult{}, Role: auth.RoleOperator,
+		handle: func(r *http.Request) (any, error) {
+			jobID := r.PathValue("id")
+			queue, err := apiQueueRef(r, exp)
+			if err != nil {
+				recordAction(r, auditLog, operationID, queue, []string{jobID}, actionResult{}, err)
+				return nil, err
+			}
+			result := actionResult{OK: true, Message: successMessage}
+			err = action(r.Context(), queue.Prefix, queue.Name, jobID)
+			recordAction(r, auditLog, operationID, queue, []string{jobID}, result, err)
+			if err != nil {
+				return nil, err
+			}
+			return result, nil
+		},
+	}
+}
+
+// apiQueueRef reads the queue from the path and resolves the prefix
+// parameter. On error the ref still names the queue and the prefix as
+// given, for the audit log.
+func apiQueueRef(r *http.Request, exp *explorer.Explorer) (explorer.QueueRef, error) {
+	queue := explorer.QueueRef{Prefix: strings.TrimSpace(r.FormValue("prefix")), Name: r.PathValue("queue")}
+	prefix, err := exp.ResolvePrefix(queue.Prefix)
+	if err != nil {
+		return queue, err
+	}
+	queue.Prefix = prefix
+	return queue, nil
+}
+
+func apiBulkRetryRequest(r *http.Request, exp *explorer.Explorer) (explorer.QueueRef, jobMatcher, error) {
+	queue, err := apiQueueRef(r, exp)
+	if err != nil {
+		return queue, jobMatcher{}, err
+	}
+	regex, _ := strconv.ParseBool(r.FormValue("regex"))
+	matcher, err := newJobMatcher(strings.TrimSpace(r.FormValue("q")), regex)
+	if err != nil {
+		return queue, jobMatcher{}, errBadActionRequest(err.Error())
+	}
+	return queue, matcher, nil
+}
+
+func newAPIQueue(stat explorer.QueueStats) apiQueue {
+	queue := apiQueue{
+		Prefix:   stat.Prefix,
+		Name:     stat.Name,
+		IsPaused: stat.IsPaused,
+		Counts: apiJobCounts{
+			Waiting:         stat.Wait,
+			Active:          stat.Active,
+			Paused:          stat.Paused,
+			Prioritized:     stat.Prioritized,
+			WaitingChildren: stat.WaitingChildren,
+			Completed:       stat.Completed,
+			Failed:          stat.Failed,
+			Delayed:         stat.Delayed,
+			Stalled:         stat.Stalled,
+			Total:           stat.Total,
+		},
+		Limits: apiQueueLimits{
+			GlobalConcurrency:   stat.Limits.GlobalConcurrency,
+			RateLimitMax:        stat.Limits.RateLimitMax,
+			RateLimitDurationMs: stat.Limits.RateLimitDuration.Milliseconds(),
+			LimiterCount:        stat.Limits.LimiterCount,
+			LimiterResetInMs:    stat.Limits.LimiterResetIn.Milliseconds(),
+			RateLimited:         stat.Limits.RateLimited,
+		},
+	}
+	if stat.ActiveUnlockedKnown {
+		unlocked := stat.ActiveUnlocked
+		queue.Counts.ActiveUnlocked = &unlocked
+	}
+	if stat.WorkersKnown {
+		workers := stat.Workers
+		queue.Workers = &workers
+	}
+	return queue
+}
+
+// newAPIBulkRetryPreview is the preview body for both /api/v1 and the
+// non-HTMX response of /queue/retry/preview.
+func newAPIBulkRetryPreview(queue explorer.QueueRef, matches bulkRetryMatches) apiBulkRetryPreview {
+	return apiBulkRetryPreview{
+		Prefix:      queue.Prefix,
+		Queue:       queue.Name,
+		Matched:     len(matches.JobIDs),
+		Scanned:     matches.Scanned,
+		Truncated:   matches.Truncated,
+		ScanLimited: matches.ScanLimited,
+		Sample:      newAPIJobSummaries(matches.Sample),
+	}
+}
+
+func newAPIJobSummaries(jobs []explorer.JobSummary) []apiJobSummary {
+	summaries := make([]apiJobSummary, 0, len(jobs))
+	for _, job := range jobs {
+		summary := apiJobSummary{
+			ID:           job.ID,
+			Name:         job.Name,
+			State:        job.State,
+			Prefix:       job.Prefix,
+			Queue:        job.Queue,
+			Timestamp:    job.Timestamp,
+			ProcessedOn:  job.ProcessedOn,
+			AttemptsMade: job.AttemptsMade,
+			Data:         rawJSON(job.Data),
+			Opts:         rawJSON(job.Opts),
+			FailedReason: job.FailedReason,
+		}
+		if job.Lock != nil {
+			summary.Lock = &apiJobLock{
+				Token:       job.Lock.Token,
+				ExpiresInMs: job.Lock.ExpiresIn.Milliseconds(),
+				Missing:     job.Lock.Missing(),
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// rawJSON embeds a stored JSON string as is, and quotes anything that is not
+// valid JSON so the response still parses.
+func rawJSON(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	quoted, _ := json.Marshal(s)
+	return quoted
+}
+
+func searchWindowValues() []string {
+	values := make([]string, 0, len(searchWindowOptions))
+	for _, option := range searchWindowOptions {
+		if option.Value != "" {
+			values = append(values, option.Value)
+		}
+	}
+	return values
+}
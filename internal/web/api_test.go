@@ -0,0 +1,164 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+func newTestAPIHandler() *APIHandler {
+	return NewAPIHandler(explorer.New(nil, "bull"), NewDashboardCache(), NewBulkRetryManager(), nil)
+}
+
+func decodeAPIError(t *testing.T, rec *httptest.ResponseRecorder) apiErrorDetail {
+	t.Helper()
+	var body apiError
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode error body: %v", err)
+	}
+	return body.Error
+}
+
+func TestAPIHandlerErrorsAreJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "unknown route", method: http.MethodGet, target: "/api/v1/nope", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "wrong method", method: http.MethodGet, target: "/api/v1/queues/orders/pause", wantStatus: http.StatusMethodNotAllowed, wantCode: "method_not_allowed"},
+		{name: "unknown prefix", method: http.MethodGet, target: "/api/v1/queues/orders?prefix=other", wantStatus: http.StatusBadRequest, wantCode: "bad_request"},
+		{name: "bad state", method: http.MethodGet, target: "/api/v1/queues/orders/jobs?state=sleeping", wantStatus: http.StatusBadRequest, wantCode: "bad_request"},
+		{name: "missing query", method: http.MethodGet, target: "/api/v1/queues/orders/search", wantStatus: http.StatusBadRequest, wantCode: "bad_request"},
+		{name: "drain without confirm", method: http.MethodPost, target: "/api/v1/queues/orders/drain", wantStatus: http.StatusBadRequest, wantCode: "bad_request"},
+		{name: "unknown bulk retry", method: http.MethodGet, target: "/api/v1/bulk-retries/42", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+	}
+
+	handler := newTestAPIHandler()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.target, nil))
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status mismatch: got %d want %d (%s)", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Fatalf("unexpected content type %q", got)
+			}
+			detail := decodeAPIError(t, rec)
+			if detail.Status != tc.wantStatus || detail.Code != tc.wantCode || detail.Message == "" {
+				t.Fatalf("unexpected error body: %+v", detail)
+			}
+		})
+	}
+}
+
+func TestAPIHandlerOpenAPIDocument(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestAPIHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status mismatch: got %d", rec.Code)
+	}
+
+	var doc openAPIDocument
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("decode document: %v", err)
+	}
+	if doc.Servers[0].URL != "/api/v1" {
+		t.Fatalf("unexpected server %q", doc.Servers[0].URL)
+	}
+	for _, route := range apiRoutes(nil, nil, nil, nil) {
+		if _, ok := doc.Paths[route.Path][strings.ToLower(route.Method)]; !ok {
+			t.Fatalf("document is missing %s %s", route.Method, route.Path)
+		}
+	}
+
+	job := doc.Components.Schemas["Job"]
+	if job == nil {
+		t.Fatal("expected a Job schema")
+	}
+	for _, name := range []string{"id", "data", "state", "queue", "prefix"} {
+		if _, ok := job.Properties[name]; !ok {
+			t.Fatalf("Job schema is missing %q", name)
+		}
+	}
+	if queue := doc.Components.Schemas["Queue"]; queue == nil || queue.Properties["counts"].Ref != "#/components/schemas/JobCounts" {
+		t.Fatalf("unexpected Queue schema: %+v", queue)
+	}
+}
+
+func TestOpenAPIDocumentServerFollowsEnvironment(t *testing.T) {
+	router := NewEnvironmentRouter()
+	router.Handle("prod", newTestAPIHandler())
+	router.Handle("staging", newTestAPIHandler())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/env/staging/api/v1/openapi.json", nil))
+
+	var doc openAPIDocument
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("decode document: %v", err)
+	}
+	if got, want := doc.Servers[0].URL, "/env/staging/api/v1"; got != want {
+		t.Fatalf("server mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestNormalizeAPIPath(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{path: "/api/v1/queues", want: "/api/v1/queues", wantOK: true},
+		{path: "/api/v1/queues/orders", want: "/api/v1/queues/{queue}", wantOK: true},
+		{path: "/api/v1/queues/orders/jobs/17", want: "/api/v1/queues/{queue}/jobs/{id}", wantOK: true},
+		{path: "/api/v1/queues/orders/jobs/17/retry", want: "/api/v1/queues/{queue}/jobs/{id}/retry", wantOK: true},
+		{path: "/api/v1/openapi.json", want: "/api/v1/openapi.json", wantOK: true},
+		{path: "/api/v1/queues//jobs", wantOK: false},
+		{path: "/queue/jobs", wantOK: false},
+	}
+
+	for _, tc := range tests {
+		got, ok := NormalizeAPIPath(tc.path)
+		if ok != tc.wantOK || got != tc.want {
+			t.Fatalf("NormalizeAPIPath(%q) = %q, %t; want %q, %t", tc.path, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestNewAPIJobSummariesEmbedsJSON(t *testing.T) {
+	jobs := newAPIJobSummaries([]explorer.JobSummary{{
+		ID:        "1",
+		Data:      `{"orderId":42}`,
+		Opts:      "not json",
+		Timestamp: time.UnixMilli(1700000000000),
+		Lock:      &explorer.JobLock{},
+	}})
+
+	encoded, err := json.Marshal(jobs[0])
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if data, ok := decoded["data"].(map[string]any); !ok || data["orderId"] != float64(42) {
+		t.Fatalf("expected data to be embedded as JSON, got %v", decoded["data"])
+	}
+	if decoded["opts"] != "not json" {
+		t.Fatalf("expected invalid opts to be quoted, got %v", decoded["opts"])
+	}
+	if lock, ok := decoded["lock"].(map[string]any); !ok || lock["missing"] != true {
+		t.Fatalf("expected a missing lock, got %v", decoded["lock"])
+	}
+}
@@ -0,0 +1,201 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/kofno/bullderdash/internal/audit"
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+// auditTargetParams are already on the event as its queue and jobs.
+var auditTargetParams = []string{"prefix", "queue", "id"}
+
+// recordAction writes the audit event for a state-changing request once its
+// outcome is known. err is the action's error, if any.
+func recordAction(r *http.Request, auditLog *audit.Logger, operation string, queue explorer.QueueRef, jobs []string, result actionResult, err error) {
+	if !auditLog.Enabled() {
+		return
+	}
+	auditLog.Record(r.Context(), newAuditEvent(r, operation, queue, jobs, result, err))
+}
+
+// newAuditEvent describes a request for the audit log. Actions that finish
+// in the background build it while the request is at hand and record it
+// later.
+func newAuditEvent(r *http.Request, operation string, queue explorer.QueueRef, jobs []string, result actionResult, err error) audit.Event {
+	event := audit.Event{
+		Actor:      "anonymous",
+		RemoteAddr: r.RemoteAddr,
+		Action:     operation,
+		Prefix:     queue.Prefix,
+		Queue:      queue.Name,
+		Jobs:       jobs,
+		Params:     auditParams(r),
+		Outcome:    audit.OutcomeSuccess,
+		Status:     http.StatusOK,
+		Message:    result.Message,
+	}
+	if id := auth.FromContext(r.Context()); id != nil {
+		event.Actor = id.Name
+		event.ActorSubject = id.Subject
+		event.AuthMethod = id.Method
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		if errors.Is(err, errForbidden) {
+			event.Outcome = audit.OutcomeDenied
+		}
+		event.Status = actionErrorStatus(err)
+		event.Message = err.Error()
+	}
+	return event
+}
+
+// auditParams are the request's options, such as a clean's state and grace
+// or a bulk retry's filter.
+func auditParams(r *http.Request) map[string]string {
+	if err := r.ParseForm(); err != nil {
+		return nil
+	}
+	params := make(map[string]string)
+	for name, values := range r.Form {
+		if slices.Contains(auditTargetParams, name) || len(values) == 0 {
+			continue
+		}
+		params[name] = strings.Join(values, ",")
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+const auditPageTmpl = `
+<div class="space-y-6">
+    <div>
+        <div class="text-sm uppercase tracking-wide text-gray-400">Audit log</div>
+        <div class="text-xl font-semibold text-indigo-700">Who changed what</div>
+        <div class="mt-1 text-sm text-gray-500">Every retry, removal, promotion, pause, clean, drain and obliterate, from the dashboard and the API, newest first.</div>
+    </div>
+
+    <form method="get" action="{{basePath}}/audit" class="flex flex-wrap items-end gap-3">
+        <label class="flex flex-col text-xs uppercase tracking-wide text-gray-400">
+            Search
+            <input type="text" name="q" value="{{.Data.Query.Text}}" placeholder="actor, action, job ID, parameter..." class="mt-1 w-80 rounded-md border border-gray-300 px-3 py-2 text-sm text-gray-800">
+        </label>
+        <label class="flex flex-col text-xs uppercase tracking-wide text-gray-400">
+            Queue
+            <input type="text" name="queue" value="{{.Data.Query.Queue}}" class="mt-1 w-48 rounded-md border border-gray-300 px-3 py-2 text-sm text-gray-800">
+        </label>
+        <label class="flex flex-col text-xs uppercase tracking-wide text-gray-400">
+            Outcome
+            <select name="outcome" class="mt-1 h-10 rounded-md border border-gray-300 px-3 py-2 text-sm text-gray-800">
+                <option value="">any</option>
+                {{range .Data.Outcomes}}<option value="{{.}}" {{if eq . $.Data.Query.Outcome}}selected{{end}}>{{.}}</option>{{end}}
+            </select>
+        </label>
+        <button type="submit" class="h-10 rounded-md bg-indigo-600 px-4 text-sm font-medium text-white hover:bg-indigo-700">Search</button>
+    </form>
+
+    {{if .Data.Events}}
+    <table class="min-w-full divide-y divide-gray-200 text-sm">
+        <thead class="bg-gray-50">
+            <tr>
+                <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Time (UTC)</th>
+                <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Actor</th>
+                <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Action</th>
+                <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Target</th>
+                <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Parameters</th>
+                <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Outcome</th>
+            </tr>
+        </thead>
+        <tbody class="divide-y divide-gray-200">
+            {{range .Data.Events}}
+            <tr class="align-top">
+                <td class="px-4 py-2 whitespace-nowrap text-gray-600">{{.Time.Format "2006-01-02 15:04:05"}}</td>
+                <td class="px-4 py-2 text-gray-900">{{.Actor}}{{if .AuthMethod}} <span class="text-xs text-gray-400">{{.AuthMethod}}</span>{{end}}{{if and .ActorSubject (ne .ActorSubject .Actor)}}<div class="text-xs text-gray-400 font-mono">{{.ActorSubject}}</div>{{end}}{{if .RemoteAddr}}<div class="text-xs text-gray-400">{{.RemoteAddr}}</div>{{end}}</td>
+                <td class="px-4 py-2 font-mono text-gray-900">{{.Action}}</td>
+                <td class="px-4 py-2 text-gray-700">
+                    <a href="{{basePath}}/queue/{{.Queue}}?prefix={{urlquery .Prefix}}" class="text-indigo-600 hover:text-indigo-900">{{.Queue}}</a>
+                    {{if .Prefix}}<span class="text-xs text-gray-400">{{.Prefix}}</span>{{end}}
+                    {{if eq (len .Jobs) 1}}
+                    <div class="font-mono text-xs"><a href="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery (index .Jobs 0)}}" class="hover:text-indigo-700">job {{index .Jobs 0}}</a></div>
+                    {{else if .JobCount}}
+                    <details class="text-xs">
+                        <summary class="cursor-pointer text-indigo-600">{{.JobCount}} jobs</summary>
+                        <div class="mt-1 max-w-xs break-words font-mono text-gray-600">{{range .Jobs}}{{.}} {{end}}{{if gt .JobCount (len .Jobs)}}…{{end}}</div>
+                    </details>
+                    {{end}}
+                </td>
+                <td class="px-4 py-2 font-mono text-xs text-gray-600">{{range $name, $value := .Params}}<div>{{$name}}={{$value}}</div>{{end}}</td>
+                <td class="px-4 py-2">
+                    <span class="rounded-full px-2 py-0.5 text-xs font-semibold {{if eq .Outcome "success"}}bg-green-100 text-green-800{{else if eq .Outcome "denied"}}bg-amber-100 text-amber-800{{else}}bg-red-100 text-red-800{{end}}">{{.Outcome}}</span>
+                    {{if .Message}}<div class="mt-1 max-w-xs text-xs text-gray-500">{{.Message}}</div>{{end}}
+                </td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+    {{else}}
+    <div class="rounded-md border border-dashed border-gray-300 p-6 text-center text-sm text-gray-500">
+        {{if .Data.Enabled}}No audit events match.{{else}}Audit logging is off (AUDIT_SINK=none).{{end}}
+    </div>
+    {{end}}
+</div>
+`
+
+type auditPageData struct {
+	Query    audit.Query
+	Outcomes []string
+	Events   []audit.Event
+	Enabled  bool
+}
+
+// AuditHandler shows the audit log with a search form, or JSON with
+// format=json. Events on queues the request may not view are left out.
+func AuditHandler(auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := audit.Query{
+			Text:    strings.TrimSpace(r.URL.Query().Get("q")),
+			Queue:   strings.TrimSpace(r.URL.Query().Get("queue")),
+			Outcome: strings.TrimSpace(r.URL.Query().Get("outcome")),
+			Limit:   min(parsePositiveInt(r.URL.Query().Get("limit"), audit.DefaultLimit), 1000),
+		}
+
+		events, err := auditLog.Search(r.Context(), query)
+		if err != nil {
+			log.Printf("❌ audit search error: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		visible := make([]audit.Event, 0, len(events))
+		for _, event := range events {
+			if auth.Allowed(r.Context(), event.Queue, auth.RoleViewer) {
+				visible = append(visible, event)
+			}
+		}
+
+		if wantsJSON(r) {
+			writeJSON(w, http.StatusOK, struct {
+				Events []audit.Event `json:"events"`
+			}{visible})
+			return
+		}
+
+		data := auditPageData{
+			Query:    query,
+			Outcomes: []string{audit.OutcomeSuccess, audit.OutcomeFailure, audit.OutcomeDenied},
+			Events:   visible,
+			Enabled:  auditLog.Enabled(),
+		}
+		if err := renderShell(w, r, "Bull-der-dash - Audit log", "Audit log", auditPageTmpl, data); err != nil {
+			log.Printf("❌ renderShell error (audit): %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kofno/bullderdash/internal/audit"
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+func TestActionsAreAudited(t *testing.T) {
+	sink := audit.NewWriterSink(&bytes.Buffer{}, 10)
+	auditLog := audit.New(sink, "default")
+	exp := explorer.New(nil, "bull")
+	grants := []string{"user:basic:alice viewer", "user:basic:root admin"}
+
+	// Denied from the dashboard, then refused for a missing confirmation by
+	// the API.
+	rec := httptest.NewRecorder()
+	DrainQueueHandler(exp, auditLog)(rec, asUser(t, httptest.NewRequest(http.MethodPost, "/queue/drain?queue=orders", nil), "alice", grants...))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("dashboard status = %d, want 403", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	NewAPIHandler(exp, NewDashboardCache(), NewBulkRetryManager(), auditLog).
+		ServeHTTP(rec, asUser(t, httptest.NewRequest(http.MethodPost, "/api/v1/queues/orders/drain?confirm=nope", nil), "root", grants...))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("api status = %d, want 400 (%s)", rec.Code, rec.Body.String())
+	}
+
+	events, err := sink.Search(context.Background(), audit.Query{})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	failed, denied := events[0], events[1]
+	if denied.Actor != "alice" || denied.Action != "drainQueue" || denied.Queue != "orders" || denied.Outcome != audit.OutcomeDenied || denied.Status != http.StatusForbidden {
+		t.Fatalf("denied event = %+v", denied)
+	}
+	if failed.Actor != "root" || failed.Prefix != "bull" || failed.Outcome != audit.OutcomeFailure || failed.Status != http.StatusBadRequest || failed.Params["confirm"] != "nope" {
+		t.Fatalf("failed event = %+v", failed)
+	}
+	if failed.ActorSubject != "root" {
+		t.Fatalf("failed event does not identify the actor: %+v", failed)
+	}
+}
+
+func TestRequestsRefusedBeforeTheActionAreAudited(t *testing.T) {
+	sink := audit.NewWriterSink(&bytes.Buffer{}, 10)
+	auditLog := audit.New(sink, "default")
+	exp := explorer.New(nil, "bull")
+	grants := []string{"user:basic:root admin"}
+	api := NewAPIHandler(exp, NewDashboardCache(), NewBulkRetryManager(), auditLog)
+
+	requests := []struct {
+		handler http.Handler
+		target  string
+		action  string
+	}{
+		{handler: DrainQueueHandler(exp, auditLog), target: "/queue/drain?queue=orders&prefix=nope", action: "drainQueue"},
+		{handler: RetryJobHandler(exp, auditLog), target: "/job/retry?queue=orders", action: "retryJob"},
+		{handler: BulkRetryHandler(exp, NewBulkRetryManager(), auditLog), target: "/queue/retry?queue=orders&q=(&regex=true", action: "startBulkRetry"},
+		{handler: BulkRetryCancelHandler(NewBulkRetryManager(), auditLog), target: "/queue/retry/cancel?task=7", action: "cancelBulkRetry"},
+		{handler: api, target: "/api/v1/queues/orders/drain?prefix=nope&confirm=orders", action: "drainQueue"},
+		{handler: api, target: "/api/v1/queues/orders/jobs/42/retry?prefix=nope", action: "retryJob"},
+	}
+	for _, req := range requests {
+		rec := httptest.NewRecorder()
+		req.handler.ServeHTTP(rec, asUser(t, httptest.NewRequest(http.MethodPost, req.target, nil), "root", grants...))
+		if rec.Code < 400 {
+			t.Fatalf("%s: status = %d, want an error", req.target, rec.Code)
+		}
+	}
+
+	events, err := sink.Search(context.Background(), audit.Query{Outcome: audit.OutcomeFailure})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(events) != len(requests) {
+		t.Fatalf("got %d failure events, want %d: %+v", len(events), len(requests), events)
+	}
+	for i, event := range events {
+		// Newest first.
+		if want := requests[len(requests)-1-i].action; event.Action != want || event.Actor != "root" {
+			t.Fatalf("event %d = %+v, want action %s", i, event, want)
+		}
+	}
+}
+
+func TestQueueActionsAuditTheJobsTheyChanged(t *testing.T) {
+	sink := audit.NewWriterSink(&bytes.Buffer{}, 10)
+	auditLog := audit.New(sink, "default")
+	exp := explorer.New(nil, "bull")
+	grants := []string{"user:basic:root admin"}
+
+	drained := queueActionHandler("drainQueue", auth.RoleAdmin, exp, auditLog, func(*http.Request, explorer.QueueRef) (actionResult, error) {
+		return jobsResult("Drained 2 jobs", []string{"7", "9"}), nil
+	})
+	rec := httptest.NewRecorder()
+	drained(rec, asUser(t, httptest.NewRequest(http.MethodPost, "/queue/drain?queue=orders&confirm=orders", nil), "root", grants...))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s)", rec.Code, rec.Body.String())
+	}
+	retried := auditBulkRetry(asUser(t, httptest.NewRequest(http.MethodPost, "/queue/retry?queue=orders", nil), "root", grants...),
+		auditLog, explorer.QueueRef{Prefix: "bull", Name: "orders"})
+	retried(BulkRetryProgress{ID: "3", Total: 3, Retried: 2, Skipped: 1, Done: true}, []string{"4", "5"}, nil)
+
+	events, err := sink.Search(context.Background(), audit.Query{})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	bulk, drain := events[0], events[1]
+	if drain.Action != "drainQueue" || strings.Join(drain.Jobs, ",") != "7,9" || drain.JobCount != 2 {
+		t.Fatalf("drain event = %+v", drain)
+	}
+	if bulk.Action != "startBulkRetry" || strings.Join(bulk.Jobs, ",") != "4,5" || bulk.JobCount != 2 || bulk.Outcome != audit.OutcomeSuccess {
+		t.Fatalf("bulk retry event = %+v", bulk)
+	}
+}
+
+func TestAuditHandlerHidesQueuesTheUserCannotView(t *testing.T) {
+	auditLog := audit.New(audit.NewWriterSink(&bytes.Buffer{}, 10), "default")
+	for _, queue := range []string{"orders", "payments"} {
+		auditLog.Record(context.Background(), audit.Event{Actor: "root", Action: "pauseQueue", Queue: queue, Outcome: audit.OutcomeSuccess})
+	}
+
+	rec := httptest.NewRecorder()
+	req := asUser(t, httptest.NewRequest(http.MethodGet, "/audit?format=json", nil), "alice", "user:basic:alice viewer orders")
+	AuditHandler(auditLog)(rec, req)
+
+	var body struct {
+		Events []audit.Event `json:"events"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Events) != 1 || body.Events[0].Queue != "orders" {
+		t.Fatalf("events = %+v", body.Events)
+	}
+}
+
+func TestAuditHandlerRendersPage(t *testing.T) {
+	auditLog := audit.New(audit.NewWriterSink(&bytes.Buffer{}, 10), "default")
+	auditLog.Record(context.Background(), audit.Event{Actor: "root", Action: "retryJob", Queue: "orders", Jobs: []string{"7"}, Outcome: audit.OutcomeSuccess})
+
+	rec := httptest.NewRecorder()
+	AuditHandler(auditLog)(rec, httptest.NewRequest(http.MethodGet, "/audit?q=retry", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s)", rec.Code, rec.Body.String())
+	}
+	for _, want := range []string{"retryJob", "job 7", `value="retry"`} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("page missing %q", want)
+		}
+	}
+}
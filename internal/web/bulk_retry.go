@@ -0,0 +1,613 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/audit"
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+const (
+	bulkRetryScanPageSize = 500
+	bulkRetryMaxJobs      = 50000
+	bulkRetrySampleSize   = 10
+	bulkRetryBatchSize    = 100
+	bulkRetryBatchPause   = 250 * time.Millisecond
+	bulkRetryKeepTasks    = 20
+	// bulkRetryPreviewMaxScan bounds the failed jobs a preview reads, so it
+	// answers within the request's write timeout. The retry itself scans the
+	// whole set in the background.
+	bulkRetryPreviewMaxScan = 10000
+)
+
+var errBulkRetryTaskNotFound = errors.New("bulk retry task not found")
+
+type bulkRetryExplorer interface {
+	GetJobsByStatePage(ctx context.Context, queuePrefix, queueName, state string, offset, limit int) ([]explorer.JobSummary, error)
+	RetryJob(ctx context.Context, queuePrefix, queueName, jobID string) error
+}
+
+// bulkRetryMatches is the result of scanning a queue's failed set with a filter.
+type bulkRetryMatches struct {
+	JobIDs    []string
+	Sample    []explorer.JobSummary
+	Scanned   int
+	Truncated bool
+	// ScanLimited is set when the scan stopped at its limit with failed jobs
+	// left unread, so more jobs may match than were counted.
+	ScanLimited bool
+}
+
+// BulkRetryProgress is a snapshot of a background bulk retry.
+type BulkRetryProgress struct {
+	ID     string `json:"id"`
+	Prefix string `json:"prefix"`
+	Queue  string `json:"queue"`
+	Filter string `json:"filter"`
+	Regex  bool   `json:"regex"`
+	// Scanning is set while the task is still reading the failed set; Total
+	// is known once it finishes.
+	Scanning   bool      `json:"scanning"`
+	Scanned    int       `json:"scanned"`
+	Truncated  bool      `json:"truncated"`
+	Total      int       `json:"total"`
+	Processed  int       `json:"processed"`
+	Retried    int       `json:"retried"`
+	Skipped    int       `json:"skipped"`
+	Errors     int       `json:"errors"`
+	LastError  string    `json:"lastError,omitempty"`
+	Cancelled  bool      `json:"cancelled"`
+	Done       bool      `json:"done"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt,omitzero"`
+}
+
+// Percent reports how much of the task has been processed.
+func (p BulkRetryProgress) Percent() int {
+	if p.Scanning {
+		return 0
+	}
+	if p.Total == 0 {
+		return 100
+	}
+	return p.Processed * 100 / p.Total
+}
+
+// BulkRetryManager runs bulk retries in the background and keeps their
+// progress in memory so the UI can poll it. Tasks run under the manager's
+// context, so Close stops them all.
+type BulkRetryManager struct {
+	ctx        context.Context
+	stop       context.CancelFunc
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+	tasks      map[string]*BulkRetryProgress
+	cancels    map[string]context.CancelFunc
+	order      []string
+	nextID     int
+	batchSize  int
+	batchPause time.Duration
+}
+
+func NewBulkRetryManager() *BulkRetryManager {
+	ctx, stop := context.WithCancel(context.Background())
+	return &BulkRetryManager{
+		ctx:        ctx,
+		stop:       stop,
+		tasks:      make(map[string]*BulkRetryProgress),
+		cancels:    make(map[string]context.CancelFunc),
+		batchSize:  bulkRetryBatchSize,
+		batchPause: bulkRetryBatchPause,
+	}
+}
+
+// Get returns a copy of a task's progress.
+func (m *BulkRetryManager) Get(id string) (BulkRetryProgress, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok {
+		return BulkRetryProgress{}, false
+	}
+	return *task, true
+}
+
+// bulkRetryFinished is told how a task ended: the job IDs it retried, and the
+// error that ended its scan, if any.
+type bulkRetryFinished func(progress BulkRetryProgress, retried []string, err error)
+
+// Start records a new task and, on a background goroutine, scans the failed
+// set for jobs matching matcher and retries them in throttled batches. Only
+// one bulk retry may run per queue at a time. finished, if set, is called once
+// the task is done, including when it was cancelled.
+func (m *BulkRetryManager) Start(exp bulkRetryExplorer, queue explorer.QueueRef, filter string, regex bool, matcher jobMatcher, finished bulkRetryFinished) (BulkRetryProgress, error) {
+	m.mu.Lock()
+	if err := m.ctx.Err(); err != nil {
+		m.mu.Unlock()
+		return BulkRetryProgress{}, fmt.Errorf("bulk retries are shutting down: %w", err)
+	}
+	for _, task := range m.tasks {
+		if task.Prefix == queue.Prefix && task.Queue == queue.Name && !task.Done {
+			m.mu.Unlock()
+			return BulkRetryProgress{}, errBadActionRequest(fmt.Sprintf("a bulk retry is already running for %s (task %s)", queue.Name, task.ID))
+		}
+	}
+
+	m.nextID++
+	task := &BulkRetryProgress{
+		ID:        strconv.Itoa(m.nextID),
+		Prefix:    queue.Prefix,
+		Queue:     queue.Name,
+		Filter:    filter,
+		Regex:     regex,
+		Scanning:  true,
+		StartedAt: time.Now(),
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.tasks[task.ID] = task
+	m.cancels[task.ID] = cancel
+	m.order = append(m.order, task.ID)
+	m.evictLocked()
+	snapshot := *task
+	m.wg.Add(1)
+	m.mu.Unlock()
+
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		m.run(ctx, exp, task.ID, queue, matcher, finished)
+	}()
+	return snapshot, nil
+}
+
+// Cancel stops a running task after the job it is retrying.
+func (m *BulkRetryManager) Cancel(id string) (BulkRetryProgress, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok {
+		return BulkRetryProgress{}, errBulkRetryTaskNotFound
+	}
+	if task.Done {
+		return *task, errBadActionRequest(fmt.Sprintf("bulk retry %s has already finished", id))
+	}
+	m.cancels[id]()
+	task.Cancelled = true
+	return *task, nil
+}
+
+// Close cancels every running task and waits for them to stop.
+func (m *BulkRetryManager) Close() {
+	m.stop()
+	m.wg.Wait()
+}
+
+func (m *BulkRetryManager) run(ctx context.Context, exp bulkRetryExplorer, taskID string, queue explorer.QueueRef, matcher jobMatcher, finished bulkRetryFinished) {
+	var retried []string
+	matches, err := collectRetryMatches(ctx, exp, queue, matcher, 0, func(n int) {
+		m.update(taskID, func(task *BulkRetryProgress) { task.Scanned = n })
+	})
+	defer func() {
+		progress := m.finish(ctx, taskID)
+		if finished != nil {
+			finished(progress, retried, err)
+		}
+	}()
+	m.update(taskID, func(task *BulkRetryProgress) {
+		task.Scanning = false
+		task.Scanned = matches.Scanned
+		task.Truncated = matches.Truncated
+		task.Total = len(matches.JobIDs)
+		if err != nil && ctx.Err() == nil {
+			task.Errors++
+			task.LastError = err.Error()
+		}
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("❌ bulk retry %s scan error (prefix=%s queue=%s): %v", taskID, queue.Prefix, queue.Name, err)
+		}
+		return
+	}
+
+	for i, jobID := range matches.JobIDs {
+		if i > 0 && i%m.batchSize == 0 && !sleepOrDone(ctx, m.batchPause) {
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		err := exp.RetryJob(ctx, queue.Prefix, queue.Name, jobID)
+		if err != nil && ctx.Err() != nil {
+			break
+		}
+		if err == nil {
+			retried = append(retried, jobID)
+		}
+		m.update(taskID, func(task *BulkRetryProgress) {
+			task.Processed++
+			switch {
+			case err == nil:
+				task.Retried++
+			case errors.Is(err, explorer.ErrJobNotFound), errors.Is(err, explorer.ErrJobWrongState):
+				// Removed or already retried since the preview; nothing to do.
+				task.Skipped++
+			default:
+				task.Errors++
+				task.LastError = err.Error()
+			}
+		})
+	}
+}
+
+func (m *BulkRetryManager) finish(ctx context.Context, taskID string) BulkRetryProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.cancels, taskID)
+	task, ok := m.tasks[taskID]
+	if !ok {
+		return BulkRetryProgress{ID: taskID}
+	}
+	task.Done = true
+	task.Cancelled = ctx.Err() != nil
+	task.FinishedAt = time.Now()
+	log.Printf("🔁 bulk retry %s finished (prefix=%s queue=%s retried=%d skipped=%d errors=%d cancelled=%t)", task.ID, task.Prefix, task.Queue, task.Retried, task.Skipped, task.Errors, task.Cancelled)
+	return *task
+}
+
+func (m *BulkRetryManager) update(id string, fn func(task *BulkRetryProgress)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if task, ok := m.tasks[id]; ok {
+		fn(task)
+	}
+}
+
+// evictLocked drops the oldest finished tasks beyond the retention limit.
+func (m *BulkRetryManager) evictLocked() {
+	for len(m.order) > bulkRetryKeepTasks {
+		evicted := false
+		for i, id := range m.order {
+			if m.tasks[id].Done {
+				delete(m.tasks, id)
+				m.order = append(m.order[:i], m.order[i+1:]...)
+				evicted = true
+				break
+			}
+		}
+		if !evicted {
+			return
+		}
+	}
+}
+
+// sleepOrDone waits for d, reporting false if ctx ends first.
+func sleepOrDone(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// collectRetryMatches pages through the failed set and returns the IDs whose
+// name or failed reason match. Scanning stops at bulkRetryMaxJobs matches, or
+// after maxScan jobs when it is positive. onPage, if set, is told the running
+// scan count after each page.
+func collectRetryMatches(ctx context.Context, exp bulkRetryExplorer, queue explorer.QueueRef, matcher jobMatcher, maxScan int, onPage func(scanned int)) (bulkRetryMatches, error) {
+	var result bulkRetryMatches
+	for offset := 0; ; offset += bulkRetryScanPageSize {
+		limit := bulkRetryScanPageSize
+		if maxScan > 0 {
+			limit = min(limit, maxScan-result.Scanned)
+		}
+		jobs, err := exp.GetJobsByStatePage(ctx, queue.Prefix, queue.Name, "failed", offset, limit)
+		if err != nil {
+			return result, err
+		}
+		result.Scanned += len(jobs)
+		if onPage != nil {
+			onPage(result.Scanned)
+		}
+
+		for _, job := range jobs {
+			if !matchesRetryFilter(job, matcher) {
+				continue
+			}
+			if len(result.JobIDs) >= bulkRetryMaxJobs {
+				result.Truncated = true
+				return result, nil
+			}
+			result.JobIDs = append(result.JobIDs, job.ID)
+			if len(result.Sample) < bulkRetrySampleSize {
+				result.Sample = append(result.Sample, job)
+			}
+		}
+
+		if len(jobs) < limit {
+			return result, nil
+		}
+		if maxScan > 0 && result.Scanned >= maxScan {
+			result.ScanLimited = true
+			return result, nil
+		}
+	}
+}
+
+type bulkRetryPreviewData struct {
+	Prefix    string
+	Queue     string
+	Filter    string
+	Regex     bool
+	Matches   bulkRetryMatches
+	MaxJobs   int
+	MaxScan   int
+	BatchSize int
+}
+
+const bulkRetryPreviewTmpl = `
+<div class="mt-3 space-y-3">
+    <div class="text-sm text-gray-700">
+        <span class="font-semibold">{{len .Matches.JobIDs}}</span> of {{.Matches.Scanned}} failed jobs match
+        {{if .Filter}}{{if .Regex}}regex{{else}}substring{{end}} <code class="rounded bg-gray-100 px-1">{{.Filter}}</code>{{else}}(no filter){{end}}
+        {{if .Matches.Truncated}}<span class="text-amber-700">— capped at {{.MaxJobs}} jobs per run</span>{{end}}
+        {{if .Matches.ScanLimited}}<span class="text-amber-700">— the preview reads the first {{.MaxScan}} failed jobs; the retry scans them all</span>{{end}}
+    </div>
+    {{if .Matches.Sample}}
+    <table class="min-w-full divide-y divide-gray-200 text-sm">
+        <thead class="bg-gray-50">
+            <tr>
+                <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Job ID</th>
+                <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Name</th>
+                <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Failed Reason</th>
+            </tr>
+        </thead>
+        <tbody class="divide-y divide-gray-200">
+            {{range .Matches.Sample}}
+            <tr>
+                <td class="px-4 py-2 font-mono text-gray-600"><a href="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}" class="hover:text-indigo-700" target="_blank">{{.ID}}</a></td>
+                <td class="px-4 py-2 text-gray-900">{{.Name}}</td>
+                <td class="px-4 py-2 text-red-700 truncate max-w-md">{{.FailedReason}}</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+    {{if can "operator" .Queue}}
+    <button
+        hx-post="{{basePath}}/queue/retry?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&q={{urlquery .Filter}}&regex={{.Regex}}"
+        hx-confirm="Retry {{if .Matches.ScanLimited}}at least {{end}}{{len .Matches.JobIDs}} failed jobs in {{.Queue}}?"
+        hx-target="#bulk-retry-panel"
+        class="h-9 rounded-md bg-amber-600 px-4 text-sm font-medium text-white hover:bg-amber-700"
+    >
+        Retry {{if .Matches.ScanLimited}}all matching{{else}}{{len .Matches.JobIDs}}{{end}} jobs (batches of {{.BatchSize}})
+    </button>
+    {{end}}
+    {{end}}
+</div>
+`
+
+const bulkRetryProgressTmpl = `
+<div class="mt-3 space-y-2" data-bulk-retry {{if not .Done}}hx-get="{{basePath}}/queue/retry/status?task={{.ID}}" hx-trigger="every 1s" hx-swap="outerHTML"{{end}}>
+    <div class="flex items-center justify-between text-sm text-gray-700">
+        <span>Bulk retry #{{.ID}} on <span class="font-semibold">{{.Queue}}</span> <span class="text-xs text-gray-400">{{.Prefix}}</span>{{if .Done}} — {{if .Cancelled}}cancelled{{else}}finished{{end}}{{else if .Cancelled}} — cancelling{{end}}</span>
+        <span class="flex items-center gap-3">
+            {{if .Scanning}}scanning… {{.Scanned}} failed jobs read{{else}}{{.Processed}} / {{.Total}}{{end}}
+            {{if and (not .Done) (not .Cancelled) (can "operator" .Queue)}}
+            <button
+                hx-post="{{basePath}}/queue/retry/cancel?task={{.ID}}"
+                hx-confirm="Stop bulk retry #{{.ID}}? Jobs already retried stay retried."
+                hx-target="closest [data-bulk-retry]"
+                hx-swap="outerHTML"
+                class="rounded-md border border-gray-300 px-2 py-0.5 text-xs text-gray-700 hover:bg-gray-50"
+            >Cancel</button>
+            {{end}}
+        </span>
+    </div>
+    <div class="h-2 w-full rounded-full bg-gray-100">
+        <div class="h-2 rounded-full {{if .Errors}}bg-red-500{{else}}bg-amber-500{{end}}" data-percent="{{.Percent}}"></div>
+    </div>
+    <div class="text-xs text-gray-500">
+        Retried {{.Retried}} · Skipped {{.Skipped}} (no longer failed) · Errors {{.Errors}}
+        {{if .Truncated}}<span class="text-amber-700">— capped at the first matching {{.Total}} jobs</span>{{end}}
+        {{if .LastError}}<span class="text-red-700">— last error: {{.LastError}}</span>{{end}}
+    </div>
+</div>
+`
+
+var (
+	bulkRetryPreviewTemplate  = template.Must(newTemplate("bulk-retry-preview").Parse(bulkRetryPreviewTmpl))
+	bulkRetryProgressTemplate = template.Must(newTemplate("bulk-retry-progress").Parse(bulkRetryProgressTmpl))
+)
+
+// BulkRetryPreviewHandler shows how many failed jobs match a filter, with a
+// sample, before anything is retried.
+func BulkRetryPreviewHandler(exp *explorer.Explorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		queue, filter, regex, matcher, err := parseBulkRetryRequest(r, exp)
+		if err != nil {
+			writeActionError(w, r, err)
+			return
+		}
+		if !requireQueueRole(w, r, queue.Name, auth.RoleViewer) {
+			return
+		}
+
+		matches, err := collectRetryMatches(r.Context(), exp, queue, matcher, bulkRetryPreviewMaxScan, nil)
+		if err != nil {
+			log.Printf("❌ bulk retry preview error (prefix=%s queue=%s): %v", queue.Prefix, queue.Name, err)
+			writeActionError(w, r, err)
+			return
+		}
+
+		if r.Header.Get("HX-Request") == "" {
+			writeJSON(w, http.StatusOK, newAPIBulkRetryPreview(queue, matches))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := executeTemplate(w, r, bulkRetryPreviewTemplate, bulkRetryPreviewData{
+			Prefix:    queue.Prefix,
+			Queue:     queue.Name,
+			Filter:    filter,
+			Regex:     regex,
+			Matches:   matches,
+			MaxJobs:   bulkRetryMaxJobs,
+			MaxScan:   bulkRetryPreviewMaxScan,
+			BatchSize: bulkRetryBatchSize,
+		}); err != nil {
+			log.Printf("❌ Template execution error (bulk retry preview): %v", err)
+		}
+	}
+}
+
+// BulkRetryHandler starts a background task that re-scans the failed set with
+// the previewed filter and retries every match.
+func BulkRetryHandler(exp *explorer.Explorer, manager *BulkRetryManager, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requirePost(w, r) {
+			return
+		}
+		queue, filter, regex, matcher, err := parseBulkRetryRequest(r, exp)
+		if err == nil {
+			err = queueAccess(r, queue.Name, auth.RoleOperator)
+		}
+		if err != nil {
+			recordAction(r, auditLog, "startBulkRetry", queue, nil, actionResult{}, err)
+			writeActionError(w, r, err)
+			return
+		}
+
+		progress, err := manager.Start(exp, queue, filter, regex, matcher, auditBulkRetry(r, auditLog, queue))
+		if err != nil {
+			recordAction(r, auditLog, "startBulkRetry", queue, nil, actionResult{}, err)
+			writeActionError(w, r, err)
+			return
+		}
+		log.Printf("🔁 bulk retry %s started (prefix=%s queue=%s filter=%q regex=%t)", progress.ID, queue.Prefix, queue.Name, filter, regex)
+		writeBulkRetryProgress(w, r, http.StatusAccepted, progress)
+	}
+}
+
+// BulkRetryCancelHandler stops a running bulk retry.
+func BulkRetryCancelHandler(manager *BulkRetryManager, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requirePost(w, r) {
+			return
+		}
+		progress, err := cancelBulkRetry(r, manager, auditLog, r.FormValue("task"))
+		if err != nil {
+			writeActionError(w, r, err)
+			return
+		}
+		writeBulkRetryProgress(w, r, http.StatusOK, progress)
+	}
+}
+
+// BulkRetryStatusHandler reports the progress of a bulk retry task.
+func BulkRetryStatusHandler(manager *BulkRetryManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		progress, ok := manager.Get(r.URL.Query().Get("task"))
+		if !ok {
+			http.Error(w, errBulkRetryTaskNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		if !requireQueueRole(w, r, progress.Queue, auth.RoleViewer) {
+			return
+		}
+		writeBulkRetryProgress(w, r, http.StatusOK, progress)
+	}
+}
+
+// parseBulkRetryRequest reads a bulk retry's queue and filter. On error the
+// queue still holds what the request named, for the audit log.
+func parseBulkRetryRequest(r *http.Request, exp *explorer.Explorer) (queue explorer.QueueRef, filter string, regex bool, matcher jobMatcher, err error) {
+	queue = explorer.QueueRef{Prefix: strings.TrimSpace(r.FormValue("prefix")), Name: strings.TrimSpace(r.FormValue("queue"))}
+	if queue.Name == "" {
+		return queue, "", false, jobMatcher{}, errBadActionRequest("queue parameter required")
+	}
+	prefix, err := exp.ResolvePrefix(queue.Prefix)
+	if err != nil {
+		return queue, "", false, jobMatcher{}, err
+	}
+	queue.Prefix = prefix
+	filter = strings.TrimSpace(r.FormValue("q"))
+	regex, _ = strconv.ParseBool(r.FormValue("regex"))
+
+	matcher, err = newJobMatcher(filter, regex)
+	if err != nil {
+		return queue, "", false, jobMatcher{}, errBadActionRequest(err.Error())
+	}
+	return queue, filter, regex, matcher, nil
+}
+
+// auditBulkRetry records startBulkRetry once the task is done, with the jobs
+// it retried. The event is described while the request is at hand; the task
+// finishes after the response.
+func auditBulkRetry(r *http.Request, auditLog *audit.Logger, queue explorer.QueueRef) bulkRetryFinished {
+	if !auditLog.Enabled() {
+		return nil
+	}
+	event := newAuditEvent(r, "startBulkRetry", queue, nil, actionResult{}, nil)
+	return func(progress BulkRetryProgress, retried []string, err error) {
+		event := event
+		event.Jobs = retried
+		event.Message = fmt.Sprintf("Bulk retry %s retried %d of %d matching jobs (skipped=%d errors=%d cancelled=%t)",
+			progress.ID, len(retried), progress.Total, progress.Skipped, progress.Errors, progress.Cancelled)
+		if err != nil && !progress.Cancelled {
+			event.Outcome = audit.OutcomeFailure
+			event.Status = actionErrorStatus(err)
+			event.Message = fmt.Sprintf("Bulk retry %s scan failed: %v", progress.ID, err)
+		}
+		auditLog.Record(context.Background(), event)
+	}
+}
+
+// cancelBulkRetry stops a task on behalf of an operator of its queue.
+func cancelBulkRetry(r *http.Request, manager *BulkRetryManager, auditLog *audit.Logger, id string) (BulkRetryProgress, error) {
+	progress, ok := manager.Get(id)
+	if !ok {
+		err := fmt.Errorf("%w: %q", errBulkRetryTaskNotFound, id)
+		recordAction(r, auditLog, "cancelBulkRetry", explorer.QueueRef{}, nil, actionResult{}, err)
+		return BulkRetryProgress{}, err
+	}
+	queue := explorer.QueueRef{Prefix: progress.Prefix, Name: progress.Queue}
+	if err := queueAccess(r, queue.Name, auth.RoleOperator); err != nil {
+		recordAction(r, auditLog, "cancelBulkRetry", queue, nil, actionResult{}, err)
+		return BulkRetryProgress{}, err
+	}
+	progress, err := manager.Cancel(id)
+	recordAction(r, auditLog, "cancelBulkRetry", queue, nil, actionResult{OK: true, Message: fmt.Sprintf("Cancelled bulk retry %s", id)}, err)
+	if err != nil {
+		return BulkRetryProgress{}, err
+	}
+	log.Printf("🛑 bulk retry %s cancelled (prefix=%s queue=%s processed=%d)", id, queue.Prefix, queue.Name, progress.Processed)
+	return progress, nil
+}
+
+func writeBulkRetryProgress(w http.ResponseWriter, r *http.Request, status int, progress BulkRetryProgress) {
+	if r.Header.Get("HX-Request") == "" {
+		writeJSON(w, status, progress)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := executeTemplate(w, r, bulkRetryProgressTemplate, progress); err != nil {
+		log.Printf("❌ Template execution error (bulk retry progress): %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+type stubBulkRetryExplorer struct {
+	mu      sync.Mutex
+	failed  []explorer.JobSummary
+	retried []string
+	errs    map[string]error
+}
+
+func (s *stubBulkRetryExplorer) GetJobsByStatePage(ctx context.Context, queuePrefix, queueName, state string, offset, limit int) ([]explorer.JobSummary, error) {
+	if offset >= len(s.failed) {
+		return nil, nil
+	}
+	end := min(offset+limit, len(s.failed))
+	return append([]explorer.JobSummary(nil), s.failed[offset:end]...), nil
+}
+
+func (s *stubBulkRetryExplorer) RetryJob(ctx context.Context, queuePrefix, queueName, jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retried = append(s.retried, jobID)
+	return s.errs[jobID]
+}
+
+func failedJobs(n int, reason func(i int) string) []explorer.JobSummary {
+	jobs := make([]explorer.JobSummary, 0, n)
+	for i := 0; i < n; i++ {
+		jobs = append(jobs, explorer.JobSummary{ID: fmt.Sprint(i), Name: "sync", State: "failed", FailedReason: reason(i)})
+	}
+	return jobs
+}
+
+func TestCollectRetryMatchesPagesAndFilters(t *testing.T) {
+	exp := &stubBulkRetryExplorer{failed: failedJobs(1200, func(i int) string {
+		if i%3 == 0 {
+			return "connect ECONNREFUSED 10.0.0.1:443"
+		}
+		return "validation failed"
+	})}
+
+	matcher, err := newJobMatcher("econnrefused", false)
+	if err != nil {
+		t.Fatalf("newJobMatcher returned error: %v", err)
+	}
+	matches, err := collectRetryMatches(context.Background(), exp, explorer.QueueRef{Prefix: "bull", Name: "orders"}, matcher, 0, nil)
+	if err != nil {
+		t.Fatalf("collectRetryMatches returned error: %v", err)
+	}
+
+	if got, want := len(matches.JobIDs), 400; got != want {
+		t.Fatalf("match count mismatch: got %d want %d", got, want)
+	}
+	if got, want := matches.Scanned, 1200; got != want {
+		t.Fatalf("scanned mismatch: got %d want %d", got, want)
+	}
+	if got, want := len(matches.Sample), bulkRetrySampleSize; got != want {
+		t.Fatalf("sample size mismatch: got %d want %d", got, want)
+	}
+}
+
+func TestCollectRetryMatchesStopsAtScanLimit(t *testing.T) {
+	exp := &stubBulkRetryExplorer{failed: failedJobs(1200, func(int) string { return "boom" })}
+
+	var pages []int
+	matches, err := collectRetryMatches(context.Background(), exp, explorer.QueueRef{Prefix: "bull", Name: "orders"}, jobMatcher{}, 700, func(scanned int) {
+		pages = append(pages, scanned)
+	})
+	if err != nil {
+		t.Fatalf("collectRetryMatches returned error: %v", err)
+	}
+	if matches.Scanned != 700 || !matches.ScanLimited {
+		t.Fatalf("expected the scan to stop at 700 jobs, got scanned=%d limited=%t", matches.Scanned, matches.ScanLimited)
+	}
+	if fmt.Sprint(pages) != "[500 700]" {
+		t.Fatalf("unexpected page progress: %v", pages)
+	}
+}
+
+func TestCollectRetryMatchesRegex(t *testing.T) {
+	exp := &stubBulkRetryExplorer{failed: failedJobs(10, func(i int) string {
+		return fmt.Sprintf("HTTP %d", 500+i)
+	})}
+
+	matcher, err := newJobMatcher(`^HTTP 50[0-2]$`, true)
+	if err != nil {
+		t.Fatalf("newJobMatcher returned error: %v", err)
+	}
+	matches, err := collectRetryMatches(context.Background(), exp, explorer.QueueRef{Prefix: "bull", Name: "orders"}, matcher, 0, nil)
+	if err != nil {
+		t.Fatalf("collectRetryMatches returned error: %v", err)
+	}
+	if got, want := len(matches.JobIDs), 3; got != want {
+		t.Fatalf("match count mismatch: got %d want %d", got, want)
+	}
+}
+
+func TestBulkRetryManagerRunsToCompletion(t *testing.T) {
+	exp := &stubBulkRetryExplorer{
+		failed: []explorer.JobSummary{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}},
+		errs: map[string]error{
+			"b": fmt.Errorf("%w: b", explorer.ErrJobWrongState),
+			"c": fmt.Errorf("redis down"),
+		},
+	}
+	manager := NewBulkRetryManager()
+	defer manager.Close()
+	manager.batchSize = 2
+	manager.batchPause = time.Millisecond
+
+	finished := make(chan []string, 1)
+	started, err := manager.Start(exp, explorer.QueueRef{Prefix: "bull", Name: "orders"}, "", false, jobMatcher{}, func(_ BulkRetryProgress, retried []string, _ error) {
+		finished <- retried
+	})
+	if err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	if !started.Scanning {
+		t.Fatalf("expected the task to start by scanning: %+v", started)
+	}
+
+	progress := waitForBulkRetry(t, manager, started.ID)
+	if progress.Total != 4 || progress.Retried != 2 || progress.Skipped != 1 || progress.Errors != 1 || progress.Cancelled {
+		t.Fatalf("unexpected progress: %+v", progress)
+	}
+	if progress.Percent() != 100 {
+		t.Fatalf("expected 100%%, got %d", progress.Percent())
+	}
+	if retried := <-finished; fmt.Sprint(retried) != "[a d]" {
+		t.Fatalf("finished callback got %v, want the retried jobs", retried)
+	}
+}
+
+func TestBulkRetryManagerCancel(t *testing.T) {
+	exp := &stubBulkRetryExplorer{failed: failedJobs(10, func(int) string { return "boom" })}
+	manager := NewBulkRetryManager()
+	defer manager.Close()
+	manager.batchSize = 1
+	manager.batchPause = time.Hour
+
+	started, err := manager.Start(exp, explorer.QueueRef{Prefix: "bull", Name: "orders"}, "", false, jobMatcher{}, nil)
+	if err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	if _, err := manager.Cancel(started.ID); err != nil {
+		t.Fatalf("Cancel returned error: %v", err)
+	}
+
+	progress := waitForBulkRetry(t, manager, started.ID)
+	if !progress.Cancelled || progress.Processed > 1 {
+		t.Fatalf("expected the task to stop early: %+v", progress)
+	}
+	if _, err := manager.Cancel(started.ID); err == nil {
+		t.Fatal("expected cancelling a finished task to fail")
+	}
+	if _, err := manager.Cancel("missing"); err != errBulkRetryTaskNotFound {
+		t.Fatalf("expected errBulkRetryTaskNotFound, got %v", err)
+	}
+}
+
+func TestBulkRetryManagerCloseStopsTasks(t *testing.T) {
+	manager := NewBulkRetryManager()
+	manager.batchSize = 1
+	manager.batchPause = time.Hour
+
+	started, err := manager.Start(&stubBulkRetryExplorer{failed: failedJobs(10, func(int) string { return "boom" })}, explorer.QueueRef{Prefix: "bull", Name: "orders"}, "", false, jobMatcher{}, nil)
+	if err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	manager.Close()
+
+	if progress, _ := manager.Get(started.ID); !progress.Done || !progress.Cancelled {
+		t.Fatalf("expected Close to stop the task: %+v", progress)
+	}
+	if _, err := manager.Start(&stubBulkRetryExplorer{}, explorer.QueueRef{Prefix: "bull", Name: "billing"}, "", false, jobMatcher{}, nil); err == nil {
+		t.Fatal("expected Start to fail after Close")
+	}
+}
+
+func waitForBulkRetry(t *testing.T, manager *BulkRetryManager, id string) BulkRetryProgress {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if progress, _ := manager.Get(id); progress.Done {
+			return progress
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("bulk retry did not finish")
+	return BulkRetryProgress{}
+}
+
+func TestBulkRetryManagerRejectsConcurrentRunsPerQueue(t *testing.T) {
+	manager := NewBulkRetryManager()
+	manager.tasks["1"] = &BulkRetryProgress{ID: "1", Prefix: "bull", Queue: "orders"}
+	manager.order = []string{"1"}
+
+	if _, err := manager.Start(&stubBulkRetryExplorer{}, explorer.QueueRef{Prefix: "bull", Name: "orders"}, "", false, jobMatcher{}, nil); err == nil {
+		t.Fatal("expected second bulk retry on the same queue to be rejected")
+	}
+	// The same queue name under another prefix is a different queue.
+	if _, err := manager.Start(&stubBulkRetryExplorer{}, explorer.QueueRef{Prefix: "billing", Name: "orders"}, "", false, jobMatcher{}, nil); err != nil {
+		t.Fatalf("expected bulk retry under another prefix to start, got %v", err)
+	}
+}
@@ -8,7 +8,7 @@ import (
 )
 
 type DashboardSnapshot struct {
-	Queues    []string
+	Queues    []explorer.QueueRef
 	Stats     []explorer.QueueStats
 	UpdatedAt time.Time
 }
@@ -21,7 +21,7 @@ type DashboardCache struct {
 func NewDashboardCache() *DashboardCache {
 	return &DashboardCache{
 		snapshot: DashboardSnapshot{
-			Queues: make([]string, 0),
+			Queues: make([]explorer.QueueRef, 0),
 			Stats:  make([]explorer.QueueStats, 0),
 		},
 	}
@@ -32,18 +32,18 @@ func (c *DashboardCache) Get() DashboardSnapshot {
 	defer c.mu.RUnlock()
 
 	return DashboardSnapshot{
-		Queues:    append([]string(nil), c.snapshot.Queues...),
+		Queues:    append([]explorer.QueueRef(nil), c.snapshot.Queues...),
 		Stats:     append([]explorer.QueueStats(nil), c.snapshot.Stats...),
 		UpdatedAt: c.snapshot.UpdatedAt,
 	}
 }
 
-func (c *DashboardCache) Set(queues []string, stats []explorer.QueueStats, updatedAt time.Time) {
+func (c *DashboardCache) Set(queues []explorer.QueueRef, stats []explorer.QueueStats, updatedAt time.Time) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.snapshot = DashboardSnapshot{
-		Queues:    append([]string(nil), queues...),
+		Queues:    append([]explorer.QueueRef(nil), queues...),
 		Stats:     append([]explorer.QueueStats(nil), stats...),
 		UpdatedAt: updatedAt,
 	}
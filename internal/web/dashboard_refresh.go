@@ -13,24 +13,43 @@ type dashboardRefresher interface {
 	GetQueueStatsFast(ctx context.Context, queuePrefix string, queues []string) ([]explorer.QueueStats, error)
 }
 
-// RefreshDashboardCache updates the dashboard snapshot. If queue discovery times
-// out but we already have a cached queue list, reuse that list so counts can
-// continue refreshing instead of leaving the dashboard stale indefinitely.
-func RefreshDashboardCache(ctx context.Context, exp dashboardRefresher, prefix string, cache *DashboardCache) error {
-	queues, err := exp.DiscoverQueues(ctx, prefix)
-	if err != nil {
-		snapshot := cache.Get()
-		if len(snapshot.Queues) == 0 || !errors.Is(err, context.DeadlineExceeded) {
-			return err
+// RefreshDashboardCache updates the dashboard snapshot for every prefix. If
+// queue discovery times out but we already have a cached queue list for the
+// prefix, reuse that list so counts can continue refreshing instead of leaving
+// the dashboard stale indefinitely.
+func RefreshDashboardCache(ctx context.Context, exp dashboardRefresher, prefixes []string, cache *DashboardCache) error {
+	snapshot := cache.Get()
+	var queues []explorer.QueueRef
+	var stats []explorer.QueueStats
+	for _, prefix := range prefixes {
+		names, err := exp.DiscoverQueues(ctx, prefix)
+		if err != nil {
+			names = cachedQueueNames(snapshot.Queues, prefix)
+			if len(names) == 0 || !errors.Is(err, context.DeadlineExceeded) {
+				return err
+			}
 		}
-		queues = snapshot.Queues
-	}
 
-	stats, err := exp.GetQueueStatsFast(ctx, prefix, queues)
-	if err != nil {
-		return err
+		prefixStats, err := exp.GetQueueStatsFast(ctx, prefix, names)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			queues = append(queues, explorer.QueueRef{Prefix: prefix, Name: name})
+		}
+		stats = append(stats, prefixStats...)
 	}
 
 	cache.Set(queues, stats, time.Now())
 	return nil
 }
+
+func cachedQueueNames(queues []explorer.QueueRef, prefix string) []string {
+	var names []string
+	for _, queue := range queues {
+		if queue.Prefix == prefix {
+			names = append(names, queue.Name)
+		}
+	}
+	return names
+}
@@ -25,8 +25,8 @@ func (s stubDashboardRefresher) GetQueueStatsFast(ctx context.Context, queuePref
 func TestRefreshDashboardCacheFallsBackToCachedQueuesOnDeadlineExceeded(t *testing.T) {
 	cache := NewDashboardCache()
 	cache.Set(
-		[]string{"emails"},
-		[]explorer.QueueStats{{Name: "emails", Total: 1}},
+		[]explorer.QueueRef{{Prefix: "bull", Name: "emails"}},
+		[]explorer.QueueStats{{Prefix: "bull", Name: "emails", Total: 1}},
 		time.Unix(1700000000, 0),
 	)
 
@@ -39,7 +39,7 @@ func TestRefreshDashboardCacheFallsBackToCachedQueuesOnDeadlineExceeded(t *testi
 			gotQueues = append([]string(nil), queues...)
 			return []explorer.QueueStats{{Name: "emails", Total: 9}}, nil
 		},
-	}, "bull", cache)
+	}, []string{"bull"}, cache)
 	if err != nil {
 		t.Fatalf("RefreshDashboardCache returned error: %v", err)
 	}
@@ -66,7 +66,7 @@ func TestRefreshDashboardCacheReturnsDiscoveryErrorWithoutCachedQueues(t *testin
 			t.Fatal("GetQueueStatsFast should not be called when there is no cached queue list")
 			return nil, nil
 		},
-	}, "bull", cache)
+	}, []string{"bull"}, cache)
 	if !errors.Is(err, wantErr) {
 		t.Fatalf("expected %v, got %v", wantErr, err)
 	}
@@ -75,8 +75,8 @@ func TestRefreshDashboardCacheReturnsDiscoveryErrorWithoutCachedQueues(t *testin
 func TestRefreshDashboardCacheReturnsNonDeadlineDiscoveryError(t *testing.T) {
 	cache := NewDashboardCache()
 	cache.Set(
-		[]string{"emails"},
-		[]explorer.QueueStats{{Name: "emails", Total: 1}},
+		[]explorer.QueueRef{{Prefix: "bull", Name: "emails"}},
+		[]explorer.QueueStats{{Prefix: "bull", Name: "emails", Total: 1}},
 		time.Unix(1700000000, 0),
 	)
 	wantErr := errors.New("redis unavailable")
@@ -89,8 +89,33 @@ func TestRefreshDashboardCacheReturnsNonDeadlineDiscoveryError(t *testing.T) {
 			t.Fatal("GetQueueStatsFast should not be called for non-timeout discovery failures")
 			return nil, nil
 		},
-	}, "bull", cache)
+	}, []string{"bull"}, cache)
 	if !errors.Is(err, wantErr) {
 		t.Fatalf("expected %v, got %v", wantErr, err)
 	}
 }
+
+func TestRefreshDashboardCacheCombinesPrefixes(t *testing.T) {
+	cache := NewDashboardCache()
+
+	err := RefreshDashboardCache(context.Background(), stubDashboardRefresher{
+		discoverQueues: func(ctx context.Context, prefix string) ([]string, error) {
+			return []string{"emails"}, nil
+		},
+		getQueueStats: func(ctx context.Context, queuePrefix string, queues []string) ([]explorer.QueueStats, error) {
+			return []explorer.QueueStats{{Prefix: queuePrefix, Name: queues[0]}}, nil
+		},
+	}, []string{"bull", "billing"}, cache)
+	if err != nil {
+		t.Fatalf("RefreshDashboardCache returned error: %v", err)
+	}
+
+	snapshot := cache.Get()
+	want := []explorer.QueueRef{{Prefix: "bull", Name: "emails"}, {Prefix: "billing", Name: "emails"}}
+	if len(snapshot.Queues) != len(want) || snapshot.Queues[0] != want[0] || snapshot.Queues[1] != want[1] {
+		t.Fatalf("queues mismatch: got %v want %v", snapshot.Queues, want)
+	}
+	if got := snapshot.Stats[1].Prefix; got != "billing" {
+		t.Fatalf("expected stats for the second prefix, got prefix %q", got)
+	}
+}
@@ -0,0 +1,218 @@
+package web
+
+import (
+	"context"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kofno/bullderdash/internal/auth"
+)
+
+// environmentPathPrefix starts the paths that name an environment:
+// /env/<name>/queue/orders.
+const environmentPathPrefix = "/env/"
+
+type environmentContextKey struct{}
+
+type mountContextKey struct{}
+
+// WithBasePath serves next under prefix, such as "/tools/bullmq" behind a
+// shared ingress, with the prefix stripped so routes stay the same. Paths
+// outside it are not found. An empty prefix returns next unchanged.
+func WithBasePath(prefix string, next http.Handler) http.Handler {
+	if prefix == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+			http.NotFound(w, r)
+			return
+		}
+		if rest == "" {
+			target := prefix + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+		ctx := context.WithValue(r.Context(), mountContextKey{}, prefix)
+		next.ServeHTTP(w, withPath(r, rest).WithContext(ctx))
+	})
+}
+
+// mountPath is the BASE_PATH the request came in under, or empty.
+func mountPath(r *http.Request) string {
+	prefix, _ := r.Context().Value(mountContextKey{}).(string)
+	return prefix
+}
+
+// requestEnvironment is the environment a request was routed to, along with
+// every configured name for the switcher.
+type requestEnvironment struct {
+	name  string
+	names []string
+}
+
+// EnvironmentRouter serves several named Redis environments from one
+// dashboard. Paths under /env/<name>/ go to that environment's handler with
+// the prefix stripped; every other path goes to the first environment.
+type EnvironmentRouter struct {
+	names    []string
+	handlers map[string]http.Handler
+}
+
+func NewEnvironmentRouter() *EnvironmentRouter {
+	return &EnvironmentRouter{handlers: make(map[string]http.Handler)}
+}
+
+// Handle adds an environment. The first one added is the default.
+func (er *EnvironmentRouter) Handle(name string, handler http.Handler) {
+	if _, ok := er.handlers[name]; !ok {
+		er.names = append(er.names, name)
+	}
+	er.handlers[name] = handler
+}
+
+func (er *EnvironmentRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if len(er.names) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	name := er.names[0]
+	if env, rest, ok := SplitEnvironmentPath(r.URL.Path); ok {
+		if _, known := er.handlers[env]; !known {
+			http.NotFound(w, r)
+			return
+		}
+		if rest == "" {
+			target := mountPath(r) + r.URL.Path + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+		name = env
+		r = withPath(r, rest)
+	}
+
+	ctx := context.WithValue(r.Context(), environmentContextKey{}, requestEnvironment{name: name, names: er.names})
+	er.handlers[name].ServeHTTP(w, r.WithContext(ctx))
+}
+
+// SplitEnvironmentPath splits "/env/<name>/rest" into the name and "/rest".
+// rest is empty for "/env/<name>" itself.
+func SplitEnvironmentPath(path string) (name, rest string, ok bool) {
+	trimmed, ok := strings.CutPrefix(path, environmentPathPrefix)
+	if !ok {
+		return "", "", false
+	}
+	name, rest, hasRest := strings.Cut(trimmed, "/")
+	if name == "" {
+		return "", "", false
+	}
+	if hasRest {
+		rest = "/" + rest
+	}
+	return name, rest, true
+}
+
+// withPath is a shallow copy of r with a different URL path, like
+// http.StripPrefix makes.
+func withPath(r *http.Request, path string) *http.Request {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = path
+	r2.URL.RawPath = ""
+	return r2
+}
+
+// basePath is the path prefix for links on a page: BASE_PATH, followed by
+// "/env/<name>" when several environments are configured. Without either it
+// is empty, so single-target deployments keep their plain URLs.
+func basePath(r *http.Request) string {
+	env, ok := r.Context().Value(environmentContextKey{}).(requestEnvironment)
+	if !ok || len(env.names) < 2 {
+		return mountPath(r)
+	}
+	return mountPath(r) + environmentPathPrefix + env.name
+}
+
+type environmentLink struct {
+	Name    string
+	URL     string
+	Current bool
+}
+
+// environmentLinks lists the switcher entries, or nothing with a single
+// environment.
+func environmentLinks(r *http.Request) []environmentLink {
+	env, ok := r.Context().Value(environmentContextKey{}).(requestEnvironment)
+	if !ok || len(env.names) < 2 {
+		return nil
+	}
+	links := make([]environmentLink, 0, len(env.names))
+	for _, name := range env.names {
+		links = append(links, environmentLink{
+			Name:    name,
+			URL:     mountPath(r) + environmentPathPrefix + name + "/",
+			Current: name == env.name,
+		})
+	}
+	return links
+}
+
+// templateFuncs are available to every template. basePath, rootPath, asset,
+// currentUser and can are placeholders until requestFuncs binds them for a
+// request. rootPath prefixes the process-wide paths, such as /metrics, that
+// are not served per environment.
+var templateFuncs = template.FuncMap{
+	"add":         func(a, b int) int { return a + b },
+	"sub":         func(a, b int) int { return a - b },
+	"basePath":    func() string { return "" },
+	"rootPath":    func() string { return "" },
+	"asset":       assetURL,
+	"currentUser": func() *auth.Identity { return nil },
+	"can":         func(role, queueName string) bool { return false },
+}
+
+func requestFuncs(r *http.Request) template.FuncMap {
+	base := basePath(r)
+	root := mountPath(r)
+	user := auth.FromContext(r.Context())
+	return template.FuncMap{
+		"basePath": func() string { return base },
+		"rootPath": func() string { return root },
+		"asset": func(name string) string {
+			if url := assetURL(name); url != "" {
+				return root + url
+			}
+			return ""
+		},
+		"currentUser": func() *auth.Identity { return user },
+		"can":         canFunc(r),
+	}
+}
+
+// newTemplate starts a template with templateFuncs defined.
+func newTemplate(name string) *template.Template {
+	return template.New(name).Funcs(templateFuncs)
+}
+
+// executeTemplate runs a clone of tmpl with the request's funcs bound. tmpl
+// itself is never executed, which keeps it cloneable.
+func executeTemplate(w io.Writer, r *http.Request, tmpl *template.Template, data any) error {
+	clone, err := tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	return clone.Funcs(requestFuncs(r)).Execute(w, data)
+}
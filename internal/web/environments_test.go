@@ -0,0 +1,155 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSplitEnvironmentPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		wantName string
+		wantRest string
+		wantOK   bool
+	}{
+		{path: "/env/prod/queue/orders", wantName: "prod", wantRest: "/queue/orders", wantOK: true},
+		{path: "/env/prod/", wantName: "prod", wantRest: "/", wantOK: true},
+		{path: "/env/prod", wantName: "prod", wantRest: "", wantOK: true},
+		{path: "/env/", wantOK: false},
+		{path: "/queue/env/prod", wantOK: false},
+	}
+	for _, tt := range tests {
+		name, rest, ok := SplitEnvironmentPath(tt.path)
+		if name != tt.wantName || rest != tt.wantRest || ok != tt.wantOK {
+			t.Errorf("SplitEnvironmentPath(%q) = %q, %q, %t", tt.path, name, rest, ok)
+		}
+	}
+}
+
+func newTestEnvironmentRouter(names ...string) *EnvironmentRouter {
+	router := NewEnvironmentRouter()
+	for _, name := range names {
+		router.Handle(name, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name + " " + r.URL.Path + " " + basePath(r)))
+		}))
+	}
+	return router
+}
+
+func TestEnvironmentRouterRoutesByPath(t *testing.T) {
+	router := newTestEnvironmentRouter("staging", "prod")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/queue/orders", want: "staging /queue/orders /env/staging"},
+		{path: "/env/prod/queue/orders", want: "prod /queue/orders /env/prod"},
+		{path: "/env/staging/", want: "staging / /env/staging"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != tt.want {
+			t.Errorf("GET %s = %d %q, want %q", tt.path, rec.Code, rec.Body.String(), tt.want)
+		}
+	}
+}
+
+func TestEnvironmentRouterUnknownAndBareEnvironment(t *testing.T) {
+	router := newTestEnvironmentRouter("staging", "prod")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/env/qa/queue/orders", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown environment, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/env/prod?x=1", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/env/prod/?x=1" {
+		t.Fatalf("expected a redirect to /env/prod/?x=1, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestSingleEnvironmentKeepsPlainURLs(t *testing.T) {
+	router := newTestEnvironmentRouter("default")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/queue/orders", nil))
+	if rec.Body.String() != "default /queue/orders " {
+		t.Fatalf("expected no base path with one environment, got %q", rec.Body.String())
+	}
+}
+
+func TestShellLinksCarryEnvironment(t *testing.T) {
+	router := NewEnvironmentRouter()
+	for _, name := range []string{"staging", "prod"} {
+		router.Handle(name, HomeHandler())
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/env/prod/", nil))
+	html := rec.Body.String()
+	for _, want := range []string{
+		`hx-get="/env/prod/queues"`,
+		`href="/env/prod/search"`,
+		`href="/env/staging/" class="px-3 py-1 text-gray-600`,
+		`href="/env/prod/" class="px-3 py-1 bg-indigo-600 text-white"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected shell to contain %q", want)
+		}
+	}
+}
+
+func TestWithBasePathStripsPrefix(t *testing.T) {
+	handler := WithBasePath("/tools/bullmq", newTestEnvironmentRouter("staging", "prod"))
+
+	tests := []struct {
+		path     string
+		wantCode int
+		want     string
+	}{
+		{path: "/tools/bullmq/queue/orders", wantCode: http.StatusOK, want: "staging /queue/orders /tools/bullmq/env/staging"},
+		{path: "/tools/bullmq/env/prod/", wantCode: http.StatusOK, want: "prod / /tools/bullmq/env/prod"},
+		{path: "/queue/orders", wantCode: http.StatusNotFound},
+		{path: "/tools/bullmqx/queue/orders", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.wantCode || (tt.want != "" && rec.Body.String() != tt.want) {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.path, rec.Code, rec.Body.String(), tt.wantCode, tt.want)
+		}
+	}
+
+	for path, want := range map[string]string{
+		"/tools/bullmq?x=1":      "/tools/bullmq/?x=1",
+		"/tools/bullmq/env/prod": "/tools/bullmq/env/prod/",
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != want {
+			t.Errorf("GET %s = %d %q, want a redirect to %q", path, rec.Code, rec.Header().Get("Location"), want)
+		}
+	}
+}
+
+func TestShellLinksCarryBasePath(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WithBasePath("/tools/bullmq", HomeHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tools/bullmq/", nil))
+	html := rec.Body.String()
+	for _, want := range []string{
+		`hx-get="/tools/bullmq/queues"`,
+		`href="/tools/bullmq/search"`,
+		`href="/tools/bullmq/metrics"`,
+		`src="/tools/bullmq` + assetURL("bullderdash.js") + `"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected shell to contain %q", want)
+		}
+	}
+}
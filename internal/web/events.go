@@ -0,0 +1,256 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+const (
+	queueEventsBlock     = 5 * time.Second
+	queueEventsBatchSize = 100
+	// queueEventsRetryMillis tells EventSource how soon to reconnect.
+	queueEventsRetryMillis = 3000
+	// queueEventsWriteTimeout is how long a viewer may take to accept one
+	// read's worth of events before the stream is dropped.
+	queueEventsWriteTimeout = 10 * time.Second
+)
+
+// EventStreams caps how many live events streams the server holds open at
+// once; each keeps a Redis connection busy in a blocking XREAD.
+type EventStreams struct {
+	slots chan struct{}
+}
+
+func NewEventStreams(max int) *EventStreams {
+	return &EventStreams{slots: make(chan struct{}, max)}
+}
+
+// acquire takes a slot without waiting, reporting false when all are in use.
+func (s *EventStreams) acquire() bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *EventStreams) release() {
+	<-s.slots
+}
+
+// queueEventPanelTypes are the events the queue page's panel offers.
+var queueEventPanelTypes = []string{"added", "active", "progress", "completed", "failed", "stalled"}
+
+// queueEventFilter narrows a tail to some event types and one job. Empty
+// fields match everything.
+type queueEventFilter struct {
+	Events map[string]bool
+	JobID  string
+}
+
+func parseQueueEventFilter(r *http.Request) queueEventFilter {
+	filter := queueEventFilter{JobID: strings.TrimSpace(r.URL.Query().Get("job"))}
+	for _, value := range r.URL.Query()["event"] {
+		for _, event := range strings.Split(value, ",") {
+			if event = strings.TrimSpace(event); event != "" {
+				if filter.Events == nil {
+					filter.Events = make(map[string]bool)
+				}
+				filter.Events[event] = true
+			}
+		}
+	}
+	return filter
+}
+
+func (f queueEventFilter) match(event explorer.QueueEvent) bool {
+	if len(f.Events) > 0 && !f.Events[event.Event] {
+		return false
+	}
+	return f.JobID == "" || f.JobID == event.JobID
+}
+
+// QueueEventsHandler tails "<prefix>:<queue>:events" as Server-Sent Events.
+// It starts after from=<id>, or at the end of the stream with from=$ (the
+// default); a reconnecting EventSource resumes from its Last-Event-ID.
+// event=<type>[,<type>] and job=<id> filter the events sent. Once streams
+// has no slot left, new tails are refused with 503.
+func QueueEventsHandler(exp *explorer.Explorer, streams *EventStreams) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		queueName := strings.TrimSpace(r.URL.Query().Get("queue"))
+		if queueName == "" {
+			http.Error(w, "queue parameter required", http.StatusBadRequest)
+			return
+		}
+		if !requireQueueRole(w, r, queueName, auth.RoleViewer) {
+			return
+		}
+		prefix, ok := requestPrefix(w, r, exp)
+		if !ok {
+			return
+		}
+		from := strings.TrimSpace(r.URL.Query().Get("from"))
+		if lastEventID := strings.TrimSpace(r.Header.Get("Last-Event-ID")); lastEventID != "" {
+			from = lastEventID
+		}
+		if from == "" {
+			from = "$"
+		}
+		if !explorer.ValidStreamID(from) {
+			http.Error(w, fmt.Sprintf("invalid stream ID %q", from), http.StatusBadRequest)
+			return
+		}
+		filter := parseQueueEventFilter(r)
+
+		if !streams.acquire() {
+			log.Printf("⚠️ queue events refused: %d streams already open (prefix=%s queue=%s)", cap(streams.slots), prefix, queueName)
+			w.Header().Set("Retry-After", "30")
+			http.Error(w, "too many live event streams; try again later", http.StatusServiceUnavailable)
+			return
+		}
+		defer streams.release()
+
+		lastID := from
+		if lastID == "$" {
+			var err error
+			if lastID, err = exp.LastQueueEventID(r.Context(), prefix, queueName); err != nil {
+				log.Printf("❌ queue events error (prefix=%s queue=%s): %v", prefix, queueName, err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// The server's write timeout would cut the stream off, so each read
+		// pushes the deadline out instead: a viewer that stops reading is
+		// still dropped.
+		rc := http.NewResponseController(w)
+		extendDeadline := func() {
+			if err := rc.SetWriteDeadline(time.Now().Add(queueEventsBlock + queueEventsWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				log.Printf("⚠️ queue events: extending write deadline: %v", err)
+			}
+		}
+		extendDeadline()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "retry: %d\n\n", queueEventsRetryMillis)
+		if err := rc.Flush(); err != nil {
+			log.Printf("❌ queue events: streaming unsupported: %v", err)
+			return
+		}
+
+		for {
+			extendDeadline()
+			events, err := exp.ReadQueueEvents(r.Context(), prefix, queueName, lastID, queueEventsBatchSize, queueEventsBlock)
+			if r.Context().Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Printf("❌ queue events error (prefix=%s queue=%s): %v", prefix, queueName, err)
+				_ = writeServerSentEvent(w, "", "error", actionResult{OK: false, Message: err.Error()})
+				_ = rc.Flush()
+				return
+			}
+
+			if len(events) == 0 {
+				// A comment keeps proxies from closing an idle stream.
+				_, err = io.WriteString(w, ": keepalive\n\n")
+			}
+			for _, event := range events {
+				lastID = event.ID
+				if !filter.match(event) {
+					continue
+				}
+				if err = writeServerSentEvent(w, event.ID, event.Event, event); err != nil {
+					break
+				}
+			}
+			if err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeServerSentEvent writes one SSE message with a JSON data line.
+func writeServerSentEvent(w io.Writer, id, event string, data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if event == "" || strings.ContainsAny(event, "\r\n") {
+		event = "message"
+	}
+	var buf bytes.Buffer
+	if id != "" {
+		fmt.Fprintf(&buf, "id: %s\n", id)
+	}
+	fmt.Fprintf(&buf, "event: %s\ndata: %s\n\n", event, encoded)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+type queueEventsPanelData struct {
+	Prefix string
+	Queue  string
+	Types  []string
+}
+
+// queueEventsPanelTmpl is the live Events panel on the queue page; the
+// data-events-panel script in static/bullderdash.js drives it.
+const queueEventsPanelTmpl = `
+<div id="queue-events" data-events-panel class="rounded-lg border border-gray-200 p-4 mb-8" data-src="{{basePath}}/queue/events?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}" data-detail="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id=">
+    <div class="flex flex-wrap items-center justify-between gap-3">
+        <div class="flex items-center gap-2">
+            <div class="text-xs uppercase text-gray-400">Events</div>
+            <span data-role="status" class="rounded-full bg-gray-100 px-2 py-0.5 text-xs font-semibold text-gray-600">Stopped</span>
+        </div>
+        <div class="flex flex-wrap items-center gap-3 text-sm text-gray-600">
+            {{range .Types}}
+            <label class="flex items-center gap-1"><input type="checkbox" data-role="type" value="{{.}}" checked> {{.}}</label>
+            {{end}}
+            <input type="text" data-role="job" placeholder="Job ID" class="w-32 rounded-md border border-gray-300 px-2 py-1 text-sm text-gray-800">
+            <button type="button" data-role="toggle" class="rounded-md border border-blue-300 px-3 py-1 text-xs font-semibold text-blue-700 hover:bg-blue-50">Start</button>
+            <button type="button" data-role="clear" class="text-xs text-gray-500 hover:text-gray-700">Clear</button>
+        </div>
+    </div>
+    <div class="mt-3 max-h-80 overflow-auto">
+        <table class="min-w-full divide-y divide-gray-200 text-sm">
+            <thead class="bg-gray-50">
+                <tr>
+                    <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Time</th>
+                    <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Event</th>
+                    <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Job ID</th>
+                    <th class="px-4 py-2 text-left text-xs font-medium text-gray-500 uppercase">Details</th>
+                </tr>
+            </thead>
+            <tbody data-role="rows" class="divide-y divide-gray-100 font-mono text-xs"></tbody>
+        </table>
+    </div>
+</div>
+`
+
+var queueEventsPanelTemplate = template.Must(newTemplate("queue-events").Parse(queueEventsPanelTmpl))
+
+func renderQueueEventsPanelHTML(r *http.Request, prefix, queueName string) (template.HTML, error) {
+	var b strings.Builder
+	data := queueEventsPanelData{Prefix: prefix, Queue: queueName, Types: queueEventPanelTypes}
+	if err := executeTemplate(&b, r, queueEventsPanelTemplate, data); err != nil {
+		return "", err
+	}
+	return template.HTML(b.String()), nil
+}
@@ -0,0 +1,97 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+func TestQueueEventFilter(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/queue/events?queue=orders&event=completed,failed&event=stalled&job=42", nil)
+	filter := parseQueueEventFilter(req)
+
+	tests := []struct {
+		event explorer.QueueEvent
+		want  bool
+	}{
+		{event: explorer.QueueEvent{Event: "completed", JobID: "42"}, want: true},
+		{event: explorer.QueueEvent{Event: "stalled", JobID: "42"}, want: true},
+		{event: explorer.QueueEvent{Event: "active", JobID: "42"}, want: false},
+		{event: explorer.QueueEvent{Event: "failed", JobID: "7"}, want: false},
+	}
+	for _, tc := range tests {
+		if got := filter.match(tc.event); got != tc.want {
+			t.Fatalf("match(%+v) = %t, want %t", tc.event, got, tc.want)
+		}
+	}
+
+	all := parseQueueEventFilter(httptest.NewRequest(http.MethodGet, "/queue/events?queue=orders", nil))
+	if !all.match(explorer.QueueEvent{Event: "drained"}) {
+		t.Fatal("expected an empty filter to match every event")
+	}
+}
+
+func TestWriteServerSentEvent(t *testing.T) {
+	var b strings.Builder
+	if err := writeServerSentEvent(&b, "1700000000000-0", "completed", explorer.QueueEvent{ID: "1700000000000-0", Event: "completed", JobID: "42"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got := b.String()
+	if !strings.HasPrefix(got, "id: 1700000000000-0\nevent: completed\ndata: {") || !strings.HasSuffix(got, "}\n\n") {
+		t.Fatalf("unexpected message %q", got)
+	}
+	if strings.Count(got, "\n") != 4 {
+		t.Fatalf("expected the data on a single line, got %q", got)
+	}
+
+	b.Reset()
+	if err := writeServerSentEvent(&b, "", "bad\nname", actionResult{}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !strings.HasPrefix(b.String(), "event: message\n") {
+		t.Fatalf("expected an unsafe event name to be replaced, got %q", b.String())
+	}
+}
+
+func TestQueueEventsHandlerRejectsBadStart(t *testing.T) {
+	handler := QueueEventsHandler(explorer.New(nil), NewEventStreams(1))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/queue/events?queue=orders&from=yesterday", nil))
+	if got, want := rec.Code, http.StatusBadRequest; got != want {
+		t.Fatalf("status mismatch: got %d want %d", got, want)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/queue/events?queue=orders", nil)
+	req.Header.Set("Last-Event-ID", "not-an-id")
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if got, want := rec.Code, http.StatusBadRequest; got != want {
+		t.Fatalf("expected Last-Event-ID to be validated: got %d want %d", got, want)
+	}
+}
+
+func TestQueueEventsHandlerRefusesStreamsOverTheCap(t *testing.T) {
+	streams := NewEventStreams(1)
+	if !streams.acquire() {
+		t.Fatal("expected the first stream to get a slot")
+	}
+	handler := QueueEventsHandler(explorer.New(nil), streams)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/queue/events?queue=orders&from=0", nil))
+	if got, want := rec.Code, http.StatusServiceUnavailable; got != want {
+		t.Fatalf("status mismatch: got %d want %d", got, want)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("expected a Retry-After header")
+	}
+
+	streams.release()
+	if !streams.acquire() {
+		t.Fatal("expected a released slot to be reusable")
+	}
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+const jobFlowTmpl = `
+{{if .IsFlow}}
+<div class="rounded-lg border border-gray-200 p-4">
+    <div class="text-xs uppercase text-gray-400">Flow</div>
+    {{if .Parents}}
+    <div class="mt-2 flex flex-wrap items-center gap-2 text-sm">
+        {{range .Parents}}
+        {{template "flow-node-label" .}}
+        <span class="text-gray-400">→</span>
+        {{end}}
+        <span class="font-semibold text-gray-900">this job</span>
+    </div>
+    {{end}}
+    <ul class="mt-3 space-y-1 text-sm">
+        {{template "flow-node" .Tree}}
+    </ul>
+</div>
+{{end}}
+
+{{define "flow-node"}}
+<li>
+    {{if .Children}}
+    <details open>
+        <summary class="cursor-pointer">{{template "flow-node-label" .}}</summary>
+        <ul class="ml-2 mt-1 space-y-1 border-l border-gray-200 pl-4">
+            {{range .Children}}{{template "flow-node" .}}{{end}}
+            {{if .OmittedChildren}}<li class="text-xs text-gray-500">… {{.OmittedChildren}} more children not shown</li>{{end}}
+        </ul>
+    </details>
+    {{else}}
+    {{template "flow-node-label" .}}
+    {{if .OmittedChildren}}<span class="ml-2 text-xs text-gray-500">({{.OmittedChildren}} children not shown)</span>{{end}}
+    {{end}}
+</li>
+{{end}}
+
+{{define "flow-node-label"}}
+<span class="inline-flex flex-wrap items-center gap-2">
+    {{if eq .State "missing"}}
+    <span class="font-mono text-gray-500">{{.Queue}} #{{.ID}}</span>
+    {{else}}
+    <a href="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}" class="text-indigo-600 hover:text-indigo-900">
+        <span class="text-gray-500">{{.Queue}} /</span> {{.Name}} <span class="font-mono text-gray-500">#{{.ID}}</span>
+    </a>
+    {{end}}
+    <span class="rounded-full px-2 py-0.5 text-xs font-semibold {{stateClass .State}}">{{.State}}</span>
+    {{if eq .Relation "pending"}}<span class="text-xs font-medium text-amber-700">parent is waiting on this job</span>{{end}}
+    {{if eq .Relation "failed"}}<span class="text-xs font-medium text-red-700">failed (recorded on parent)</span>{{end}}
+    {{if or .Pending .Processed .Failed}}<span class="text-xs text-gray-500">children: {{.Pending}} pending · {{.Processed}} processed · {{.Failed}} failed</span>{{end}}
+</span>
+{{end}}
+`
+
+var jobFlowTemplate = template.Must(newTemplate("job-flow").Funcs(template.FuncMap{
+	"stateClass": jobStateClass,
+}).Parse(jobFlowTmpl))
+
+// JobFlowHandler renders a job's FlowProducer parents and children as a
+// collapsible tree fragment, or JSON with format=json. Jobs outside a flow
+// render nothing.
+func JobFlowHandler(exp *explorer.Explorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		queueName := r.URL.Query().Get("queue")
+		jobID := r.URL.Query().Get("id")
+		if queueName == "" || jobID == "" {
+			http.Error(w, "queue and id parameters required", http.StatusBadRequest)
+			return
+		}
+		if !requireQueueRole(w, r, queueName, auth.RoleViewer) {
+			return
+		}
+		prefix, ok := requestPrefix(w, r, exp)
+		if !ok {
+			return
+		}
+
+		flow, err := exp.GetJobFlow(r.Context(), prefix, queueName, jobID)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, explorer.ErrJobNotFound) {
+				status = http.StatusNotFound
+			}
+			log.Printf("❌ job flow error (queue=%s id=%s): %v", queueName, jobID, err)
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		if wantsJSON(r) {
+			writeJSON(w, http.StatusOK, flow)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := executeTemplate(w, r, jobFlowTemplate, flow); err != nil {
+			log.Printf("❌ Template execution error (job flow): %v", err)
+		}
+	}
+}
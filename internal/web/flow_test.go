@@ -0,0 +1,44 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+func TestJobFlowTemplateRendersNestedChildren(t *testing.T) {
+	flow := &explorer.JobFlow{
+		Parents: []*explorer.FlowNode{{Queue: "orders", ID: "root", Name: "order", State: "waiting-children"}},
+		Tree: &explorer.FlowNode{
+			Queue: "orders", ID: "mid", Name: "mid", State: "waiting-children", Pending: 1, Processed: 1,
+			Children: []*explorer.FlowNode{
+				{Prefix: "bull", Queue: "ship", ID: "s1", Name: "ship", State: "active", Relation: "pending", OmittedChildren: 3},
+				{Queue: "pay", ID: "p1", Name: "pay", State: "completed", Relation: "processed"},
+			},
+		},
+	}
+
+	var b strings.Builder
+	if err := executeTemplate(&b, httptest.NewRequest(http.MethodGet, "/", nil), jobFlowTemplate, flow); err != nil {
+		t.Fatalf("template execution failed: %v", err)
+	}
+	html := b.String()
+	for _, want := range []string{"/job/detail?prefix=bull&queue=ship&id=s1", "parent is waiting on this job", "3 children not shown", "this job"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected rendered flow to contain %q", want)
+		}
+	}
+}
+
+func TestJobFlowTemplateEmptyOutsideFlows(t *testing.T) {
+	var b strings.Builder
+	if err := executeTemplate(&b, httptest.NewRequest(http.MethodGet, "/", nil), jobFlowTemplate, &explorer.JobFlow{Tree: &explorer.FlowNode{State: "waiting"}}); err != nil {
+		t.Fatalf("template execution failed: %v", err)
+	}
+	if strings.TrimSpace(b.String()) != "" {
+		t.Fatalf("expected no output for a job outside a flow, got %q", b.String())
+	}
+}
@@ -2,15 +2,16 @@ package web
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/kofno/bullderdash/internal/auth"
 	"github.com/kofno/bullderdash/internal/explorer"
 )
 
@@ -20,7 +21,11 @@ const queueListTmpl = `
     {{range .}}
     <div class="rounded-xl border border-gray-200 bg-white shadow-sm hover:shadow-md transition-shadow">
         <div class="px-4 py-3 border-b border-gray-100 flex items-center justify-between">
-            <a href="/queue/{{.Name}}" class="text-lg font-semibold text-indigo-700 hover:text-indigo-900">{{.Name}}</a>
+            <div class="flex items-center gap-2">
+                <a href="{{basePath}}/queue/{{.Name}}?prefix={{urlquery .Prefix}}" class="text-lg font-semibold text-indigo-700 hover:text-indigo-900">{{.Name}}</a>
+                <span class="text-xs text-gray-400" title="Queue prefix">{{.Prefix}}</span>
+                {{template "queue-paused-badge" .}}
+            </div>
             <span class="text-xs uppercase tracking-wide text-gray-400">Total</span>
             <span class="text-sm font-bold text-gray-900">{{.Total}}</span>
         </div>
@@ -28,7 +33,7 @@ const queueListTmpl = `
             <div class="flex items-center justify-between rounded-md bg-yellow-50 px-2 py-1">
                 <span class="text-yellow-800">Waiting</span>
                 {{if gt .Wait 0}}
-                    <a href="/queue/jobs?queue={{.Name}}&state=waiting" class="font-semibold text-yellow-900 hover:text-yellow-700">{{.Wait}}</a>
+                    <a href="{{basePath}}/queue/jobs?prefix={{urlquery .Prefix}}&queue={{.Name}}&state=waiting" class="font-semibold text-yellow-900 hover:text-yellow-700">{{.Wait}}</a>
                 {{else}}
                     <span class="text-gray-400">{{.Wait}}</span>
                 {{end}}
@@ -36,7 +41,7 @@ const queueListTmpl = `
             <div class="flex items-center justify-between rounded-md bg-blue-50 px-2 py-1">
                 <span class="text-blue-800">Active</span>
                 {{if gt .Active 0}}
-                    <a href="/queue/jobs?queue={{.Name}}&state=active" class="font-semibold text-blue-900 hover:text-blue-700">{{.Active}}</a>
+                    <a href="{{basePath}}/queue/jobs?prefix={{urlquery .Prefix}}&queue={{.Name}}&state=active" class="font-semibold text-blue-900 hover:text-blue-700">{{.Active}}</a>
                 {{else}}
                     <span class="text-gray-400">{{.Active}}</span>
                 {{end}}
@@ -44,7 +49,7 @@ const queueListTmpl = `
             <div class="flex items-center justify-between rounded-md bg-slate-50 px-2 py-1">
                 <span class="text-slate-700">Paused</span>
                 {{if gt .Paused 0}}
-                    <a href="/queue/jobs?queue={{.Name}}&state=paused" class="font-semibold text-slate-800 hover:text-slate-600">{{.Paused}}</a>
+                    <a href="{{basePath}}/queue/jobs?prefix={{urlquery .Prefix}}&queue={{.Name}}&state=paused" class="font-semibold text-slate-800 hover:text-slate-600">{{.Paused}}</a>
                 {{else}}
                     <span class="text-gray-400">{{.Paused}}</span>
                 {{end}}
@@ -52,7 +57,7 @@ const queueListTmpl = `
             <div class="flex items-center justify-between rounded-md bg-fuchsia-50 px-2 py-1">
                 <span class="text-fuchsia-800">Prioritized</span>
                 {{if gt .Prioritized 0}}
-                    <a href="/queue/jobs?queue={{.Name}}&state=prioritized" class="font-semibold text-fuchsia-900 hover:text-fuchsia-700">{{.Prioritized}}</a>
+                    <a href="{{basePath}}/queue/jobs?prefix={{urlquery .Prefix}}&queue={{.Name}}&state=prioritized" class="font-semibold text-fuchsia-900 hover:text-fuchsia-700">{{.Prioritized}}</a>
                 {{else}}
                     <span class="text-gray-400">{{.Prioritized}}</span>
                 {{end}}
@@ -60,7 +65,7 @@ const queueListTmpl = `
             <div class="flex items-center justify-between rounded-md bg-amber-50 px-2 py-1">
                 <span class="text-amber-800">Waiting-Children</span>
                 {{if gt .WaitingChildren 0}}
-                    <a href="/queue/jobs?queue={{.Name}}&state=waiting-children" class="font-semibold text-amber-900 hover:text-amber-700">{{.WaitingChildren}}</a>
+                    <a href="{{basePath}}/queue/jobs?prefix={{urlquery .Prefix}}&queue={{.Name}}&state=waiting-children" class="font-semibold text-amber-900 hover:text-amber-700">{{.WaitingChildren}}</a>
                 {{else}}
                     <span class="text-gray-400">{{.WaitingChildren}}</span>
                 {{end}}
@@ -68,7 +73,7 @@ const queueListTmpl = `
             <div class="flex items-center justify-between rounded-md bg-green-50 px-2 py-1">
                 <span class="text-green-800">Completed</span>
                 {{if gt .Completed 0}}
-                    <a href="/queue/jobs?queue={{.Name}}&state=completed" class="font-semibold text-green-900 hover:text-green-700">{{.Completed}}</a>
+                    <a href="{{basePath}}/queue/jobs?prefix={{urlquery .Prefix}}&queue={{.Name}}&state=completed" class="font-semibold text-green-900 hover:text-green-700">{{.Completed}}</a>
                 {{else}}
                     <span class="text-gray-400">{{.Completed}}</span>
                 {{end}}
@@ -76,7 +81,7 @@ const queueListTmpl = `
             <div class="flex items-center justify-between rounded-md bg-red-50 px-2 py-1">
                 <span class="text-red-800">Failed</span>
                 {{if gt .Failed 0}}
-                    <a href="/queue/jobs?queue={{.Name}}&state=failed" class="font-semibold text-red-900 hover:text-red-700">{{.Failed}}</a>
+                    <a href="{{basePath}}/queue/jobs?prefix={{urlquery .Prefix}}&queue={{.Name}}&state=failed" class="font-semibold text-red-900 hover:text-red-700">{{.Failed}}</a>
                 {{else}}
                     <span class="text-gray-400">{{.Failed}}</span>
                 {{end}}
@@ -84,7 +89,7 @@ const queueListTmpl = `
             <div class="flex items-center justify-between rounded-md bg-purple-50 px-2 py-1">
                 <span class="text-purple-800">Delayed</span>
                 {{if gt .Delayed 0}}
-                    <a href="/queue/jobs?queue={{.Name}}&state=delayed" class="font-semibold text-purple-900 hover:text-purple-700">{{.Delayed}}</a>
+                    <a href="{{basePath}}/queue/jobs?prefix={{urlquery .Prefix}}&queue={{.Name}}&state=delayed" class="font-semibold text-purple-900 hover:text-purple-700">{{.Delayed}}</a>
                 {{else}}
                     <span class="text-gray-400">{{.Delayed}}</span>
                 {{end}}
@@ -110,21 +115,25 @@ const queueListTmpl = `
                 {{end}}
             </div>
         </div>
-        <div class="px-4 py-3 border-t border-gray-100 text-right">
-            <a href="/queue/{{.Name}}" class="text-sm font-medium text-indigo-600 hover:text-indigo-900">View →</a>
+        <div class="px-4 py-3 border-t border-gray-100 flex items-center justify-between">
+            {{template "queue-pause-button" .}}
+            {{if .WorkersKnown}}
+            <span class="text-xs {{if .NeedsWorkers}}rounded-full bg-red-100 px-2 py-0.5 font-semibold text-red-800{{else}}text-gray-500{{end}}">{{.Workers}} workers</span>
+            {{end}}
+            <a href="{{basePath}}/queue/{{.Name}}?prefix={{urlquery .Prefix}}" class="text-sm font-medium text-indigo-600 hover:text-indigo-900">View →</a>
         </div>
     </div>
     {{end}}
 </div>
 `
 
-func DashboardHandler(exp *explorer.Explorer, prefix string, cache *DashboardCache) http.HandlerFunc {
-	tmpl := template.Must(template.New("queues").Parse(queueListTmpl))
+func DashboardHandler(exp *explorer.Explorer, cache *DashboardCache) http.HandlerFunc {
+	tmpl := template.Must(newTemplate("queues").Parse(queueListTmpl + actionPartialsTmpl))
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		snapshot := cache.Get()
 		if len(snapshot.Stats) == 0 {
-			if err := RefreshDashboardCache(r.Context(), exp, prefix, cache); err != nil {
+			if err := RefreshDashboardCache(r.Context(), exp, exp.Prefixes(), cache); err != nil {
 				log.Printf("❌ dashboard snapshot refresh error: %v", err)
 				http.Error(w, fmt.Sprintf("Dashboard snapshot unavailable: %v", err), http.StatusServiceUnavailable)
 				return
@@ -133,7 +142,7 @@ func DashboardHandler(exp *explorer.Explorer, prefix string, cache *DashboardCac
 		}
 
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		err := tmpl.Execute(w, snapshot.Stats)
+		err := executeTemplate(w, r, tmpl, visibleQueueStats(r, snapshot.Stats))
 		if err != nil {
 			log.Printf("❌ Template execution error: %v", err)
 			http.Error(w, fmt.Sprintf("Template error: %v", err), http.StatusInternalServerError)
@@ -157,6 +166,13 @@ func JobListHandler(exp *explorer.Explorer) http.HandlerFunc {
 			http.Error(w, "queue and state parameters required", http.StatusBadRequest)
 			return
 		}
+		if !requireQueueRole(w, r, queueName, auth.RoleViewer) {
+			return
+		}
+		prefix, ok := requestPrefix(w, r, exp)
+		if !ok {
+			return
+		}
 
 		displayState := state
 		page := parsePositiveInt(r.URL.Query().Get("page"), 1)
@@ -174,7 +190,7 @@ func JobListHandler(exp *explorer.Explorer) http.HandlerFunc {
 		case query != "":
 			displayState = "all"
 			var results searchResults
-			results, err = searchJobsAcrossStates(r.Context(), exp, queueName, query, page, window)
+			results, err = searchJobsAcrossStates(r.Context(), exp, prefix, queueName, query, page, window)
 			jobs = results.Jobs
 			searchedJobs = results.SearchedJobs
 			windowLabel = results.WindowLabel
@@ -183,13 +199,13 @@ func JobListHandler(exp *explorer.Explorer) http.HandlerFunc {
 			displayState = "all"
 			limit = allStatesPageSize
 			offset = (page - 1) * allStatesPageSize
-			jobs, err = exp.GetJobsAcrossStatesPage(r.Context(), queueName, offset, limit)
+			jobs, err = exp.GetJobsAcrossStatesPage(r.Context(), prefix, queueName, offset, limit)
 			searchedJobs = len(jobs)
 			windowLabel = fmt.Sprintf("Showing jobs %d-%d from each state", offset+1, offset+limit)
 			hasNextPage = len(jobs) == limit
 		default:
 			offset = (page - 1) * statePageSize
-			jobs, err = exp.GetJobsByStatePage(r.Context(), queueName, state, offset, limit)
+			jobs, err = exp.GetJobsByStatePage(r.Context(), prefix, queueName, state, offset, limit)
 			searchedJobs = len(jobs)
 			windowLabel = fmt.Sprintf("Showing jobs %d-%d in %s", offset+1, offset+limit, state)
 			hasNextPage = len(jobs) == limit
@@ -200,6 +216,7 @@ func JobListHandler(exp *explorer.Explorer) http.HandlerFunc {
 		}
 
 		data := struct {
+			Prefix        string
 			Queue         string
 			State         string
 			Query         string
@@ -212,6 +229,7 @@ func JobListHandler(exp *explorer.Explorer) http.HandlerFunc {
 			WindowLabel   string
 			SearchedJobs  int
 		}{
+			Prefix:        prefix,
 			Queue:         queueName,
 			State:         displayState,
 			Query:         query,
@@ -226,45 +244,16 @@ func JobListHandler(exp *explorer.Explorer) http.HandlerFunc {
 		}
 
 		if r.Header.Get("HX-Request") != "" {
-			tmpl := template.Must(template.New("jobs").Funcs(template.FuncMap{
-				"add": func(a, b int) int { return a + b },
-				"sub": func(a, b int) int { return a - b },
-			}).Parse(jobListTmpl))
+			tmpl := template.Must(newTemplate("jobs").Parse(jobListTmpl + actionPartialsTmpl))
 			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-			if err := tmpl.Execute(w, pageData{Data: data}); err != nil {
+			if err := executeTemplate(w, r, tmpl, pageData{Data: data}); err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
 			return
 		}
 
-		err = renderShell(w, "Bull-der-dash - "+queueName, "Queue: "+queueName+" / "+state, jobListTmpl, data)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	}
-}
-
-// JobDetailHandler shows details for a specific job
-func JobDetailHandler(exp *explorer.Explorer) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		queueName := r.URL.Query().Get("queue")
-		jobID := r.URL.Query().Get("id")
-		if queueName == "" || jobID == "" {
-			http.Error(w, "queue and id parameters required", http.StatusBadRequest)
-			return
-		}
-
-		job, err := exp.GetJob(r.Context(), queueName, jobID)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Return JSON for now - we can add HTML template later
-		w.Header().Set("Content-Type", "application/json")
-		err = json.NewEncoder(w).Encode(job)
+		err = renderShell(w, r, "Bull-der-dash - "+queueName, "Queue: "+queueName+" / "+state, jobListTmpl, data)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -312,7 +301,7 @@ const jobListTmpl = `
     <div class="flex flex-wrap items-center justify-between gap-4">
         <div>
             <div class="text-sm uppercase tracking-wide text-gray-400">Queue</div>
-            <div class="text-xl font-semibold text-indigo-700">{{.Data.Queue}}</div>
+            <div class="text-xl font-semibold text-indigo-700">{{.Data.Queue}} <span class="text-sm font-normal text-gray-400">{{.Data.Prefix}}</span></div>
         </div>
         <div class="flex items-center gap-3">
             <span class="text-xs uppercase tracking-wide text-gray-400">State</span>
@@ -320,15 +309,16 @@ const jobListTmpl = `
             <span class="text-sm text-gray-500">({{len .Data.Jobs}})</span>
         </div>
         <div class="flex items-center gap-4 text-sm">
-            <a href="/queue/{{.Data.Queue}}" class="font-medium text-indigo-600 hover:text-indigo-800">← Back to Queue</a>
-            <a href="/" class="font-medium text-gray-500 hover:text-gray-700">All Queues</a>
+            <a href="{{basePath}}/queue/{{.Data.Queue}}?prefix={{urlquery .Data.Prefix}}" class="font-medium text-indigo-600 hover:text-indigo-800">← Back to Queue</a>
+            <a href="{{basePath}}/" class="font-medium text-gray-500 hover:text-gray-700">All Queues</a>
             {{if ne .Data.State "all"}}
-            <a href="/queue/jobs?queue={{.Data.Queue}}&state=all" class="font-medium text-gray-500 hover:text-gray-700">All States View</a>
+            <a href="{{basePath}}/queue/jobs?prefix={{urlquery .Data.Prefix}}&queue={{.Data.Queue}}&state=all" class="font-medium text-gray-500 hover:text-gray-700">All States View</a>
             {{end}}
         </div>
     </div>
 
-    <form class="flex flex-wrap items-end gap-3" method="get" action="/queue/jobs">
+    <form class="flex flex-wrap items-end gap-3" method="get" action="{{basePath}}/queue/jobs">
+        <input type="hidden" name="prefix" value="{{.Data.Prefix}}">
         <input type="hidden" name="queue" value="{{.Data.Queue}}">
         <input type="hidden" name="state" value="{{.Data.State}}">
         <label class="flex flex-col text-xs uppercase tracking-wide text-gray-400">
@@ -361,7 +351,7 @@ const jobListTmpl = `
         </button>
         {{if .Data.Query}}
         <a
-            href="/queue/jobs?queue={{.Data.Queue}}&state={{.Data.State}}"
+            href="{{basePath}}/queue/jobs?prefix={{urlquery .Data.Prefix}}&queue={{.Data.Queue}}&state={{.Data.State}}"
             class="h-9 rounded-md border border-gray-300 px-3 text-sm font-medium text-gray-600 hover:text-gray-900 flex items-center"
         >
             Clear
@@ -385,6 +375,9 @@ const jobListTmpl = `
                     <th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">Name</th>
                     <th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">Created</th>
                     <th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">Attempts</th>
+                    {{if eq .Data.State "active"}}
+                    <th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">Lock</th>
+                    {{end}}
                     <th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">Actions</th>
                 </tr>
             </thead>
@@ -395,12 +388,16 @@ const jobListTmpl = `
                     <td class="px-6 py-4 text-sm text-gray-900">{{.Name}}</td>
                     <td class="px-6 py-4 text-sm text-gray-500">{{.Timestamp.Format "2006-01-02 15:04:05"}}</td>
                     <td class="px-6 py-4 text-sm text-gray-500">{{.AttemptsMade}}</td>
+                    {{if eq $.Data.State "active"}}
+                    <td class="px-6 py-4 text-sm">{{template "job-lock" .}}</td>
+                    {{end}}
                     <td class="px-6 py-4 text-sm">
-                        <a href="/job/detail?queue={{.Queue}}&id={{.ID}}" 
+                        <a href="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{.Queue}}&id={{.ID}}" 
                            class="text-indigo-600 hover:text-indigo-900"
                            target="_blank">
                             View Details →
                         </a>
+                        {{template "job-buttons" .}}
                     </td>
                 </tr>
                 {{end}}
@@ -422,7 +419,7 @@ const jobListTmpl = `
         <div class="flex items-center gap-3">
             {{if .Data.HasPrevPage}}
             <a
-                href="/queue/jobs?queue={{.Data.Queue}}&state={{.Data.State}}&q={{.Data.Query}}&since={{.Data.SearchWindow}}&page={{sub .Data.Page 1}}"
+                href="{{basePath}}/queue/jobs?prefix={{urlquery .Data.Prefix}}&queue={{.Data.Queue}}&state={{.Data.State}}&q={{.Data.Query}}&since={{.Data.SearchWindow}}&page={{sub .Data.Page 1}}"
                 class="rounded-md border border-gray-300 px-3 py-2 font-medium text-gray-600 hover:text-gray-900"
             >
                 Previous
@@ -430,7 +427,7 @@ const jobListTmpl = `
             {{end}}
             {{if .Data.HasNextPage}}
             <a
-                href="/queue/jobs?queue={{.Data.Queue}}&state={{.Data.State}}&q={{.Data.Query}}&since={{.Data.SearchWindow}}&page={{add .Data.Page 1}}"
+                href="{{basePath}}/queue/jobs?prefix={{urlquery .Data.Prefix}}&queue={{.Data.Queue}}&state={{.Data.State}}&q={{.Data.Query}}&since={{.Data.SearchWindow}}&page={{add .Data.Page 1}}"
                 class="rounded-md border border-gray-300 px-3 py-2 font-medium text-gray-600 hover:text-gray-900"
             >
                 Next
@@ -444,15 +441,19 @@ const jobListTmpl = `
 type pageData struct {
 	Title    string
 	Subtitle string
-	Data     interface{}
+	// Environments feeds the nav switcher; it is empty with one environment.
+	Environments []environmentLink
+	Data         interface{}
 }
 
 const shellTmpl = `
 <!DOCTYPE html>
 <html>
     <head>
-        <script src="https://unpkg.com/htmx.org@1.9.10"></script>
-        <script src="https://cdn.tailwindcss.com"></script>
+        <meta name="htmx-config" content='{"includeIndicatorStyles":false,"allowEval":false}'>
+        <link rel="stylesheet" href="{{asset "tailwind.css"}}">
+        <script src="{{asset "htmx.min.js"}}"></script>
+        <script src="{{asset "bullderdash.js"}}"></script>
         <title>{{.Title}}</title>
     </head>
     <body class="bg-gray-50 p-10">
@@ -462,11 +463,23 @@ const shellTmpl = `
                 <h1 class="text-2xl font-bold text-indigo-600">🐂 Bullderdash Explorer</h1>
                 {{if .Subtitle}}<div class="text-sm text-gray-500">{{.Subtitle}}</div>{{end}}
             </div>
-            <div class="flex gap-4 text-sm text-gray-600">
-                <a href="/" class="hover:text-indigo-600">Home</a>
-                <a href="/search" class="font-medium text-indigo-600 hover:text-indigo-800">Search Jobs</a>
-                <a href="/metrics" target="_blank" class="hover:text-indigo-600">📊 Metrics</a>
-                <a href="/health" target="_blank" class="hover:text-indigo-600">💚 Health</a>
+            <div class="flex items-center gap-4 text-sm text-gray-600">
+                {{if .Environments}}
+                <div class="flex overflow-hidden rounded-md border border-gray-300 text-xs font-medium">
+                    {{range .Environments}}
+                    <a href="{{.URL}}" class="px-3 py-1 {{if .Current}}bg-indigo-600 text-white{{else}}text-gray-600 hover:bg-gray-50{{end}}">{{.Name}}</a>
+                    {{end}}
+                </div>
+                {{end}}
+                <a href="{{basePath}}/" class="hover:text-indigo-600">Home</a>
+                <a href="{{basePath}}/search" class="font-medium text-indigo-600 hover:text-indigo-800">Search Jobs</a>
+                <a href="{{basePath}}/audit" class="hover:text-indigo-600">📝 Audit</a>
+                <a href="{{rootPath}}/metrics" target="_blank" class="hover:text-indigo-600">📊 Metrics</a>
+                <a href="{{rootPath}}/health" target="_blank" class="hover:text-indigo-600">💚 Health</a>
+                {{with currentUser}}
+                <span class="border-l border-gray-200 pl-4 text-gray-500" title="Signed in with {{.Method}}">👤 {{.Name}}</span>
+                {{if eq .Method "oidc"}}<a href="{{rootPath}}/auth/logout" class="hover:text-indigo-600">Sign out</a>{{end}}
+                {{end}}
             </div>
         </div>
 
@@ -477,7 +490,7 @@ const shellTmpl = `
 `
 
 const homeContentTmpl = `
-<div id="queue-list" hx-get="/queues" hx-trigger="load, every 5s">
+<div id="queue-list" hx-get="{{basePath}}/queues" hx-trigger="load, every 5s">
     Loading queues...
 </div>
 `
@@ -490,7 +503,7 @@ const searchPageTmpl = `
         <div class="mt-1 text-sm text-gray-500">Searches a paged window from each state so results stay fast on large queues.</div>
     </div>
 
-    <form class="flex flex-wrap items-end gap-4" method="get" action="/queue/jobs">
+    <form class="flex flex-wrap items-end gap-4" method="get" action="{{basePath}}/search">
         <label class="flex flex-col text-xs uppercase tracking-wide text-gray-400">
             Queue
             <select
@@ -498,12 +511,15 @@ const searchPageTmpl = `
                 class="mt-1 w-64 rounded-md border border-gray-300 px-3 py-2 text-sm text-gray-800 focus:border-indigo-500 focus:outline-none focus:ring-1 focus:ring-indigo-500"
                 required
             >
-                {{range .Data.Queues}}
-                <option value="{{.}}" {{if eq . $.Data.SelectedQueue}}selected{{end}}>{{.}}</option>
+                {{range .Data.Prefixes}}
+                <optgroup label="{{.Prefix}}">
+                    {{range .Queues}}
+                    <option value="{{.Key}}" {{if eq .Key $.Data.SelectedQueue}}selected{{end}}>{{.Name}}</option>
+                    {{end}}
+                </optgroup>
                 {{end}}
             </select>
         </label>
-        <input type="hidden" name="state" value="all">
         <label class="flex flex-col text-xs uppercase tracking-wide text-gray-400">
             Query
             <input
@@ -524,11 +540,8 @@ const searchPageTmpl = `
 </div>
 `
 
-func renderShell(w http.ResponseWriter, title, subtitle, contentTmpl string, data interface{}) error {
-	tmpl, err := template.New("shell").Funcs(template.FuncMap{
-		"add": func(a, b int) int { return a + b },
-		"sub": func(a, b int) int { return a - b },
-	}).Parse(shellTmpl)
+func renderShell(w http.ResponseWriter, r *http.Request, title, subtitle, contentTmpl string, data interface{}) error {
+	tmpl, err := newTemplate("shell").Funcs(requestFuncs(r)).Parse(shellTmpl + actionPartialsTmpl)
 	if err != nil {
 		return err
 	}
@@ -540,12 +553,24 @@ func renderShell(w http.ResponseWriter, title, subtitle, contentTmpl string, dat
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	return tmpl.ExecuteTemplate(w, "shell", pageData{
-		Title:    title,
-		Subtitle: subtitle,
-		Data:     data,
+		Title:        title,
+		Subtitle:     subtitle,
+		Environments: environmentLinks(r),
+		Data:         data,
 	})
 }
 
+// requestPrefix resolves the prefix query parameter, answering 400 for
+// prefixes that are not configured.
+func requestPrefix(w http.ResponseWriter, r *http.Request, exp *explorer.Explorer) (string, bool) {
+	prefix, err := exp.ResolvePrefix(strings.TrimSpace(r.FormValue("prefix")))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", false
+	}
+	return prefix, true
+}
+
 func parsePositiveInt(raw string, fallback int) int {
 	if fallback < 1 {
 		fallback = 1
@@ -563,7 +588,7 @@ func parsePositiveInt(raw string, fallback int) int {
 // HomeHandler renders the main dashboard shell
 func HomeHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := renderShell(w, "Bull-der-dash", "", homeContentTmpl, nil)
+		err := renderShell(w, r, "Bull-der-dash", "", homeContentTmpl, nil)
 		if err != nil {
 			log.Printf("❌ renderShell error (home): %v", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -572,34 +597,43 @@ func HomeHandler() http.HandlerFunc {
 	}
 }
 
-// SearchPageHandler renders a global search form with queue selection
-func SearchPageHandler(exp *explorer.Explorer, prefix string, cache *DashboardCache) http.HandlerFunc {
+// SearchPageHandler renders a global search form with queue selection. The
+// form submits the selected queue as "<prefix>:<queue>" back here, and is
+// redirected to the job list search for that queue.
+func SearchPageHandler(exp *explorer.Explorer, cache *DashboardCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		selectedQueue := strings.TrimSpace(r.URL.Query().Get("queue"))
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if ref, ok := explorer.ParseQueueRef(selectedQueue); ok && query != "" {
+			target := url.Values{"prefix": {ref.Prefix}, "queue": {ref.Name}, "state": {"all"}, "q": {query}}
+			http.Redirect(w, r, basePath(r)+"/queue/jobs?"+target.Encode(), http.StatusFound)
+			return
+		}
+
 		snapshot := cache.Get()
 		queues := snapshot.Queues
 		if len(queues) == 0 {
-			if err := RefreshDashboardCache(r.Context(), exp, prefix, cache); err != nil {
+			if err := RefreshDashboardCache(r.Context(), exp, exp.Prefixes(), cache); err != nil {
 				log.Printf("❌ DiscoverQueues error (search): %v", err)
 				http.Error(w, fmt.Sprintf("DiscoverQueues error: %v", err), http.StatusInternalServerError)
 				return
 			}
 			queues = cache.Get().Queues
 		}
-		selectedQueue := strings.TrimSpace(r.URL.Query().Get("queue"))
-		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		queues = visibleQueueRefs(r, queues)
 		if selectedQueue == "" && len(queues) > 0 {
-			selectedQueue = queues[0]
+			selectedQueue = queues[0].Key()
 		}
 		data := struct {
-			Queues        []string
+			Prefixes      []searchPrefixGroup
 			SelectedQueue string
 			Query         string
 		}{
-			Queues:        queues,
+			Prefixes:      groupQueuesByPrefix(exp.Prefixes(), queues),
 			SelectedQueue: selectedQueue,
 			Query:         query,
 		}
-		err := renderShell(w, "Bull-der-dash - Search", "Search jobs across states in paged windows", searchPageTmpl, data)
+		err := renderShell(w, r, "Bull-der-dash - Search", "Search jobs across states in paged windows", searchPageTmpl, data)
 		if err != nil {
 			log.Printf("❌ renderShell error (search): %v", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -608,9 +642,34 @@ func SearchPageHandler(exp *explorer.Explorer, prefix string, cache *DashboardCa
 	}
 }
 
+type searchPrefixGroup struct {
+	Prefix string
+	Queues []explorer.QueueRef
+}
+
+// groupQueuesByPrefix groups queues under their prefix, in configured prefix
+// order, skipping prefixes without queues.
+func groupQueuesByPrefix(prefixes []string, queues []explorer.QueueRef) []searchPrefixGroup {
+	groups := make([]searchPrefixGroup, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		group := searchPrefixGroup{Prefix: prefix}
+		for _, queue := range queues {
+			if queue.Prefix == prefix {
+				group.Queues = append(group.Queues, queue)
+			}
+		}
+		if len(group.Queues) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
 type queueDetailPageData struct {
 	Stat            explorer.QueueStats
 	SummaryHTML     template.HTML
+	EventsHTML      template.HTML
+	Tab             string
 	Waiting         []explorer.JobSummary
 	Active          []explorer.JobSummary
 	Paused          []explorer.JobSummary
@@ -623,11 +682,31 @@ type queueDetailPageData struct {
 
 type queueSummaryViewData struct {
 	Stat explorer.QueueStats
+	// RateLimit describes the configured global rate limit, e.g. "100 / 1s".
+	RateLimit string
+	ResetIn   string
+	// ConcurrencyPercent is Active against the global concurrency, capped at 100.
+	ConcurrencyPercent int64
+}
+
+func newQueueSummaryViewData(stat explorer.QueueStats) queueSummaryViewData {
+	data := queueSummaryViewData{Stat: stat}
+	limits := stat.Limits
+	if limits.RateLimitMax > 0 && limits.RateLimitDuration > 0 {
+		data.RateLimit = fmt.Sprintf("%d / %s", limits.RateLimitMax, limits.RateLimitDuration)
+	}
+	if limits.LimiterResetIn > 0 {
+		data.ResetIn = formatJobDuration(limits.LimiterResetIn)
+	}
+	if limits.GlobalConcurrency > 0 {
+		data.ConcurrencyPercent = min(stat.Active*100/limits.GlobalConcurrency, 100)
+	}
+	return data
 }
 
 // QueueSummaryHandler renders the fast, polled summary for a queue.
-func QueueSummaryHandler(exp *explorer.Explorer, prefix string) http.HandlerFunc {
-	tmpl := template.Must(template.New("queue-summary").Parse(queueSummaryTmpl))
+func QueueSummaryHandler(exp *explorer.Explorer) http.HandlerFunc {
+	tmpl := template.Must(newTemplate("queue-summary").Parse(queueSummaryTmpl + actionPartialsTmpl))
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		queueName := strings.TrimSpace(r.URL.Query().Get("queue"))
@@ -635,6 +714,13 @@ func QueueSummaryHandler(exp *explorer.Explorer, prefix string) http.HandlerFunc
 			http.Error(w, "queue parameter required", http.StatusBadRequest)
 			return
 		}
+		if !requireQueueRole(w, r, queueName, auth.RoleViewer) {
+			return
+		}
+		prefix, ok := requestPrefix(w, r, exp)
+		if !ok {
+			return
+		}
 
 		stat, err := loadFastQueueStat(r.Context(), exp, prefix, queueName)
 		if err != nil {
@@ -643,7 +729,7 @@ func QueueSummaryHandler(exp *explorer.Explorer, prefix string) http.HandlerFunc
 		}
 
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		if err := tmpl.Execute(w, pageData{Data: queueSummaryViewData{Stat: stat}}); err != nil {
+		if err := executeTemplate(w, r, tmpl, pageData{Data: newQueueSummaryViewData(stat)}); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -651,7 +737,7 @@ func QueueSummaryHandler(exp *explorer.Explorer, prefix string) http.HandlerFunc
 }
 
 // QueueDetailHandler shows detailed view of a single queue with all job states
-func QueueDetailHandler(exp *explorer.Explorer, prefix string) http.HandlerFunc {
+func QueueDetailHandler(exp *explorer.Explorer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Extract queue name from path: /queue/{name}
 		queueName := strings.TrimPrefix(r.URL.Path, "/queue/")
@@ -659,6 +745,13 @@ func QueueDetailHandler(exp *explorer.Explorer, prefix string) http.HandlerFunc
 			http.Error(w, "queue name required", http.StatusBadRequest)
 			return
 		}
+		if !requireQueueRole(w, r, queueName, auth.RoleViewer) {
+			return
+		}
+		prefix, ok := requestPrefix(w, r, exp)
+		if !ok {
+			return
+		}
 
 		stat, err := loadFastQueueStat(r.Context(), exp, prefix, queueName)
 		if err != nil {
@@ -666,25 +759,41 @@ func QueueDetailHandler(exp *explorer.Explorer, prefix string) http.HandlerFunc
 			return
 		}
 
-		// Get jobs in each state
-		waiting, _ := exp.GetJobsByState(r.Context(), queueName, "waiting", 50)
-		active, _ := exp.GetJobsByState(r.Context(), queueName, "active", 50)
-		paused, _ := exp.GetJobsByState(r.Context(), queueName, "paused", 50)
-		prioritized, _ := exp.GetJobsByState(r.Context(), queueName, "prioritized", 50)
-		waitingChildren, _ := exp.GetJobsByState(r.Context(), queueName, "waiting-children", 50)
-		completed, _ := exp.GetJobsByState(r.Context(), queueName, "completed", 50)
-		failed, _ := exp.GetJobsByState(r.Context(), queueName, "failed", 50)
-		delayed, _ := exp.GetJobsByState(r.Context(), queueName, "delayed", 50)
-
-		summaryHTML, err := renderQueueSummaryHTML(stat)
+		summaryHTML, err := renderQueueSummaryHTML(r, stat)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		eventsHTML, err := renderQueueEventsPanelHTML(r, prefix, queueName)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		if r.URL.Query().Get("tab") == "schedulers" {
+			data := queueDetailPageData{Stat: stat, SummaryHTML: summaryHTML, EventsHTML: eventsHTML, Tab: "schedulers"}
+			if err := renderShell(w, r, "Bull-der-dash - "+queueName, "Queue: "+queueName, queueDetailTmpl, data); err != nil {
+				log.Printf("❌ renderShell error (queue=%s): %v", queueName, err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		// Get jobs in each state
+		waiting, _ := exp.GetJobsByState(r.Context(), prefix, queueName, "waiting", 50)
+		active, _ := exp.GetJobsByState(r.Context(), prefix, queueName, "active", 50)
+		paused, _ := exp.GetJobsByState(r.Context(), prefix, queueName, "paused", 50)
+		prioritized, _ := exp.GetJobsByState(r.Context(), prefix, queueName, "prioritized", 50)
+		waitingChildren, _ := exp.GetJobsByState(r.Context(), prefix, queueName, "waiting-children", 50)
+		completed, _ := exp.GetJobsByState(r.Context(), prefix, queueName, "completed", 50)
+		failed, _ := exp.GetJobsByState(r.Context(), prefix, queueName, "failed", 50)
+		delayed, _ := exp.GetJobsByState(r.Context(), prefix, queueName, "delayed", 50)
+
 		data := queueDetailPageData{
 			Stat:            stat,
 			SummaryHTML:     summaryHTML,
+			EventsHTML:      eventsHTML,
+			Tab:             "jobs",
 			Waiting:         waiting,
 			Active:          active,
 			Paused:          paused,
@@ -695,7 +804,7 @@ func QueueDetailHandler(exp *explorer.Explorer, prefix string) http.HandlerFunc
 			Delayed:         delayed,
 		}
 
-		err = renderShell(w, "Bull-der-dash - "+queueName, "Queue: "+queueName, queueDetailTmpl, data)
+		err = renderShell(w, r, "Bull-der-dash - "+queueName, "Queue: "+queueName, queueDetailTmpl, data)
 		if err != nil {
 			log.Printf("❌ renderShell error (queue=%s): %v", queueName, err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -715,14 +824,14 @@ func loadFastQueueStat(ctx context.Context, exp *explorer.Explorer, prefix, queu
 	return stats[0], nil
 }
 
-func renderQueueSummaryHTML(stat explorer.QueueStats) (template.HTML, error) {
-	tmpl, err := template.New("queue-summary").Parse(queueSummaryTmpl)
+func renderQueueSummaryHTML(r *http.Request, stat explorer.QueueStats) (template.HTML, error) {
+	tmpl, err := newTemplate("queue-summary").Funcs(requestFuncs(r)).Parse(queueSummaryTmpl + actionPartialsTmpl)
 	if err != nil {
 		return "", err
 	}
 
 	var b strings.Builder
-	if err := tmpl.Execute(&b, pageData{Data: queueSummaryViewData{Stat: stat}}); err != nil {
+	if err := tmpl.Execute(&b, pageData{Data: newQueueSummaryViewData(stat)}); err != nil {
 		return "", err
 	}
 
@@ -730,15 +839,27 @@ func renderQueueSummaryHTML(stat explorer.QueueStats) (template.HTML, error) {
 }
 
 const queueSummaryTmpl = `
-<div id="queue-summary" hx-get="/queue/summary?queue={{.Data.Stat.Name}}" hx-trigger="every 5s" hx-swap="outerHTML">
-<div class="grid grid-cols-1 lg:grid-cols-3 gap-4 mb-8">
+<div id="queue-summary" hx-get="{{basePath}}/queue/summary?prefix={{urlquery .Data.Stat.Prefix}}&queue={{.Data.Stat.Name}}" hx-trigger="every 5s" hx-swap="outerHTML">
+<div class="grid grid-cols-1 md:grid-cols-2 xl:grid-cols-4 gap-4 mb-8">
     <div class="rounded-lg border border-gray-200 p-4">
-        <div class="text-xs uppercase text-gray-400">Queue</div>
-        <div class="text-lg font-semibold text-indigo-700">{{.Data.Stat.Name}}</div>
+        <div class="flex items-center justify-between">
+            <div class="text-xs uppercase text-gray-400">Queue</div>
+            {{template "queue-pause-button" .Data.Stat}}
+        </div>
+        <div class="flex items-center gap-2">
+            <div class="text-lg font-semibold text-indigo-700">{{.Data.Stat.Name}}</div>
+            {{template "queue-paused-badge" .Data.Stat}}
+        </div>
+        <div class="text-xs text-gray-400">prefix {{.Data.Stat.Prefix}}</div>
         <div class="mt-2 text-sm text-gray-600">Total jobs</div>
         <div class="text-2xl font-bold text-gray-900">{{.Data.Stat.Total}}</div>
+        {{if .Data.Stat.WorkersKnown}}
+        <div class="mt-2 text-sm {{if .Data.Stat.NeedsWorkers}}font-semibold text-red-700{{else}}text-gray-600{{end}}">
+            {{.Data.Stat.Workers}} workers connected{{if .Data.Stat.NeedsWorkers}}: jobs are waiting with nobody to process them{{end}}
+        </div>
+        {{end}}
         <div class="mt-4">
-            <a href="/queue/jobs?queue={{.Data.Stat.Name}}&state=all" class="inline-flex items-center rounded-md bg-indigo-600 px-3 py-2 text-xs font-semibold text-white hover:bg-indigo-700">
+            <a href="{{basePath}}/queue/jobs?prefix={{urlquery .Data.Stat.Prefix}}&queue={{.Data.Stat.Name}}&state=all" class="inline-flex items-center rounded-md bg-indigo-600 px-3 py-2 text-xs font-semibold text-white hover:bg-indigo-700">
                 Search Jobs →
             </a>
         </div>
@@ -752,7 +873,7 @@ const queueSummaryTmpl = `
             </div>
             <div class="flex items-center justify-between rounded-md bg-blue-50 px-2 py-1">
                 <span class="text-blue-800">Active</span>
-                <span class="font-semibold text-blue-900">{{.Data.Stat.Active}}</span>
+                <span class="font-semibold text-blue-900">{{.Data.Stat.Active}}{{if .Data.Stat.ActiveUnlocked}} <span class="text-xs font-semibold text-red-700" title="Active jobs whose lock has expired or is missing">({{.Data.Stat.ActiveUnlocked}} unlocked)</span>{{end}}</span>
             </div>
             <div class="flex items-center justify-between rounded-md bg-purple-50 px-2 py-1">
                 <span class="text-purple-800">Delayed</span>
@@ -789,6 +910,39 @@ const queueSummaryTmpl = `
             </div>
         </div>
     </div>
+    <div class="rounded-lg border border-gray-200 p-4">
+        <div class="flex items-center justify-between">
+            <div class="text-xs uppercase text-gray-400">Limits</div>
+            {{if .Data.Stat.Limits.RateLimited}}
+            <span class="rounded-full bg-red-100 px-2 py-0.5 text-xs font-semibold text-red-800">Rate limited</span>
+            {{end}}
+        </div>
+        <div class="mt-2 space-y-2 text-sm">
+            <div class="flex items-center justify-between">
+                <span class="text-gray-600">Rate limit</span>
+                <span class="font-semibold text-gray-900">{{if .Data.RateLimit}}{{.Data.RateLimit}}{{else}}<span class="font-normal text-gray-400">not set</span>{{end}}</span>
+            </div>
+            {{if .Data.ResetIn}}
+            <div class="flex items-center justify-between text-xs text-gray-500">
+                <span>{{if .Data.Stat.Limits.RateLimited}}Throttled{{else}}{{.Data.Stat.Limits.LimiterCount}} jobs this window{{end}}</span>
+                <span>resets in {{.Data.ResetIn}}</span>
+            </div>
+            {{end}}
+            <div class="flex items-center justify-between">
+                <span class="text-gray-600">Concurrency</span>
+                <span class="font-semibold text-gray-900">{{.Data.Stat.Active}} <span class="font-normal text-gray-400">/ {{if .Data.Stat.Limits.GlobalConcurrency}}{{.Data.Stat.Limits.GlobalConcurrency}}{{else}}unlimited{{end}}</span></span>
+            </div>
+            {{if .Data.Stat.Limits.GlobalConcurrency}}
+            <div class="h-2 rounded-full bg-gray-100">
+                <div class="h-2 rounded-full {{if ge .Data.ConcurrencyPercent 100}}bg-amber-500{{else}}bg-blue-500{{end}}" data-percent="{{.Data.ConcurrencyPercent}}"></div>
+            </div>
+            {{end}}
+            <div class="flex items-center justify-between text-xs text-gray-500">
+                <span>Priority counter</span>
+                <span>{{.Data.Stat.Limits.PriorityCounter}}</span>
+            </div>
+        </div>
+    </div>
 </div>
 </div>
 `
@@ -797,6 +951,21 @@ const queueDetailTmpl = `
 <div id="queue-detail">
 {{.Data.SummaryHTML}}
 
+<div hx-get="{{basePath}}/queue/workers?prefix={{urlquery .Data.Stat.Prefix}}&queue={{urlquery .Data.Stat.Name}}" hx-trigger="load" hx-swap="outerHTML"></div>
+
+{{.Data.EventsHTML}}
+
+<div class="mb-6 flex gap-6 border-b border-gray-200 text-sm font-medium">
+    <a href="{{basePath}}/queue/{{.Data.Stat.Name}}?prefix={{urlquery .Data.Stat.Prefix}}" class="-mb-px border-b-2 pb-2 {{if eq .Data.Tab "schedulers"}}border-transparent text-gray-500 hover:text-gray-700{{else}}border-indigo-600 text-indigo-700{{end}}">Jobs</a>
+    <a href="{{basePath}}/queue/{{.Data.Stat.Name}}?prefix={{urlquery .Data.Stat.Prefix}}&tab=schedulers" class="-mb-px border-b-2 pb-2 {{if eq .Data.Tab "schedulers"}}border-indigo-600 text-indigo-700{{else}}border-transparent text-gray-500 hover:text-gray-700{{end}}">Schedulers</a>
+</div>
+
+{{if eq .Data.Tab "schedulers"}}
+<div hx-get="{{basePath}}/queue/schedulers?prefix={{urlquery .Data.Stat.Prefix}}&queue={{urlquery .Data.Stat.Name}}" hx-trigger="load" hx-swap="outerHTML">
+    <div class="text-sm text-gray-500">Loading schedulers...</div>
+</div>
+{{else}}
+
 <table class="min-w-full divide-y divide-gray-200 mb-8">
     <thead class="bg-gray-50">
         <tr>
@@ -870,6 +1039,121 @@ const queueDetailTmpl = `
     </tbody>
 </table>
 
+<div class="rounded-lg border border-gray-200 p-4 mb-8">
+    <div class="text-xs uppercase text-gray-400">Maintenance</div>
+    <div class="mt-1 text-sm text-gray-500">Remove finished jobs older than a grace period in throttled batches. Preview first to see how many would be removed.</div>
+    <form class="mt-3 flex flex-wrap items-end gap-3">
+        <input type="hidden" name="prefix" value="{{.Data.Stat.Prefix}}">
+        <input type="hidden" name="queue" value="{{.Data.Stat.Name}}">
+        <label class="flex flex-col text-xs uppercase tracking-wide text-gray-400">
+            State
+            <select name="state" class="mt-1 h-10 rounded-md border border-gray-300 px-3 py-2 text-sm text-gray-800">
+                <option value="completed">completed ({{.Data.Stat.Completed}})</option>
+                <option value="failed">failed ({{.Data.Stat.Failed}})</option>
+            </select>
+        </label>
+        <label class="flex flex-col text-xs uppercase tracking-wide text-gray-400">
+            Finished more than
+            <select name="grace" class="mt-1 h-10 rounded-md border border-gray-300 px-3 py-2 text-sm text-gray-800">
+                <option value="1h">1 hour ago</option>
+                <option value="24h" selected>24 hours ago</option>
+                <option value="7d">7 days ago</option>
+                <option value="30d">30 days ago</option>
+                <option value="">any time</option>
+            </select>
+        </label>
+        <label class="flex flex-col text-xs uppercase tracking-wide text-gray-400">
+            Limit
+            <input type="number" name="limit" value="10000" min="1" max="10000" class="mt-1 w-32 rounded-md border border-gray-300 px-3 py-2 text-sm text-gray-800">
+        </label>
+        {{if can "admin" .Data.Stat.Name}}
+        <button
+            type="button"
+            hx-post="{{basePath}}/queue/clean"
+            hx-include="closest form"
+            hx-vals='{"dryrun": "true"}'
+            hx-target="#clean-result"
+            class="h-9 rounded-md border border-gray-300 px-3 text-sm font-medium text-gray-700 hover:bg-gray-50"
+        >
+            Preview
+        </button>
+        <button
+            type="button"
+            hx-post="{{basePath}}/queue/clean"
+            hx-include="closest form"
+            hx-vals='{"dryrun": "false"}'
+            hx-target="#clean-result"
+            hx-confirm="Permanently remove matching jobs from {{.Data.Stat.Name}}?"
+            class="h-9 rounded-md bg-red-600 px-4 text-sm font-medium text-white hover:bg-red-700"
+        >
+            Clean
+        </button>
+        {{else}}
+        <span class="self-center text-sm text-gray-500">Cleaning needs the admin role.</span>
+        {{end}}
+        <div id="clean-result" class="self-center"></div>
+    </form>
+</div>
+
+<div class="rounded-lg border border-gray-200 p-4 mb-8">
+    <div class="text-xs uppercase text-gray-400">Bulk retry failed jobs</div>
+    <div class="mt-1 text-sm text-gray-500">Match on job name or failed reason (same rules as search). Preview the matches, then retry them in throttled batches.</div>
+    <form class="mt-3 flex flex-wrap items-end gap-3" hx-get="{{basePath}}/queue/retry/preview" hx-target="#bulk-retry-panel">
+        <input type="hidden" name="prefix" value="{{.Data.Stat.Prefix}}">
+        <input type="hidden" name="queue" value="{{.Data.Stat.Name}}">
+        <label class="flex flex-col text-xs uppercase tracking-wide text-gray-400">
+            Filter
+            <input type="text" name="q" placeholder="e.g. ECONNREFUSED" class="mt-1 w-80 rounded-md border border-gray-300 px-3 py-2 text-sm text-gray-800">
+        </label>
+        <label class="flex items-center gap-2 text-sm text-gray-600">
+            <input type="checkbox" name="regex" value="true"> Regex
+        </label>
+        <button type="submit" class="h-9 rounded-md border border-gray-300 px-3 text-sm font-medium text-gray-700 hover:bg-gray-50">
+            Preview ({{.Data.Stat.Failed}} failed)
+        </button>
+    </form>
+    <div id="bulk-retry-panel"></div>
+</div>
+
+<div class="rounded-lg border border-red-200 p-4 mb-8">
+    <div class="text-xs uppercase text-red-400">Danger zone</div>
+    <div class="mt-1 text-sm text-gray-500">Drain removes every waiting, delayed and prioritized job. Obliterate pauses the queue and deletes every key it owns. Type <code class="rounded bg-gray-100 px-1">{{.Data.Stat.Name}}</code> to confirm.</div>
+    <form class="mt-3 flex flex-wrap items-end gap-3">
+        <input type="hidden" name="prefix" value="{{.Data.Stat.Prefix}}">
+        <input type="hidden" name="queue" value="{{.Data.Stat.Name}}">
+        <label class="flex flex-col text-xs uppercase tracking-wide text-gray-400">
+            Queue name
+            <input type="text" name="confirm" autocomplete="off" placeholder="{{.Data.Stat.Name}}" class="mt-1 w-64 rounded-md border border-gray-300 px-3 py-2 text-sm text-gray-800">
+        </label>
+        <label class="flex items-center gap-2 text-sm text-gray-600">
+            <input type="checkbox" name="force" value="true"> Obliterate even with {{.Data.Stat.Active}} active jobs
+        </label>
+        {{if can "admin" .Data.Stat.Name}}
+        <button
+            type="button"
+            hx-post="{{basePath}}/queue/drain"
+            hx-include="closest form"
+            hx-target="#danger-result"
+            class="h-9 rounded-md border border-red-300 px-3 text-sm font-medium text-red-700 hover:bg-red-50"
+        >
+            Drain
+        </button>
+        <button
+            type="button"
+            hx-post="{{basePath}}/queue/obliterate"
+            hx-include="closest form"
+            hx-target="#danger-result"
+            class="h-9 rounded-md bg-red-700 px-4 text-sm font-medium text-white hover:bg-red-800"
+        >
+            Obliterate
+        </button>
+        {{else}}
+        <span class="self-center text-sm text-gray-500">Draining and obliterating need the admin role.</span>
+        {{end}}
+        <div id="danger-result" class="self-center"></div>
+    </form>
+</div>
+
 <div class="space-y-8">
     {{if .Data.Waiting}}
     <div>
@@ -887,7 +1171,10 @@ const queueDetailTmpl = `
                 <tr class="hover:bg-gray-50">
                     <td class="px-6 py-4 text-sm font-mono text-gray-600">{{.ID}}</td>
                     <td class="px-6 py-4 text-sm text-gray-900">{{.Name}}</td>
-                    <td class="px-6 py-4 text-sm"><a href="/job/detail?queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a></td>
+                    <td class="px-6 py-4 text-sm">
+                        <a href="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a>
+                        {{template "job-buttons" .}}
+                    </td>
                 </tr>
                 {{end}}
             </tbody>
@@ -904,6 +1191,7 @@ const queueDetailTmpl = `
                     <th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">Job ID</th>
                     <th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">Name</th>
                     <th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">Attempts</th>
+                    <th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">Lock</th>
                     <th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">Actions</th>
                 </tr>
             </thead>
@@ -913,7 +1201,11 @@ const queueDetailTmpl = `
                     <td class="px-6 py-4 text-sm font-mono text-gray-600">{{.ID}}</td>
                     <td class="px-6 py-4 text-sm text-gray-900">{{.Name}}</td>
                     <td class="px-6 py-4 text-sm text-gray-600">{{.AttemptsMade}}</td>
-                    <td class="px-6 py-4 text-sm"><a href="/job/detail?queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a></td>
+                    <td class="px-6 py-4 text-sm">{{template "job-lock" .}}</td>
+                    <td class="px-6 py-4 text-sm">
+                        <a href="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a>
+                        {{template "job-buttons" .}}
+                    </td>
                 </tr>
                 {{end}}
             </tbody>
@@ -937,7 +1229,10 @@ const queueDetailTmpl = `
                 <tr class="hover:bg-gray-50">
                     <td class="px-6 py-4 text-sm font-mono text-gray-600">{{.ID}}</td>
                     <td class="px-6 py-4 text-sm text-gray-900">{{.Name}}</td>
-                    <td class="px-6 py-4 text-sm"><a href="/job/detail?queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a></td>
+                    <td class="px-6 py-4 text-sm">
+                        <a href="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a>
+                        {{template "job-buttons" .}}
+                    </td>
                 </tr>
                 {{end}}
             </tbody>
@@ -961,7 +1256,10 @@ const queueDetailTmpl = `
                 <tr class="hover:bg-gray-50">
                     <td class="px-6 py-4 text-sm font-mono text-gray-600">{{.ID}}</td>
                     <td class="px-6 py-4 text-sm text-gray-900">{{.Name}}</td>
-                    <td class="px-6 py-4 text-sm"><a href="/job/detail?queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a></td>
+                    <td class="px-6 py-4 text-sm">
+                        <a href="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a>
+                        {{template "job-buttons" .}}
+                    </td>
                 </tr>
                 {{end}}
             </tbody>
@@ -985,7 +1283,10 @@ const queueDetailTmpl = `
                 <tr class="hover:bg-gray-50">
                     <td class="px-6 py-4 text-sm font-mono text-gray-600">{{.ID}}</td>
                     <td class="px-6 py-4 text-sm text-gray-900">{{.Name}}</td>
-                    <td class="px-6 py-4 text-sm"><a href="/job/detail?queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a></td>
+                    <td class="px-6 py-4 text-sm">
+                        <a href="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a>
+                        {{template "job-buttons" .}}
+                    </td>
                 </tr>
                 {{end}}
             </tbody>
@@ -995,7 +1296,19 @@ const queueDetailTmpl = `
 
     {{if .Data.Delayed}}
     <div>
-        <h2 class="text-lg font-semibold text-purple-700 mb-3">Delayed</h2>
+        <div class="flex items-center justify-between mb-3">
+            <h2 class="text-lg font-semibold text-purple-700">Delayed</h2>
+            {{if can "operator" .Data.Stat.Name}}
+            <button
+                hx-post="{{basePath}}/queue/promote?prefix={{urlquery .Data.Stat.Prefix}}&queue={{urlquery .Data.Stat.Name}}"
+                hx-confirm="Promote all {{.Data.Stat.Delayed}} delayed jobs in {{.Data.Stat.Name}} now?"
+                hx-swap="outerHTML"
+                class="rounded-md border border-purple-300 px-3 py-1 text-xs font-semibold text-purple-700 hover:bg-purple-50"
+            >
+                Promote all delayed
+            </button>
+            {{end}}
+        </div>
         <table class="min-w-full divide-y divide-gray-200">
             <thead class="bg-gray-50">
                 <tr>
@@ -1009,7 +1322,10 @@ const queueDetailTmpl = `
                 <tr class="hover:bg-gray-50">
                     <td class="px-6 py-4 text-sm font-mono text-gray-600">{{.ID}}</td>
                     <td class="px-6 py-4 text-sm text-gray-900">{{.Name}}</td>
-                    <td class="px-6 py-4 text-sm"><a href="/job/detail?queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a></td>
+                    <td class="px-6 py-4 text-sm">
+                        <a href="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a>
+                        {{template "job-buttons" .}}
+                    </td>
                 </tr>
                 {{end}}
             </tbody>
@@ -1033,7 +1349,10 @@ const queueDetailTmpl = `
                 <tr class="hover:bg-gray-50">
                     <td class="px-6 py-4 text-sm font-mono text-gray-600">{{.ID}}</td>
                     <td class="px-6 py-4 text-sm text-gray-900">{{.Name}}</td>
-                    <td class="px-6 py-4 text-sm"><a href="/job/detail?queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a></td>
+                    <td class="px-6 py-4 text-sm">
+                        <a href="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a>
+                        {{template "job-buttons" .}}
+                    </td>
                 </tr>
                 {{end}}
             </tbody>
@@ -1059,7 +1378,10 @@ const queueDetailTmpl = `
                     <td class="px-6 py-4 text-sm font-mono text-gray-600">{{.ID}}</td>
                     <td class="px-6 py-4 text-sm text-gray-900">{{.Name}}</td>
                     <td class="px-6 py-4 text-sm text-gray-600">{{.AttemptsMade}}</td>
-                    <td class="px-6 py-4 text-sm"><a href="/job/detail?queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a></td>
+                    <td class="px-6 py-4 text-sm">
+                        <a href="{{basePath}}/job/detail?prefix={{urlquery .Prefix}}&queue={{.Queue}}&id={{.ID}}" class="text-indigo-600 hover:text-indigo-900" target="_blank">View →</a>
+                        {{template "job-buttons" .}}
+                    </td>
                 </tr>
                 {{end}}
             </tbody>
@@ -1067,4 +1389,6 @@ const queueDetailTmpl = `
     </div>
     {{end}}
 </div>
+{{end}}
+</div>
 `
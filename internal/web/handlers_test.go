@@ -0,0 +1,130 @@
+package web
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+func TestNewQueueSummaryViewDataLimits(t *testing.T) {
+	stat := explorer.QueueStats{
+		Name:   "email",
+		Active: 8,
+		Limits: explorer.QueueLimits{
+			GlobalConcurrency: 4,
+			RateLimitMax:      100,
+			RateLimitDuration: time.Minute,
+			LimiterCount:      100,
+			LimiterResetIn:    1500 * time.Millisecond,
+			RateLimited:       true,
+		},
+	}
+
+	data := newQueueSummaryViewData(stat)
+	if data.RateLimit != "100 / 1m0s" {
+		t.Fatalf("unexpected rate limit %q", data.RateLimit)
+	}
+	if data.ResetIn != "1.5s" {
+		t.Fatalf("unexpected reset %q", data.ResetIn)
+	}
+	if data.ConcurrencyPercent != 100 {
+		t.Fatalf("expected concurrency capped at 100%%, got %d", data.ConcurrencyPercent)
+	}
+
+	html, err := renderQueueSummaryHTML(httptest.NewRequest(http.MethodGet, "/", nil), stat)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for _, want := range []string{"Rate limited", "resets in 1.5s", "100 / 1m0s"} {
+		if !strings.Contains(string(html), want) {
+			t.Fatalf("expected summary to contain %q", want)
+		}
+	}
+}
+
+func TestNewQueueSummaryViewDataUnlimited(t *testing.T) {
+	data := newQueueSummaryViewData(explorer.QueueStats{Name: "email", Active: 3})
+	if data.RateLimit != "" || data.ResetIn != "" || data.ConcurrencyPercent != 0 {
+		t.Fatalf("expected no limits, got %+v", data)
+	}
+
+	html, err := renderQueueSummaryHTML(httptest.NewRequest(http.MethodGet, "/", nil), explorer.QueueStats{Name: "email", Active: 3})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if strings.Contains(string(html), "Rate limited") || !strings.Contains(string(html), "unlimited") {
+		t.Fatalf("unexpected summary for an unlimited queue")
+	}
+}
+
+func TestQueueTemplatesFlagQueuesWithoutWorkers(t *testing.T) {
+	stat := explorer.QueueStats{Name: "email", Wait: 12, WorkersKnown: true}
+
+	html, err := renderQueueSummaryHTML(httptest.NewRequest(http.MethodGet, "/", nil), stat)
+	if err != nil {
+		t.Fatalf("render summary: %v", err)
+	}
+	if !strings.Contains(string(html), "nobody to process them") {
+		t.Fatalf("expected the summary to flag the missing workers")
+	}
+
+	tmpl := template.Must(newTemplate("queues").Parse(queueListTmpl + actionPartialsTmpl))
+	var b strings.Builder
+	if err := tmpl.Execute(&b, []explorer.QueueStats{stat}); err != nil {
+		t.Fatalf("render dashboard: %v", err)
+	}
+	if !strings.Contains(b.String(), "bg-red-100 px-2 py-0.5 font-semibold text-red-800\">0 workers") {
+		t.Fatalf("expected the dashboard card to flag the missing workers")
+	}
+}
+
+func TestJobListTemplateShowsLocksForActiveJobs(t *testing.T) {
+	tmpl := template.Must(newTemplate("jobs").Parse(jobListTmpl + actionPartialsTmpl))
+
+	data := map[string]any{
+		"Queue":         "email",
+		"State":         "active",
+		"Query":         "",
+		"SearchWindow":  "",
+		"WindowOptions": searchWindowOptions,
+		"Page":          1,
+		"Jobs": []explorer.JobSummary{
+			{ID: "1", Queue: "email", State: "active", Lock: &explorer.JobLock{Token: "worker-1:1", ExpiresIn: 20 * time.Second}},
+			{ID: "2", Queue: "email", State: "active", Lock: &explorer.JobLock{}, ProcessedOn: time.Date(2026, 1, 1, 9, 30, 0, 0, time.Local)},
+		},
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, pageData{Data: data}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for _, want := range []string{">Lock</th>", "Locked", "20s left", "No lock", "since 09:30:00"} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("expected job list to contain %q", want)
+		}
+	}
+}
+
+func TestGroupQueuesByPrefix(t *testing.T) {
+	queues := []explorer.QueueRef{
+		{Prefix: "billing", Name: "invoices"},
+		{Prefix: "bull", Name: "email"},
+		{Prefix: "billing", Name: "refunds"},
+	}
+
+	groups := groupQueuesByPrefix([]string{"bull", "billing", "empty"}, queues)
+	if len(groups) != 2 {
+		t.Fatalf("expected empty prefixes to be skipped, got %+v", groups)
+	}
+	if groups[0].Prefix != "bull" || len(groups[0].Queues) != 1 {
+		t.Fatalf("unexpected first group: %+v", groups[0])
+	}
+	if groups[1].Prefix != "billing" || len(groups[1].Queues) != 2 || groups[1].Queues[1].Name != "refunds" {
+		t.Fatalf("unexpected second group: %+v", groups[1])
+	}
+}
@@ -0,0 +1,275 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+type jobStackTrace struct {
+	Attempt int
+	Trace   string
+}
+
+type jobDetailViewData struct {
+	Job             *explorer.Job
+	StateClass      string
+	MaxAttempts     int
+	CreatedAt       time.Time
+	ProcessedAt     time.Time
+	FinishedAt      time.Time
+	DelayedUntil    time.Time
+	Waited          string
+	Processing      string
+	ProgressPercent float64
+	HasPercent      bool
+	ProgressJSON    string
+	DataJSON        string
+	OptsJSON        string
+	ReturnValueJSON string
+	StackTraces     []jobStackTrace
+}
+
+const jobDetailTmpl = `
+{{with .Data}}
+<div class="space-y-6">
+    <div class="flex flex-wrap items-start justify-between gap-4">
+        <div>
+            <a href="{{basePath}}/queue/{{.Job.Queue}}?prefix={{urlquery .Job.Prefix}}" class="text-sm text-indigo-600 hover:text-indigo-900">← {{.Job.Queue}}</a>
+            <span class="ml-1 text-xs text-gray-400">{{.Job.Prefix}}</span>
+            <div class="mt-1 flex items-center gap-3">
+                <div class="text-xl font-semibold text-gray-900">{{if .Job.Name}}{{.Job.Name}}{{else}}(unnamed){{end}}</div>
+                <span class="font-mono text-sm text-gray-500">#{{.Job.ID}}</span>
+                <span class="rounded-full px-2 py-0.5 text-xs font-semibold uppercase tracking-wide {{.StateClass}}">{{.Job.State}}</span>
+            </div>
+        </div>
+        <div class="flex items-center text-sm">
+            <a href="{{basePath}}/job/detail?prefix={{urlquery .Job.Prefix}}&queue={{urlquery .Job.Queue}}&id={{urlquery .Job.ID}}&format=json" class="font-medium text-gray-600 hover:text-gray-900" target="_blank">JSON</a>
+            {{template "job-buttons" .Job}}
+        </div>
+    </div>
+
+    <div class="grid grid-cols-2 gap-4 md:grid-cols-4">
+        <div class="rounded-lg border border-gray-200 p-4">
+            <div class="text-xs uppercase text-gray-400">Attempts</div>
+            <div class="text-2xl font-semibold text-gray-900">{{.Job.AttemptsMade}} <span class="text-base font-normal text-gray-400">/ {{.MaxAttempts}}</span></div>
+        </div>
+        <div class="rounded-lg border border-gray-200 p-4">
+            <div class="text-xs uppercase text-gray-400">Waited</div>
+            <div class="text-2xl font-semibold text-gray-900">{{if .Waited}}{{.Waited}}{{else}}—{{end}}</div>
+        </div>
+        <div class="rounded-lg border border-gray-200 p-4">
+            <div class="text-xs uppercase text-gray-400">Processing</div>
+            <div class="text-2xl font-semibold text-gray-900">{{if .Processing}}{{.Processing}}{{else}}—{{end}}</div>
+        </div>
+        <div class="rounded-lg border border-gray-200 p-4">
+            <div class="text-xs uppercase text-gray-400">Logs</div>
+            <div class="text-2xl font-semibold text-gray-900">{{.Job.LogCount}}</div>
+        </div>
+    </div>
+
+    <dl class="grid grid-cols-1 gap-x-6 gap-y-2 text-sm md:grid-cols-2">
+        <div class="flex gap-2"><dt class="w-28 text-gray-400">Created</dt><dd class="text-gray-900">{{if not .CreatedAt.IsZero}}{{.CreatedAt.Format "2006-01-02 15:04:05.000"}}{{else}}—{{end}}</dd></div>
+        <div class="flex gap-2"><dt class="w-28 text-gray-400">Processed</dt><dd class="text-gray-900">{{if not .ProcessedAt.IsZero}}{{.ProcessedAt.Format "2006-01-02 15:04:05.000"}}{{else}}—{{end}}</dd></div>
+        <div class="flex gap-2"><dt class="w-28 text-gray-400">Finished</dt><dd class="text-gray-900">{{if not .FinishedAt.IsZero}}{{.FinishedAt.Format "2006-01-02 15:04:05.000"}}{{else}}—{{end}}</dd></div>
+        {{if not .DelayedUntil.IsZero}}
+        <div class="flex gap-2"><dt class="w-28 text-gray-400">Delayed until</dt><dd class="text-gray-900">{{.DelayedUntil.Format "2006-01-02 15:04:05.000"}}</dd></div>
+        {{end}}
+    </dl>
+
+    <div class="rounded-lg border border-gray-200 p-4">
+        <div class="text-xs uppercase text-gray-400">Progress</div>
+        {{if .HasPercent}}
+        <div class="mt-2 flex items-center gap-3">
+            <div class="h-2 flex-1 rounded-full bg-gray-100">
+                <div class="h-2 rounded-full bg-indigo-500" data-percent="{{.ProgressPercent}}"></div>
+            </div>
+            <span class="text-sm font-medium text-gray-700">{{.ProgressPercent}}%</span>
+        </div>
+        {{else if .ProgressJSON}}
+        <pre class="mt-2 overflow-auto rounded-md bg-gray-50 p-3 text-xs text-gray-800">{{.ProgressJSON}}</pre>
+        {{else}}
+        <div class="mt-1 text-sm text-gray-500">No progress reported.</div>
+        {{end}}
+    </div>
+
+    {{if or .Job.FailedReason .StackTraces}}
+    <div class="rounded-lg border border-red-200 p-4">
+        <div class="text-xs uppercase text-red-400">Failure</div>
+        {{if .Job.FailedReason}}<div class="mt-1 text-sm font-medium text-red-700">{{.Job.FailedReason}}</div>{{end}}
+        {{range .StackTraces}}
+        <details class="mt-3" open>
+            <summary class="cursor-pointer text-sm text-gray-600">Attempt {{.Attempt}}</summary>
+            <pre class="mt-2 overflow-auto rounded-md bg-gray-900 p-3 text-xs leading-5 text-red-200">{{.Trace}}</pre>
+        </details>
+        {{end}}
+    </div>
+    {{end}}
+
+    <div hx-get="{{basePath}}/job/flow?prefix={{urlquery .Job.Prefix}}&queue={{urlquery .Job.Queue}}&id={{urlquery .Job.ID}}" hx-trigger="load" hx-swap="outerHTML"></div>
+
+    <details class="rounded-lg border border-gray-200 p-4" open>
+        <summary class="cursor-pointer text-xs uppercase text-gray-400">Data</summary>
+        <pre class="mt-2 max-h-[32rem] overflow-auto rounded-md bg-gray-50 p-3 text-xs text-gray-800">{{.DataJSON}}</pre>
+    </details>
+    <details class="rounded-lg border border-gray-200 p-4">
+        <summary class="cursor-pointer text-xs uppercase text-gray-400">Options</summary>
+        <pre class="mt-2 max-h-[32rem] overflow-auto rounded-md bg-gray-50 p-3 text-xs text-gray-800">{{.OptsJSON}}</pre>
+    </details>
+    {{if .ReturnValueJSON}}
+    <details class="rounded-lg border border-gray-200 p-4" open>
+        <summary class="cursor-pointer text-xs uppercase text-gray-400">Return value</summary>
+        <pre class="mt-2 max-h-[32rem] overflow-auto rounded-md bg-gray-50 p-3 text-xs text-gray-800">{{.ReturnValueJSON}}</pre>
+    </details>
+    {{end}}
+
+    <div hx-get="{{basePath}}/job/logs?prefix={{urlquery .Job.Prefix}}&queue={{urlquery .Job.Queue}}&id={{urlquery .Job.ID}}&tail=true" hx-trigger="load" hx-swap="outerHTML">
+        <div class="rounded-lg border border-gray-200 p-4 text-sm text-gray-500">Loading logs...</div>
+    </div>
+</div>
+{{end}}
+`
+
+// JobDetailHandler shows a single job. Browsers get an HTML page; JSON is
+// returned with format=json or an Accept: application/json header.
+func JobDetailHandler(exp *explorer.Explorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		queueName := r.URL.Query().Get("queue")
+		jobID := r.URL.Query().Get("id")
+		if queueName == "" || jobID == "" {
+			http.Error(w, "queue and id parameters required", http.StatusBadRequest)
+			return
+		}
+		if !requireQueueRole(w, r, queueName, auth.RoleViewer) {
+			return
+		}
+		prefix, ok := requestPrefix(w, r, exp)
+		if !ok {
+			return
+		}
+
+		job, err := exp.GetJob(r.Context(), prefix, queueName, jobID)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, explorer.ErrJobNotFound) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		if wantsJSON(r) {
+			writeJSON(w, http.StatusOK, job)
+			return
+		}
+
+		subtitle := fmt.Sprintf("Queue: %s / Job: %s", queueName, jobID)
+		if err := renderShell(w, r, "Bull-der-dash - Job "+jobID, subtitle, jobDetailTmpl, newJobDetailViewData(job)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+func newJobDetailViewData(job *explorer.Job) jobDetailViewData {
+	data := jobDetailViewData{
+		Job:             job,
+		StateClass:      jobStateClass(job.State),
+		MaxAttempts:     1,
+		CreatedAt:       millisToTime(job.Timestamp),
+		ProcessedAt:     millisToTime(job.ProcessedOn),
+		FinishedAt:      millisToTime(job.FinishedOn),
+		DataJSON:        prettyJSON(job.Data),
+		OptsJSON:        prettyJSON(job.Opts),
+		ReturnValueJSON: prettyJSON(job.ReturnValue),
+	}
+
+	if attempts, ok := job.Opts["attempts"].(float64); ok && attempts > 1 {
+		data.MaxAttempts = int(attempts)
+	}
+	if job.Delay > 0 && job.Timestamp > 0 {
+		data.DelayedUntil = millisToTime(job.Timestamp + job.Delay)
+	}
+	if job.ProcessedOn > 0 && job.Timestamp > 0 {
+		data.Waited = formatJobDuration(time.Duration(job.ProcessedOn-job.Timestamp-job.Delay) * time.Millisecond)
+	}
+	if job.FinishedOn > 0 && job.ProcessedOn > 0 {
+		data.Processing = formatJobDuration(time.Duration(job.FinishedOn-job.ProcessedOn) * time.Millisecond)
+	}
+
+	switch progress := job.Progress.(type) {
+	case nil:
+	case float64:
+		data.HasPercent = true
+		data.ProgressPercent = min(max(progress, 0), 100)
+	default:
+		data.ProgressJSON = prettyJSON(progress)
+	}
+
+	// BullMQ keeps the most recent stackTraceLimit traces, oldest first, so
+	// the last trace belongs to the last attempt.
+	firstAttempt := job.AttemptsMade - len(job.StackTrace) + 1
+	if firstAttempt < 1 {
+		firstAttempt = 1
+	}
+	for i, trace := range job.StackTrace {
+		if trace == "" {
+			continue
+		}
+		data.StackTraces = append(data.StackTraces, jobStackTrace{Attempt: firstAttempt + i, Trace: trace})
+	}
+
+	return data
+}
+
+func jobStateClass(state string) string {
+	switch state {
+	case "waiting", "paused", "prioritized", "waiting-children":
+		return "bg-yellow-100 text-yellow-800"
+	case "active":
+		return "bg-blue-100 text-blue-800"
+	case "completed":
+		return "bg-green-100 text-green-800"
+	case "failed":
+		return "bg-red-100 text-red-800"
+	case "delayed":
+		return "bg-purple-100 text-purple-800"
+	default:
+		return "bg-gray-100 text-gray-700"
+	}
+}
+
+func millisToTime(ms int64) time.Time {
+	if ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+func formatJobDuration(d time.Duration) string {
+	switch {
+	case d < 0:
+		return ""
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return d.Round(10 * time.Millisecond).String()
+	default:
+		return d.Round(time.Second).String()
+	}
+}
+
+func prettyJSON(v any) string {
+	if v == nil {
+		return ""
+	}
+	encoded, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+func TestNewJobDetailViewDataDurations(t *testing.T) {
+	job := &explorer.Job{
+		ID:          "1",
+		Queue:       "orders",
+		State:       "completed",
+		Opts:        map[string]interface{}{"attempts": float64(5)},
+		Timestamp:   1_700_000_000_000,
+		Delay:       2_000,
+		ProcessedOn: 1_700_000_003_500,
+		FinishedOn:  1_700_000_065_000,
+	}
+
+	data := newJobDetailViewData(job)
+	if data.MaxAttempts != 5 {
+		t.Fatalf("expected max attempts 5, got %d", data.MaxAttempts)
+	}
+	if data.Waited != "1.5s" {
+		t.Fatalf("expected wait of 1.5s after the delay, got %q", data.Waited)
+	}
+	if data.Processing != "1m2s" {
+		t.Fatalf("expected processing time 1m2s, got %q", data.Processing)
+	}
+	if !data.DelayedUntil.Equal(time.UnixMilli(1_700_000_002_000)) {
+		t.Fatalf("unexpected delayed-until time: %v", data.DelayedUntil)
+	}
+	if !data.FinishedAt.Equal(time.UnixMilli(job.FinishedOn)) {
+		t.Fatalf("unexpected finished time: %v", data.FinishedAt)
+	}
+}
+
+func TestNewJobDetailViewDataProgress(t *testing.T) {
+	data := newJobDetailViewData(&explorer.Job{Progress: float64(140)})
+	if !data.HasPercent || data.ProgressPercent != 100 {
+		t.Fatalf("expected numeric progress clamped to 100, got %v (%t)", data.ProgressPercent, data.HasPercent)
+	}
+
+	data = newJobDetailViewData(&explorer.Job{Progress: map[string]interface{}{"step": "upload"}})
+	if data.HasPercent || data.ProgressJSON == "" {
+		t.Fatalf("expected object progress rendered as JSON, got %+v", data)
+	}
+}
+
+func TestNewJobDetailViewDataStackTraceAttempts(t *testing.T) {
+	job := &explorer.Job{
+		State:        "failed",
+		AttemptsMade: 5,
+		StackTrace:   []string{"Error: third", "Error: fourth", "Error: fifth"},
+	}
+
+	data := newJobDetailViewData(job)
+	if len(data.StackTraces) != 3 {
+		t.Fatalf("expected 3 stack traces, got %d", len(data.StackTraces))
+	}
+	if data.StackTraces[0].Attempt != 3 || data.StackTraces[2].Attempt != 5 {
+		t.Fatalf("unexpected attempt numbering: %+v", data.StackTraces)
+	}
+}
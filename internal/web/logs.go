@@ -0,0 +1,188 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+const (
+	jobLogsDefaultLimit = 100
+	jobLogsMaxLimit     = 1000
+)
+
+type jobLogLine struct {
+	Number int64
+	Text   string
+}
+
+type jobLogsViewData struct {
+	Prefix   string
+	Queue    string
+	ID       string
+	State    string
+	Logs     explorer.JobLogs
+	Lines    []jobLogLine
+	Limit    int64
+	Live     bool
+	First    int64
+	Last     int64
+	HasOlder bool
+	HasNewer bool
+	Older    int64
+	Newer    int64
+}
+
+const jobLogsPanelTmpl = `
+<div id="job-logs" class="rounded-lg border border-gray-200 p-4"
+    {{if .Live}}hx-get="{{basePath}}/job/logs?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}&limit={{.Limit}}&tail=true&live=true" hx-trigger="every 2s" hx-swap="outerHTML"{{end}}>
+    <div class="flex flex-wrap items-center justify-between gap-3">
+        <div class="flex items-center gap-2">
+            <div class="text-xs uppercase text-gray-400">Logs</div>
+            {{if .Live}}<span class="rounded-full bg-blue-100 px-2 py-0.5 text-xs font-semibold text-blue-700">Live</span>{{end}}
+            <span class="text-sm text-gray-500">{{if .Lines}}lines {{.First}}–{{.Last}} of {{.Logs.Count}}{{else}}{{.Logs.Count}} lines{{end}}</span>
+        </div>
+        <div class="flex items-center gap-3 text-sm">
+            {{if .HasOlder}}
+            <button hx-get="{{basePath}}/job/logs?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}&limit={{.Limit}}&offset=0" hx-target="#job-logs" hx-swap="outerHTML" class="text-indigo-600 hover:text-indigo-900">First</button>
+            <button hx-get="{{basePath}}/job/logs?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}&limit={{.Limit}}&offset={{.Older}}" hx-target="#job-logs" hx-swap="outerHTML" class="text-indigo-600 hover:text-indigo-900">← Older</button>
+            {{end}}
+            {{if .HasNewer}}
+            <button hx-get="{{basePath}}/job/logs?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}&limit={{.Limit}}&offset={{.Newer}}" hx-target="#job-logs" hx-swap="outerHTML" class="text-indigo-600 hover:text-indigo-900">Newer →</button>
+            <button hx-get="{{basePath}}/job/logs?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}&limit={{.Limit}}&tail=true" hx-target="#job-logs" hx-swap="outerHTML" class="text-indigo-600 hover:text-indigo-900">Latest</button>
+            {{end}}
+            {{if eq .State "active"}}
+            {{if .Live}}
+            <button hx-get="{{basePath}}/job/logs?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}&limit={{.Limit}}&tail=true" hx-target="#job-logs" hx-swap="outerHTML" class="rounded-md border border-gray-300 px-3 py-1 text-xs font-semibold text-gray-700 hover:bg-gray-50">Stop live tail</button>
+            {{else}}
+            <button hx-get="{{basePath}}/job/logs?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&id={{urlquery .ID}}&limit={{.Limit}}&tail=true&live=true" hx-target="#job-logs" hx-swap="outerHTML" class="rounded-md border border-blue-300 px-3 py-1 text-xs font-semibold text-blue-700 hover:bg-blue-50">Live tail</button>
+            {{end}}
+            {{end}}
+        </div>
+    </div>
+    {{if .Lines}}
+    <pre class="mt-3 max-h-[32rem] overflow-auto rounded-md bg-gray-900 p-3 text-xs leading-5 text-gray-100">{{range .Lines}}<span class="select-none text-gray-500">{{printf "%5d" .Number}}</span>  {{.Text}}
+{{end}}</pre>
+    {{else}}
+    <div class="mt-3 text-sm text-gray-500">This job has no log lines{{if .Logs.Count}} in this range{{end}}.</div>
+    {{end}}
+</div>
+`
+
+const jobLogsPageTmpl = `
+<div class="mb-4 text-sm">
+    <a href="{{basePath}}/job/detail?prefix={{urlquery .Data.Prefix}}&queue={{urlquery .Data.Queue}}&id={{urlquery .Data.ID}}" class="text-indigo-600 hover:text-indigo-900">← Job {{.Data.ID}}</a>
+    <span class="ml-2 text-gray-500">{{.Data.State}}</span>
+</div>
+{{with .Data}}` + jobLogsPanelTmpl + `{{end}}
+`
+
+var jobLogsPanelTemplate = template.Must(newTemplate("job-logs").Parse(jobLogsPanelTmpl))
+
+// JobLogsHandler pages through a job's job.log() lines. Without an offset the
+// latest lines are shown; live=true keeps polling the tail while the job is
+// active. Returns an HTML fragment for HTMX, JSON with format=json or an
+// Accept: application/json header, and a full page otherwise.
+func JobLogsHandler(exp *explorer.Explorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		queueName := query.Get("queue")
+		jobID := query.Get("id")
+		if queueName == "" || jobID == "" {
+			http.Error(w, "queue and id parameters required", http.StatusBadRequest)
+			return
+		}
+		if !requireQueueRole(w, r, queueName, auth.RoleViewer) {
+			return
+		}
+		prefix, ok := requestPrefix(w, r, exp)
+		if !ok {
+			return
+		}
+
+		limit := int64(min(parsePositiveInt(query.Get("limit"), jobLogsDefaultLimit), jobLogsMaxLimit))
+		offset, err := strconv.ParseInt(query.Get("offset"), 10, 64)
+		tail, _ := strconv.ParseBool(query.Get("tail"))
+		if err != nil || offset < 0 {
+			offset = 0
+			tail = true
+		}
+
+		job, err := exp.GetJob(r.Context(), prefix, queueName, jobID)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, explorer.ErrJobNotFound) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		logs, err := exp.GetJobLogs(r.Context(), prefix, queueName, jobID, offset, limit, tail)
+		if err != nil {
+			log.Printf("❌ job logs error (queue=%s id=%s): %v", queueName, jobID, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if wantsJSON(r) {
+			writeJSON(w, http.StatusOK, logs)
+			return
+		}
+
+		live, _ := strconv.ParseBool(query.Get("live"))
+		data := newJobLogsViewData(prefix, queueName, jobID, job.State, logs, limit, tail, live)
+
+		if r.Header.Get("HX-Request") != "" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			if err := executeTemplate(w, r, jobLogsPanelTemplate, data); err != nil {
+				log.Printf("❌ Template execution error (job logs): %v", err)
+			}
+			return
+		}
+
+		if err := renderShell(w, r, "Bull-der-dash - Job "+jobID+" logs", fmt.Sprintf("Queue: %s / Job: %s", queueName, jobID), jobLogsPageTmpl, data); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+func newJobLogsViewData(prefix, queueName, jobID, state string, logs explorer.JobLogs, limit int64, tail, live bool) jobLogsViewData {
+	lines := make([]jobLogLine, 0, len(logs.Logs))
+	for i, text := range logs.Logs {
+		lines = append(lines, jobLogLine{Number: logs.Offset + int64(i) + 1, Text: text})
+	}
+
+	last := logs.Offset + int64(len(lines))
+	return jobLogsViewData{
+		Prefix:   prefix,
+		Queue:    queueName,
+		ID:       jobID,
+		State:    state,
+		Logs:     logs,
+		Lines:    lines,
+		Limit:    limit,
+		Live:     live && tail && state == "active",
+		First:    logs.Offset + 1,
+		Last:     last,
+		HasOlder: logs.Offset > 0,
+		HasNewer: last < logs.Count,
+		Older:    max(logs.Offset-limit, 0),
+		Newer:    last,
+	}
+}
+
+// wantsJSON reports whether a GET caller asked for JSON instead of HTML.
+func wantsJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "json" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
@@ -0,0 +1,60 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+func TestNewJobLogsViewDataPaging(t *testing.T) {
+	logs := explorer.JobLogs{Logs: []string{"a", "b", "c"}, Count: 10, Offset: 4}
+	data := newJobLogsViewData("bull", "orders", "1", "completed", logs, 3, false, false)
+
+	if data.First != 5 || data.Last != 7 {
+		t.Fatalf("unexpected range: %d-%d", data.First, data.Last)
+	}
+	if data.Lines[0].Number != 5 || data.Lines[2].Text != "c" {
+		t.Fatalf("unexpected lines: %+v", data.Lines)
+	}
+	if !data.HasOlder || data.Older != 1 {
+		t.Fatalf("unexpected older page: has=%t offset=%d", data.HasOlder, data.Older)
+	}
+	if !data.HasNewer || data.Newer != 7 {
+		t.Fatalf("unexpected newer page: has=%t offset=%d", data.HasNewer, data.Newer)
+	}
+}
+
+func TestNewJobLogsViewDataLiveOnlyForActiveTail(t *testing.T) {
+	logs := explorer.JobLogs{Logs: []string{"a"}, Count: 1}
+
+	if !newJobLogsViewData("bull", "orders", "1", "active", logs, 100, true, true).Live {
+		t.Fatal("expected live tail for an active job")
+	}
+	if newJobLogsViewData("bull", "orders", "1", "completed", logs, 100, true, true).Live {
+		t.Fatal("expected live tail to stop once the job is no longer active")
+	}
+	if newJobLogsViewData("bull", "orders", "1", "active", logs, 100, false, true).Live {
+		t.Fatal("expected live tail to require the tail window")
+	}
+}
+
+func TestWantsJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/job/logs?queue=orders&id=1&format=json", nil)
+	if !wantsJSON(req) {
+		t.Fatal("expected format=json to select JSON")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/job/logs?queue=orders&id=1", nil)
+	req.Header.Set("Accept", "application/json")
+	if !wantsJSON(req) {
+		t.Fatal("expected Accept: application/json to select JSON")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/job/logs?queue=orders&id=1", nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	if wantsJSON(req) {
+		t.Fatal("expected browsers to get HTML")
+	}
+}
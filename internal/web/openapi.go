@@ -0,0 +1,234 @@
+package web
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// The OpenAPI document is generated from apiRoutes: parameters from each
+// route, and schemas by reflecting over the Go types the handlers encode.
+
+type openAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       openAPIInfo                            `json:"info"`
+	Servers    []openAPIServer                        `json:"servers"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components openAPIComponents                      `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Required    bool           `json:"required,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `json:"schemas"`
+}
+
+// openAPISchema is the subset of JSON Schema the API needs. The zero value
+// is "any value".
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+func newOpenAPIDocument(routes []apiRoute, serverURL string) openAPIDocument {
+	schemas := make(map[string]*openAPISchema)
+	errorSchema := schemaFor(reflect.TypeOf(apiError{}), schemas)
+
+	doc := openAPIDocument{
+		OpenAPI:    "3.0.3",
+		Info:       openAPIInfo{Title: "Bull-der-dash API", Version: "v1"},
+		Servers:    []openAPIServer{{URL: serverURL}},
+		Paths:      make(map[string]map[string]openAPIOperation),
+		Components: openAPIComponents{Schemas: schemas},
+	}
+	for _, route := range routes {
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		operation := openAPIOperation{
+			OperationID: route.OperationID,
+			Summary:     route.Summary,
+			Responses: map[string]openAPIResponse{
+				strconv.Itoa(status): jsonResponse(http.StatusText(status), schemaFor(reflect.TypeOf(route.Response), schemas)),
+				"default":            jsonResponse("Error", errorSchema),
+			},
+		}
+		for _, param := range route.Params {
+			operation.Parameters = append(operation.Parameters, openAPIParameter{
+				Name:        param.Name,
+				In:          param.In,
+				Required:    param.Required,
+				Description: param.Description,
+				Schema:      &openAPISchema{Type: param.Type, Enum: param.Enum},
+			})
+		}
+
+		if doc.Paths[route.Path] == nil {
+			doc.Paths[route.Path] = make(map[string]openAPIOperation)
+		}
+		doc.Paths[route.Path][strings.ToLower(route.Method)] = operation
+	}
+	return doc
+}
+
+func jsonResponse(description string, schema *openAPISchema) openAPIResponse {
+	return openAPIResponse{
+		Description: description,
+		Content:     map[string]openAPIMediaType{"application/json": {Schema: schema}},
+	}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// schemaFor describes how encoding/json encodes t. Named structs are added to
+// schemas and referenced, so shared types appear once.
+func schemaFor(t reflect.Type, schemas map[string]*openAPISchema) *openAPISchema {
+	switch t {
+	case timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case rawMessageType:
+		return &openAPISchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), schemas)
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openAPISchema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &openAPISchema{Type: "array", Items: schemaFor(t.Elem(), schemas)}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: schemaFor(t.Elem(), schemas)}
+	case reflect.Struct:
+		name := schemaName(t)
+		if name == "" {
+			return structSchema(t, schemas)
+		}
+		if _, ok := schemas[name]; !ok {
+			// Reserve the name first in case the type refers to itself.
+			schemas[name] = nil
+			schemas[name] = structSchema(t, schemas)
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + name}
+	default:
+		return &openAPISchema{}
+	}
+}
+
+func structSchema(t reflect.Type, schemas map[string]*openAPISchema) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+	addStructProperties(t, schema, schemas)
+	return schema
+}
+
+// addStructProperties adds t's fields to schema, promoting the fields of
+// untagged embedded structs the way encoding/json does. Outer fields win.
+func addStructProperties(t reflect.Type, schema *openAPISchema, schemas map[string]*openAPISchema) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = schemaFor(field.Type, schemas)
+		omittable := strings.Contains(opts, "omitempty") || strings.Contains(opts, "omitzero")
+		if !omittable && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	for _, inner := range embedded {
+		promoted := &openAPISchema{Properties: make(map[string]*openAPISchema)}
+		addStructProperties(inner, promoted, schemas)
+		for _, name := range slices.Sorted(maps.Keys(promoted.Properties)) {
+			if _, ok := schema.Properties[name]; ok {
+				continue
+			}
+			schema.Properties[name] = promoted.Properties[name]
+			if slices.Contains(promoted.Required, name) {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+}
+
+// schemaName names a struct's component schema: apiQueue is "Queue",
+// explorer.Job is "Job".
+func schemaName(t reflect.Type) string {
+	name := strings.TrimPrefix(t.Name(), "api")
+	if name == "" {
+		return ""
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,193 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/audit"
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+const schedulersPageSize = 50
+
+type jobSchedulerRow struct {
+	explorer.JobScheduler
+	Prefix   string
+	Queue    string
+	Schedule string
+	DueIn    string
+}
+
+type jobSchedulersViewData struct {
+	Prefix      string
+	Queue       string
+	Schedulers  []jobSchedulerRow
+	Total       int64
+	Page        int
+	HasPrevPage bool
+	HasNextPage bool
+}
+
+const jobSchedulersTmpl = `
+<div id="job-schedulers" class="space-y-3">
+    <div class="text-sm text-gray-500">{{.Total}} repeatable jobs / job schedulers in <span class="font-semibold text-gray-700">{{.Queue}}</span>, ordered by next run.</div>
+    {{if .Schedulers}}
+    <div class="overflow-x-auto rounded-lg border border-gray-200">
+        <table class="min-w-full divide-y divide-gray-200">
+            <thead class="bg-gray-50">
+                <tr>
+                    <th class="px-4 py-3 text-left text-xs font-medium text-gray-500 uppercase">Key</th>
+                    <th class="px-4 py-3 text-left text-xs font-medium text-gray-500 uppercase">Name</th>
+                    <th class="px-4 py-3 text-left text-xs font-medium text-gray-500 uppercase">Schedule</th>
+                    <th class="px-4 py-3 text-left text-xs font-medium text-gray-500 uppercase">Next run</th>
+                    <th class="px-4 py-3 text-left text-xs font-medium text-gray-500 uppercase">Iterations</th>
+                    <th class="px-4 py-3 text-left text-xs font-medium text-gray-500 uppercase">Template</th>
+                    <th class="px-4 py-3 text-left text-xs font-medium text-gray-500 uppercase">Actions</th>
+                </tr>
+            </thead>
+            <tbody class="bg-white divide-y divide-gray-200">
+                {{range .Schedulers}}
+                <tr class="hover:bg-gray-50 align-top">
+                    <td class="px-4 py-3 text-sm font-mono text-gray-600 break-all">
+                        {{.Key}}
+                        {{if .Legacy}}<span class="ml-1 rounded bg-gray-100 px-1 text-xs text-gray-500">legacy</span>{{end}}
+                    </td>
+                    <td class="px-4 py-3 text-sm text-gray-900">{{.Name}}</td>
+                    <td class="px-4 py-3 text-sm text-gray-700">
+                        <span class="font-mono">{{.Schedule}}</span>
+                        {{if .TZ}}<div class="text-xs text-gray-500">{{.TZ}}</div>{{end}}
+                    </td>
+                    <td class="px-4 py-3 text-sm text-gray-700">
+                        {{.NextRun.Format "2006-01-02 15:04:05"}}
+                        <div class="text-xs text-gray-500">{{.DueIn}}</div>
+                    </td>
+                    <td class="px-4 py-3 text-sm text-gray-700">{{.Iterations}}{{if .Limit}} / {{.Limit}}{{end}}</td>
+                    <td class="px-4 py-3 text-sm text-gray-700">
+                        {{if or .Data .Opts}}
+                        <details>
+                            <summary class="cursor-pointer text-indigo-600">Show</summary>
+                            {{if .Data}}<div class="mt-2 text-xs uppercase text-gray-400">data</div><pre class="mt-1 max-w-md overflow-auto rounded bg-gray-50 p-2 text-xs">{{.Data}}</pre>{{end}}
+                            {{if .Opts}}<div class="mt-2 text-xs uppercase text-gray-400">opts</div><pre class="mt-1 max-w-md overflow-auto rounded bg-gray-50 p-2 text-xs">{{.Opts}}</pre>{{end}}
+                        </details>
+                        {{else}}—{{end}}
+                    </td>
+                    <td class="px-4 py-3 text-sm">
+                        {{if can "admin" .Queue}}<button hx-post="{{basePath}}/queue/schedulers/remove?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&key={{urlquery .Key}}" hx-confirm="Remove scheduler {{.Key}} and its next delayed job?" hx-swap="outerHTML" class="font-medium text-red-600 hover:text-red-800">Remove</button>{{end}}
+                    </td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+    </div>
+    <div class="flex items-center justify-between text-sm">
+        {{if .HasPrevPage}}
+        <button hx-get="{{basePath}}/queue/schedulers?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&page={{sub .Page 1}}" hx-target="#job-schedulers" hx-swap="outerHTML" class="text-indigo-600 hover:text-indigo-900">← Previous</button>
+        {{else}}<span></span>{{end}}
+        <span class="text-gray-500">Page {{.Page}}</span>
+        {{if .HasNextPage}}
+        <button hx-get="{{basePath}}/queue/schedulers?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}&page={{add .Page 1}}" hx-target="#job-schedulers" hx-swap="outerHTML" class="text-indigo-600 hover:text-indigo-900">Next →</button>
+        {{else}}<span></span>{{end}}
+    </div>
+    {{else}}
+    <div class="rounded-lg border border-gray-200 p-4 text-sm text-gray-500">No repeatable jobs or job schedulers.</div>
+    {{end}}
+</div>
+`
+
+var jobSchedulersTemplate = template.Must(newTemplate("job-schedulers").Parse(jobSchedulersTmpl))
+
+// JobSchedulersHandler lists a queue's repeatable jobs and job schedulers as
+// an HTML fragment for the queue page, or JSON with format=json.
+func JobSchedulersHandler(exp *explorer.Explorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		queueName := r.URL.Query().Get("queue")
+		if queueName == "" {
+			http.Error(w, "queue parameter required", http.StatusBadRequest)
+			return
+		}
+		if !requireQueueRole(w, r, queueName, auth.RoleViewer) {
+			return
+		}
+		prefix, ok := requestPrefix(w, r, exp)
+		if !ok {
+			return
+		}
+		page := parsePositiveInt(r.URL.Query().Get("page"), 1)
+		offset := (page - 1) * schedulersPageSize
+
+		schedulers, total, err := exp.GetJobSchedulers(r.Context(), prefix, queueName, offset, schedulersPageSize)
+		if err != nil {
+			log.Printf("❌ job schedulers error (queue=%s): %v", queueName, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if wantsJSON(r) {
+			writeJSON(w, http.StatusOK, struct {
+				Prefix     string                  `json:"prefix"`
+				Queue      string                  `json:"queue"`
+				Total      int64                   `json:"total"`
+				Page       int                     `json:"page"`
+				Schedulers []explorer.JobScheduler `json:"schedulers"`
+			}{prefix, queueName, total, page, schedulers})
+			return
+		}
+
+		data := newJobSchedulersViewData(prefix, queueName, schedulers, total, page, time.Now())
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := executeTemplate(w, r, jobSchedulersTemplate, data); err != nil {
+			log.Printf("❌ Template execution error (job schedulers): %v", err)
+		}
+	}
+}
+
+// RemoveJobSchedulerHandler removes a job scheduler and its next delayed job.
+func RemoveJobSchedulerHandler(exp *explorer.Explorer, auditLog *audit.Logger) http.HandlerFunc {
+	return queueActionHandler("removeJobScheduler", auth.RoleAdmin, exp, auditLog, removeJobSchedulerAction(exp))
+}
+
+func removeJobSchedulerAction(exp *explorer.Explorer) queueAction {
+	return func(r *http.Request, queue explorer.QueueRef) (actionResult, error) {
+		key := strings.TrimSpace(r.FormValue("key"))
+		if key == "" {
+			return actionResult{}, errBadActionRequest("key parameter required")
+		}
+		return actionResult{OK: true, Message: "Removed"}, exp.RemoveJobScheduler(r.Context(), queue.Prefix, queue.Name, key)
+	}
+}
+
+func newJobSchedulersViewData(prefix, queueName string, schedulers []explorer.JobScheduler, total int64, page int, now time.Time) jobSchedulersViewData {
+	rows := make([]jobSchedulerRow, 0, len(schedulers))
+	for _, scheduler := range schedulers {
+		row := jobSchedulerRow{JobScheduler: scheduler, Prefix: prefix, Queue: queueName}
+		switch {
+		case scheduler.Pattern != "":
+			row.Schedule = scheduler.Pattern
+		case scheduler.Every > 0:
+			row.Schedule = "every " + (time.Duration(scheduler.Every) * time.Millisecond).String()
+		default:
+			row.Schedule = "—"
+		}
+		if until := scheduler.NextRun.Sub(now); until >= 0 {
+			row.DueIn = "in " + until.Round(time.Second).String()
+		} else {
+			row.DueIn = fmt.Sprintf("overdue by %s", (-until).Round(time.Second))
+		}
+		rows = append(rows, row)
+	}
+
+	return jobSchedulersViewData{
+		Prefix:      prefix,
+		Queue:       queueName,
+		Schedulers:  rows,
+		Total:       total,
+		Page:        page,
+		HasPrevPage: page > 1,
+		HasNextPage: int64(page*schedulersPageSize) < total,
+	}
+}
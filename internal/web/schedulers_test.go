@@ -0,0 +1,37 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+func TestNewJobSchedulersViewData(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	schedulers := []explorer.JobScheduler{
+		{Key: "nightly", Pattern: "0 3 * * *", NextRun: now.Add(90 * time.Minute)},
+		{Key: "ping", Every: 30000, NextRun: now.Add(-5 * time.Second)},
+	}
+
+	data := newJobSchedulersViewData("bull", "orders", schedulers, 120, 2, now)
+
+	if got := data.Schedulers[0].Schedule; got != "0 3 * * *" {
+		t.Fatalf("unexpected cron schedule: %q", got)
+	}
+	if got := data.Schedulers[0].DueIn; got != "in 1h30m0s" {
+		t.Fatalf("unexpected due-in text: %q", got)
+	}
+	if got := data.Schedulers[1].Schedule; got != "every 30s" {
+		t.Fatalf("unexpected every schedule: %q", got)
+	}
+	if got := data.Schedulers[1].DueIn; got != "overdue by 5s" {
+		t.Fatalf("unexpected overdue text: %q", got)
+	}
+	if data.Schedulers[1].Prefix != "bull" || data.Schedulers[1].Queue != "orders" {
+		t.Fatalf("expected rows to carry the queue, got %q %q", data.Schedulers[1].Prefix, data.Schedulers[1].Queue)
+	}
+	if !data.HasPrevPage || !data.HasNextPage {
+		t.Fatalf("expected both pagers on page 2 of 3, got prev=%t next=%t", data.HasPrevPage, data.HasNextPage)
+	}
+}
@@ -2,6 +2,8 @@ package web
 
 import (
 	"context"
+	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -16,7 +18,7 @@ const (
 )
 
 type searchExplorer interface {
-	GetJobsAcrossStatesPage(ctx context.Context, queueName string, offsetPerState, limitPerState int) ([]explorer.JobSummary, error)
+	GetJobsAcrossStatesPage(ctx context.Context, prefix, queueName string, offsetPerState, limitPerState int) ([]explorer.JobSummary, error)
 }
 
 type searchWindowOption struct {
@@ -64,11 +66,11 @@ func parseSearchWindow(value string, now time.Time) searchWindow {
 	}
 }
 
-func searchJobsAcrossStates(ctx context.Context, exp searchExplorer, queueName, query string, page int, window searchWindow) (searchResults, error) {
+func searchJobsAcrossStates(ctx context.Context, exp searchExplorer, prefix, queueName, query string, page int, window searchWindow) (searchResults, error) {
 	start := (page - 1) * searchResultsPageSize
 	endExclusive := start + searchResultsPageSize
 	needCount := endExclusive + 1
-	queryLower := strings.ToLower(strings.TrimSpace(query))
+	matcher := newSubstringMatcher(query)
 
 	filtered := make([]explorer.JobSummary, 0, needCount)
 	searchedJobs := 0
@@ -76,7 +78,7 @@ func searchJobsAcrossStates(ctx context.Context, exp searchExplorer, queueName,
 
 	for scanPage := 0; scanPage < searchMaxScanPages; scanPage++ {
 		offset := scanPage * searchScanPerState
-		batch, err := exp.GetJobsAcrossStatesPage(ctx, queueName, offset, searchScanPerState)
+		batch, err := exp.GetJobsAcrossStatesPage(ctx, prefix, queueName, offset, searchScanPerState)
 		if err != nil {
 			return searchResults{}, err
 		}
@@ -88,7 +90,7 @@ func searchJobsAcrossStates(ctx context.Context, exp searchExplorer, queueName,
 		}
 
 		for _, job := range batch {
-			if !matchesSearch(job, queryLower, window) {
+			if !matchesSearch(job, matcher, window) {
 				continue
 			}
 			filtered = append(filtered, job)
@@ -126,16 +128,52 @@ func searchJobsAcrossStates(ctx context.Context, exp searchExplorer, queueName,
 	}, nil
 }
 
-func matchesSearch(job explorer.JobSummary, queryLower string, window searchWindow) bool {
+// jobMatcher applies the search matching rules to a job field: a
+// case-insensitive substring match, or a regular expression when requested.
+type jobMatcher struct {
+	substring string
+	re        *regexp.Regexp
+}
+
+func newSubstringMatcher(query string) jobMatcher {
+	return jobMatcher{substring: strings.ToLower(strings.TrimSpace(query))}
+}
+
+func newJobMatcher(query string, useRegex bool) (jobMatcher, error) {
+	query = strings.TrimSpace(query)
+	if !useRegex || query == "" {
+		return newSubstringMatcher(query), nil
+	}
+	re, err := regexp.Compile(query)
+	if err != nil {
+		return jobMatcher{}, fmt.Errorf("invalid regex: %w", err)
+	}
+	return jobMatcher{re: re}, nil
+}
+
+func (m jobMatcher) match(value string) bool {
+	if m.re != nil {
+		return m.re.MatchString(value)
+	}
+	return strings.Contains(strings.ToLower(value), m.substring)
+}
+
+func matchesSearch(job explorer.JobSummary, matcher jobMatcher, window searchWindow) bool {
 	if window.Set && !job.Timestamp.IsZero() && job.Timestamp.Before(window.Since) {
 		return false
 	}
 
-	return strings.Contains(strings.ToLower(job.ID), queryLower) ||
-		strings.Contains(strings.ToLower(job.Name), queryLower) ||
-		strings.Contains(strings.ToLower(job.Data), queryLower) ||
-		strings.Contains(strings.ToLower(job.Opts), queryLower) ||
-		strings.Contains(strings.ToLower(job.FailedReason), queryLower)
+	return matcher.match(job.ID) ||
+		matcher.match(job.Name) ||
+		matcher.match(job.Data) ||
+		matcher.match(job.Opts) ||
+		matcher.match(job.FailedReason)
+}
+
+// matchesRetryFilter is the bulk retry subset of matchesSearch: only the job
+// name and failed reason are considered.
+func matchesRetryFilter(job explorer.JobSummary, matcher jobMatcher) bool {
+	return matcher.match(job.Name) || matcher.match(job.FailedReason)
 }
 
 func searchWindowLabel(window searchWindow) string {
@@ -12,7 +12,7 @@ type stubSearchExplorer struct {
 	pages map[int][]explorer.JobSummary
 }
 
-func (s stubSearchExplorer) GetJobsAcrossStatesPage(ctx context.Context, queueName string, offsetPerState, limitPerState int) ([]explorer.JobSummary, error) {
+func (s stubSearchExplorer) GetJobsAcrossStatesPage(ctx context.Context, prefix, queueName string, offsetPerState, limitPerState int) ([]explorer.JobSummary, error) {
 	return append([]explorer.JobSummary(nil), s.pages[offsetPerState]...), nil
 }
 
@@ -37,7 +37,7 @@ func TestSearchJobsAcrossStatesFiltersByQueryAndTimeWindow(t *testing.T) {
 				{ID: "job-3", Name: "billing", Data: `{"account":"xyz"}`, Timestamp: now.Add(-5 * time.Minute)},
 			},
 		},
-	}, "emails", "abc", 1, parseSearchWindow("1h", now))
+	}, "bull", "emails", "abc", 1, parseSearchWindow("1h", now))
 	if err != nil {
 		t.Fatalf("searchJobsAcrossStates returned error: %v", err)
 	}
@@ -59,7 +59,7 @@ func TestSearchJobsAcrossStatesSortsNewestFirst(t *testing.T) {
 				{ID: "newer", Name: "email", Data: "match", Timestamp: now.Add(-5 * time.Minute)},
 			},
 		},
-	}, "emails", "match", 1, parseSearchWindow("", now))
+	}, "bull", "emails", "match", 1, parseSearchWindow("", now))
 	if err != nil {
 		t.Fatalf("searchJobsAcrossStates returned error: %v", err)
 	}
@@ -68,3 +68,25 @@ func TestSearchJobsAcrossStatesSortsNewestFirst(t *testing.T) {
 		t.Fatalf("expected newest job first, got %q want %q", got, want)
 	}
 }
+
+func TestNewJobMatcher(t *testing.T) {
+	substring, err := newJobMatcher("  Timeout ", false)
+	if err != nil {
+		t.Fatalf("newJobMatcher returned error: %v", err)
+	}
+	if !substring.match("request TIMEOUT after 30s") {
+		t.Fatal("expected case-insensitive substring match")
+	}
+
+	regex, err := newJobMatcher(`^ETIMEDOUT|ECONNRESET$`, true)
+	if err != nil {
+		t.Fatalf("newJobMatcher returned error: %v", err)
+	}
+	if !regex.match("ETIMEDOUT on upstream") || regex.match("etimedout") {
+		t.Fatal("expected case-sensitive regex match")
+	}
+
+	if _, err := newJobMatcher("(", true); err == nil {
+		t.Fatal("expected invalid regex error")
+	}
+}
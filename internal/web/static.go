@@ -0,0 +1,139 @@
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// staticFiles are the UI's scripts and styles. htmx.min.js and tailwind.css
+// are not written by hand: scripts/build-ui-assets.sh downloads and compiles
+// them and records their checksums in scripts/ui-assets.sha384, and they
+// have to be committed from its output. Until they are, MissingAssets
+// reports them and the binary refuses to start.
+//
+//go:embed static
+var staticFiles embed.FS
+
+// StaticPathPrefix is where StaticHandler is mounted.
+const StaticPathPrefix = "/static/"
+
+// requiredAssets are the files every page loads.
+var requiredAssets = []string{"htmx.min.js", "tailwind.css", "bullderdash.js"}
+
+type staticAsset struct {
+	body        []byte
+	contentType string
+	// version is a prefix of the content hash. URLs carry it as ?v= so a
+	// new build busts browser caches.
+	version string
+}
+
+var staticAssets = loadStaticAssets()
+
+// staticStartTime is the Last-Modified of every asset: embedded files have
+// no modification time of their own.
+var staticStartTime = time.Now()
+
+func loadStaticAssets() map[string]staticAsset {
+	assets := make(map[string]staticAsset)
+	entries, err := fs.ReadDir(staticFiles, "static")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		body, err := staticFiles.ReadFile("static/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		sum := sha256.Sum256(body)
+		contentType := mime.TypeByExtension(path.Ext(entry.Name()))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		assets[entry.Name()] = staticAsset{
+			body:        body,
+			contentType: contentType,
+			version:     hex.EncodeToString(sum[:])[:12],
+		}
+	}
+	return assets
+}
+
+// assetURL is the "asset" template func: the versioned URL of an embedded
+// file.
+func assetURL(name string) string {
+	asset, ok := staticAssets[name]
+	if !ok {
+		return ""
+	}
+	return StaticPathPrefix + name + "?v=" + asset.version
+}
+
+// MissingAssets lists the required files this binary was built without;
+// pages cannot render without them.
+func MissingAssets() []string {
+	var missing []string
+	for _, name := range requiredAssets {
+		if _, ok := staticAssets[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// StaticHandler serves the embedded assets. A request carrying the current
+// ?v= version may be cached for a year; anything else is revalidated with
+// its ETag.
+func StaticHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, StaticPathPrefix)
+		asset, ok := staticAssets[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", asset.contentType)
+		w.Header().Set("ETag", `"`+asset.version+`"`)
+		if r.URL.Query().Get("v") == asset.version {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			w.Header().Set("Cache-Control", "public, max-age=300")
+		}
+		http.ServeContent(w, r, name, staticStartTime, bytes.NewReader(asset.body))
+	})
+}
+
+// contentSecurityPolicy allows scripts, styles and connections from this
+// origin only.
+var contentSecurityPolicy = strings.Join([]string{
+	"default-src 'none'",
+	"script-src 'self'",
+	"style-src 'self'",
+	"img-src 'self' data:",
+	"connect-src 'self'",
+	"form-action 'self'",
+	"base-uri 'none'",
+	"frame-ancestors 'none'",
+}, "; ")
+
+// WithSecurityHeaders sets the Content-Security-Policy and related headers
+// on every response.
+func WithSecurityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "same-origin")
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,125 @@
+// Bullderdash page behaviour. It lives in a file rather than inline
+// <script> tags so the Content-Security-Policy can allow scripts from 'self'
+// only. htmx.onLoad runs each function for the first page and for every
+// fragment htmx swaps in.
+(function () {
+    "use strict";
+
+    // Progress bars carry their width in data-percent: inline style
+    // attributes are blocked by the CSP, but CSSOM writes are not.
+    function sizeBars(root) {
+        var bars = root.querySelectorAll("[data-percent]");
+        Array.prototype.forEach.call(bars, function (bar) {
+            var percent = Math.max(0, Math.min(100, Number(bar.dataset.percent) || 0));
+            bar.style.width = percent + "%";
+        });
+    }
+
+    // The live Events panel on the queue page. It only reads data
+    // attributes, so nothing from Redis reaches the page except through
+    // textContent.
+    function startEventsPanel(panel) {
+        if (panel.dataset.bound) {
+            return;
+        }
+        panel.dataset.bound = "true";
+
+        var status = panel.querySelector("[data-role=status]");
+        var toggle = panel.querySelector("[data-role=toggle]");
+        var rows = panel.querySelector("[data-role=rows]");
+        var classes = {active: "text-blue-700", completed: "text-green-700", failed: "text-red-700", stalled: "text-orange-700", progress: "text-indigo-700"};
+        var source = null;
+
+        function cell(text, className) {
+            var td = document.createElement("td");
+            td.className = "px-4 py-1 " + (className || "text-gray-600");
+            td.textContent = text;
+            return td;
+        }
+
+        function show(e) {
+            var ev = JSON.parse(e.data);
+            var tr = document.createElement("tr");
+            tr.appendChild(cell(new Date(ev.time).toLocaleTimeString()));
+            tr.appendChild(cell(ev.event, classes[ev.event]));
+            var id = cell("");
+            if (ev.jobId) {
+                var a = document.createElement("a");
+                a.href = panel.dataset.detail + encodeURIComponent(ev.jobId);
+                a.target = "_blank";
+                a.className = "text-indigo-600 hover:text-indigo-900";
+                a.textContent = ev.jobId;
+                id.appendChild(a);
+            }
+            tr.appendChild(id);
+            var details = ev.fields ? Object.keys(ev.fields).map(function (k) { return k + "=" + ev.fields[k]; }).join(" ") : "";
+            var td = cell(details);
+            td.classList.add("truncate", "max-w-md");
+            td.title = details;
+            tr.appendChild(td);
+            rows.insertBefore(tr, rows.firstChild);
+            while (rows.children.length > 200) {
+                rows.removeChild(rows.lastChild);
+            }
+        }
+
+        function setStatus(text, className) {
+            status.textContent = text;
+            status.className = "rounded-full px-2 py-0.5 text-xs font-semibold " + className;
+        }
+
+        function stop() {
+            if (source) {
+                source.close();
+                source = null;
+            }
+            toggle.textContent = "Start";
+            setStatus("Stopped", "bg-gray-100 text-gray-600");
+        }
+
+        function start() {
+            var types = Array.prototype.map.call(panel.querySelectorAll("[data-role=type]:checked"), function (box) { return box.value; });
+            var job = panel.querySelector("[data-role=job]").value.trim();
+            var url = panel.dataset.src + "&event=" + encodeURIComponent(types.join(","));
+            if (job) {
+                url += "&job=" + encodeURIComponent(job);
+            }
+            source = new EventSource(url);
+            types.forEach(function (type) { source.addEventListener(type, show); });
+            source.onopen = function () { setStatus("Live", "bg-blue-100 text-blue-700"); };
+            source.onerror = function () {
+                // EventSource gives up on a refusal such as the server's 503
+                // when every events stream is taken.
+                if (source && source.readyState === EventSource.CLOSED) {
+                    stop();
+                    setStatus("Unavailable", "bg-red-100 text-red-700");
+                    return;
+                }
+                setStatus("Reconnecting", "bg-amber-100 text-amber-700");
+            };
+            toggle.textContent = "Stop";
+        }
+
+        toggle.addEventListener("click", function () { source ? stop() : start(); });
+        panel.querySelector("[data-role=clear]").addEventListener("click", function () { rows.textContent = ""; });
+        panel.addEventListener("change", function () {
+            if (source) {
+                stop();
+                start();
+            }
+        });
+        document.body.addEventListener("htmx:beforeCleanupElement", function (e) {
+            if (e.target.contains(panel)) {
+                stop();
+            }
+        });
+    }
+
+    htmx.onLoad(function (root) {
+        sizeBars(root);
+        if (root.matches && root.matches("[data-events-panel]")) {
+            startEventsPanel(root);
+        }
+        Array.prototype.forEach.call(root.querySelectorAll("[data-events-panel]"), startEventsPanel);
+    });
+})();
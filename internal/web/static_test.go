@@ -0,0 +1,115 @@
+package web
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStaticHandlerCachesVersionedAssets(t *testing.T) {
+	url := assetURL("bullderdash.js")
+	if url == "" {
+		t.Fatal("bullderdash.js is not embedded")
+	}
+
+	rec := httptest.NewRecorder()
+	StaticHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if got := rec.Header().Get("Cache-Control"); !strings.Contains(got, "immutable") {
+		t.Fatalf("Cache-Control = %q, want immutable", got)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/javascript") {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	etag := rec.Header().Get("ETag")
+
+	// Unversioned or stale URLs revalidate instead.
+	req := httptest.NewRequest(http.MethodGet, "/static/bullderdash.js?v=old", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	StaticHandler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("revalidation status = %d, want 304", rec.Code)
+	}
+	if got := rec.Header().Get("Cache-Control"); strings.Contains(got, "immutable") {
+		t.Fatalf("stale URL Cache-Control = %q", got)
+	}
+}
+
+func TestStaticHandlerUnknownAsset(t *testing.T) {
+	for _, path := range []string{"/static/missing.js", "/static/", "/static/../static.go"} {
+		rec := httptest.NewRecorder()
+		StaticHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: status = %d, want 404", path, rec.Code)
+		}
+	}
+}
+
+func TestContentSecurityPolicy(t *testing.T) {
+	for _, unwanted := range []string{"unsafe-inline", "unsafe-eval", "https:"} {
+		if strings.Contains(contentSecurityPolicy, unwanted) {
+			t.Fatalf("policy allows %q: %s", unwanted, contentSecurityPolicy)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	WithSecurityHeaders(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Header().Get("Content-Security-Policy") != contentSecurityPolicy {
+		t.Fatalf("Content-Security-Policy = %q", rec.Header().Get("Content-Security-Policy"))
+	}
+	if rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatal("missing X-Content-Type-Options")
+	}
+}
+
+// TestEmbeddedAssetsMatchChecksums keeps the embedded htmx and CSS in step
+// with scripts/ui-assets.sha384, which the build script writes.
+func TestEmbeddedAssetsMatchChecksums(t *testing.T) {
+	data, err := os.ReadFile("../../scripts/ui-assets.sha384")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		file, want, ok := strings.Cut(line, " ")
+		if !ok {
+			t.Fatalf("malformed checksum line %q", line)
+		}
+		name := strings.TrimPrefix(file, "internal/web/static/")
+		listed[name] = true
+		asset, ok := staticAssets[name]
+		if !ok {
+			continue
+		}
+		sum := sha512.Sum384(asset.body)
+		if got := "sha384-" + base64.StdEncoding.EncodeToString(sum[:]); got != want {
+			t.Errorf("%s: %s, checksums list %s; rerun scripts/build-ui-assets.sh", name, got, want)
+		}
+	}
+	for name := range staticAssets {
+		if name != "bullderdash.js" && !listed[name] {
+			t.Errorf("%s is embedded but not in scripts/ui-assets.sha384", name)
+		}
+	}
+}
+
+func TestShellHasNoInlineScriptsOrStyles(t *testing.T) {
+	rec := httptest.NewRecorder()
+	AuditHandler(nil)(rec, httptest.NewRequest(http.MethodGet, "/audit", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, assetURL("bullderdash.js")) {
+		t.Fatal("page does not load bullderdash.js")
+	}
+	for _, inline := range []string{"<script>", "style=", "<style"} {
+		if strings.Contains(body, inline) {
+			t.Fatalf("page has inline %q", inline)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package web
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kofno/bullderdash/internal/auth"
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+type workerRow struct {
+	explorer.Worker
+	Age  string
+	Idle string
+}
+
+type queueWorkersViewData struct {
+	Prefix  string
+	Queue   string
+	Workers []workerRow
+	Error   string
+}
+
+const queueWorkersTmpl = `
+<div id="queue-workers" hx-get="{{basePath}}/queue/workers?prefix={{urlquery .Prefix}}&queue={{urlquery .Queue}}" hx-trigger="every 10s" hx-swap="outerHTML" class="mb-8 rounded-lg border border-gray-200 p-4">
+    <div class="flex items-center justify-between">
+        <div class="text-xs uppercase text-gray-400">Workers</div>
+        {{if not .Error}}<span class="text-sm font-semibold text-gray-900">{{len .Workers}} connected</span>{{end}}
+    </div>
+    {{if .Error}}
+    <div class="mt-2 text-sm text-gray-500">Worker discovery unavailable: {{.Error}}</div>
+    {{else if .Workers}}
+    <div class="mt-3 overflow-x-auto">
+        <table class="min-w-full divide-y divide-gray-200 text-sm">
+            <thead>
+                <tr>
+                    <th class="px-3 py-2 text-left text-xs font-medium text-gray-500 uppercase">Name</th>
+                    <th class="px-3 py-2 text-left text-xs font-medium text-gray-500 uppercase">Address</th>
+                    <th class="px-3 py-2 text-left text-xs font-medium text-gray-500 uppercase">Connected for</th>
+                    <th class="px-3 py-2 text-left text-xs font-medium text-gray-500 uppercase">Idle</th>
+                    <th class="px-3 py-2 text-left text-xs font-medium text-gray-500 uppercase">Client</th>
+                </tr>
+            </thead>
+            <tbody class="divide-y divide-gray-100">
+                {{range .Workers}}
+                <tr>
+                    <td class="px-3 py-2 text-gray-900">{{if .Name}}{{.Name}}{{else}}<span class="text-gray-400">(unnamed)</span>{{end}}</td>
+                    <td class="px-3 py-2 font-mono text-gray-600">{{.Addr}}</td>
+                    <td class="px-3 py-2 text-gray-600">{{.Age}}</td>
+                    <td class="px-3 py-2 text-gray-600">{{.Idle}}</td>
+                    <td class="px-3 py-2 font-mono text-xs text-gray-500">{{.ClientID}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+    </div>
+    {{else}}
+    <div class="mt-2 text-sm text-gray-500">No workers are connected to this queue.</div>
+    {{end}}
+</div>
+`
+
+var queueWorkersTemplate = template.Must(newTemplate("queue-workers").Parse(queueWorkersTmpl))
+
+// QueueWorkersHandler lists the workers connected to a queue, found through
+// CLIENT LIST, as a polled fragment for the queue page or JSON with
+// format=json.
+func QueueWorkersHandler(exp *explorer.Explorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		queueName := strings.TrimSpace(r.URL.Query().Get("queue"))
+		if queueName == "" {
+			http.Error(w, "queue parameter required", http.StatusBadRequest)
+			return
+		}
+		if !requireQueueRole(w, r, queueName, auth.RoleViewer) {
+			return
+		}
+		prefix, ok := requestPrefix(w, r, exp)
+		if !ok {
+			return
+		}
+
+		workers, err := exp.GetWorkers(r.Context(), prefix, queueName)
+		if wantsJSON(r) {
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, struct {
+				Prefix  string            `json:"prefix"`
+				Queue   string            `json:"queue"`
+				Workers []explorer.Worker `json:"workers"`
+			}{prefix, queueName, workers})
+			return
+		}
+
+		// The panel polls, so a CLIENT LIST failure (often disabled on
+		// managed Redis) is shown in place rather than as an error response.
+		data := newQueueWorkersViewData(prefix, queueName, workers)
+		if err != nil {
+			log.Printf("❌ worker discovery error (prefix=%s queue=%s): %v", prefix, queueName, err)
+			data.Error = err.Error()
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := executeTemplate(w, r, queueWorkersTemplate, data); err != nil {
+			log.Printf("❌ Template execution error (queue workers): %v", err)
+		}
+	}
+}
+
+func newQueueWorkersViewData(prefix, queueName string, workers []explorer.Worker) queueWorkersViewData {
+	rows := make([]workerRow, 0, len(workers))
+	for _, worker := range workers {
+		rows = append(rows, workerRow{
+			Worker: worker,
+			Age:    (time.Duration(worker.AgeSeconds) * time.Second).String(),
+			Idle:   (time.Duration(worker.IdleSeconds) * time.Second).String(),
+		})
+	}
+	return queueWorkersViewData{Prefix: prefix, Queue: queueName, Workers: rows}
+}
@@ -0,0 +1,42 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kofno/bullderdash/internal/explorer"
+)
+
+func TestQueueWorkersTemplate(t *testing.T) {
+	data := newQueueWorkersViewData("bull", "email", []explorer.Worker{
+		{Queue: "email", Name: "mailer-1", Addr: "10.0.0.5:51234", ClientID: "7", AgeSeconds: 3700, IdleSeconds: 2},
+	})
+	if data.Workers[0].Age != "1h1m40s" || data.Workers[0].Idle != "2s" {
+		t.Fatalf("unexpected durations: %+v", data.Workers[0])
+	}
+
+	var b strings.Builder
+	if err := executeTemplate(&b, httptest.NewRequest(http.MethodGet, "/", nil), queueWorkersTemplate, data); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for _, want := range []string{"1 connected", "mailer-1", "10.0.0.5:51234"} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("expected panel to contain %q", want)
+		}
+	}
+}
+
+func TestQueueWorkersTemplateShowsDiscoveryErrors(t *testing.T) {
+	data := newQueueWorkersViewData("bull", "email", nil)
+	data.Error = "ERR unknown command 'CLIENT'"
+
+	var b strings.Builder
+	if err := executeTemplate(&b, httptest.NewRequest(http.MethodGet, "/", nil), queueWorkersTemplate, data); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(b.String(), "Worker discovery unavailable") || strings.Contains(b.String(), "connected") {
+		t.Fatalf("unexpected panel: %s", b.String())
+	}
+}
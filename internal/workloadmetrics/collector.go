@@ -3,7 +3,6 @@ package workloadmetrics
 import (
 	"context"
 	"errors"
-	"fmt"
 	"log"
 	"sort"
 	"strings"
@@ -24,7 +23,10 @@ type QueueDiscoverer interface {
 }
 
 type Config struct {
-	QueuePrefix         string
+	// Environment labels the metrics when one dashboard watches several
+	// Redis targets.
+	Environment         string
+	QueuePrefixes       []string
 	PollInterval        time.Duration
 	BlockTimeout        time.Duration
 	BatchSize           int64
@@ -33,24 +35,35 @@ type Config struct {
 }
 
 type Collector struct {
-	client     *redis.Client
+	client     redis.UniversalClient
 	discoverer QueueDiscoverer
 	cfg        Config
 
-	mu      sync.Mutex
-	queues  []string
+	mu     sync.Mutex
+	queues []queueRef
+	// lastIDs is keyed by event stream key.
 	lastIDs map[string]string
 	limiter *jobNameLimiter
 	now     func() time.Time
 }
 
+// queueRef is a queue under one of the configured prefixes.
+type queueRef struct {
+	prefix string
+	name   string
+}
+
+func (q queueRef) key() string {
+	return q.prefix + ":" + q.name
+}
+
 type jobSample struct {
 	Name            string
 	DurationSeconds float64
 	HasDuration     bool
 }
 
-func New(client *redis.Client, discoverer QueueDiscoverer, cfg Config) *Collector {
+func New(client redis.UniversalClient, discoverer QueueDiscoverer, cfg Config) *Collector {
 	cfg = normalizeConfig(cfg)
 	return &Collector{
 		client:     client,
@@ -63,8 +76,11 @@ func New(client *redis.Client, discoverer QueueDiscoverer, cfg Config) *Collecto
 }
 
 func normalizeConfig(cfg Config) Config {
-	if cfg.QueuePrefix == "" {
-		cfg.QueuePrefix = "bull"
+	if cfg.Environment == "" {
+		cfg.Environment = "default"
+	}
+	if len(cfg.QueuePrefixes) == 0 {
+		cfg.QueuePrefixes = []string{"bull"}
 	}
 	if cfg.PollInterval <= 0 {
 		cfg.PollInterval = 10 * time.Second
@@ -116,7 +132,7 @@ func (c *Collector) Run(ctx context.Context) {
 			if errors.Is(err, redis.Nil) {
 				continue
 			}
-			metrics.RedisOperationErrors.WithLabelValues("workload_xread").Inc()
+			metrics.RedisOperationErrors.WithLabelValues(c.cfg.Environment, "workload_xread").Inc()
 			log.Printf("workload metrics event read error: %v", err)
 			if !sleepContext(ctx, time.Second) {
 				return
@@ -126,27 +142,33 @@ func (c *Collector) Run(ctx context.Context) {
 }
 
 func (c *Collector) refreshQueues(ctx context.Context) error {
-	queues, err := c.discoverer.DiscoverQueues(ctx, c.cfg.QueuePrefix)
-	if err != nil {
-		metrics.RedisOperationErrors.WithLabelValues("workload_discover_queues").Inc()
-		return err
+	var queues []queueRef
+	for _, prefix := range c.cfg.QueuePrefixes {
+		names, err := c.discoverer.DiscoverQueues(ctx, prefix)
+		if err != nil {
+			metrics.RedisOperationErrors.WithLabelValues(c.cfg.Environment, "workload_discover_queues").Inc()
+			return err
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			queues = append(queues, queueRef{prefix: prefix, name: name})
+		}
 	}
 
-	sort.Strings(queues)
-
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	seen := make(map[string]struct{}, len(queues))
 	for _, queue := range queues {
-		seen[queue] = struct{}{}
-		if _, ok := c.lastIDs[queue]; !ok {
-			c.lastIDs[queue] = c.cfg.StartID
+		stream := queue.key() + ":events"
+		seen[stream] = struct{}{}
+		if _, ok := c.lastIDs[stream]; !ok {
+			c.lastIDs[stream] = c.cfg.StartID
 		}
 	}
-	for queue := range c.lastIDs {
-		if _, ok := seen[queue]; !ok {
-			delete(c.lastIDs, queue)
+	for stream := range c.lastIDs {
+		if _, ok := seen[stream]; !ok {
+			delete(c.lastIDs, stream)
 		}
 	}
 	c.queues = append(c.queues[:0], queues...)
@@ -160,22 +182,53 @@ func (c *Collector) queueCount() int {
 }
 
 func (c *Collector) readOnce(ctx context.Context) error {
-	streams, streamQueues := c.streamArgs()
-	if len(streamQueues) == 0 {
+	streams, streamQueues := c.streamKeys()
+	if len(streams) == 0 {
 		return nil
 	}
 
+	// XREAD can only name streams in one slot on a cluster. Blocking on one
+	// slot would starve the rest, so with several slots each is polled
+	// without blocking and the collector backs off when all are idle.
+	groups := [][]string{streams}
+	if _, ok := c.client.(*redis.ClusterClient); ok {
+		groups = groupStreamsBySlot(streams)
+	}
+	if len(groups) == 1 {
+		_, err := c.readStreams(ctx, groups[0], streamQueues, c.cfg.BlockTimeout)
+		return err
+	}
+
+	var read int
+	for _, group := range groups {
+		n, err := c.readStreams(ctx, group, streamQueues, -1)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		read += n
+	}
+	if read == 0 {
+		sleepContext(ctx, c.cfg.BlockTimeout)
+	}
+	return nil
+}
+
+// readStreams runs one XREAD over streams and processes what it returns,
+// reporting how many messages were read. A negative block reads without
+// blocking.
+func (c *Collector) readStreams(ctx context.Context, streams []string, streamQueues map[string]queueRef, block time.Duration) (int, error) {
 	start := time.Now()
 	results, err := c.client.XRead(ctx, &redis.XReadArgs{
-		Streams: streams,
+		Streams: c.streamArgs(streams),
 		Count:   c.cfg.BatchSize,
-		Block:   c.cfg.BlockTimeout,
+		Block:   block,
 	}).Result()
-	metrics.RedisOperationDuration.WithLabelValues("workload_xread").Observe(time.Since(start).Seconds())
+	metrics.RedisOperationDuration.WithLabelValues(c.cfg.Environment, "workload_xread").Observe(time.Since(start).Seconds())
 	if err != nil {
-		return err
+		return 0, err
 	}
 
+	var read int
 	for _, stream := range results {
 		queue, ok := streamQueues[stream.Stream]
 		if !ok {
@@ -183,46 +236,62 @@ func (c *Collector) readOnce(ctx context.Context) error {
 		}
 		for _, msg := range stream.Messages {
 			c.processMessage(ctx, queue, msg)
-			c.setLastID(queue, msg.ID)
+			c.setLastID(stream.Stream, msg.ID)
+			read++
 		}
 	}
-
-	return nil
+	return read, nil
 }
 
-func (c *Collector) streamArgs() ([]string, map[string]string) {
+func (c *Collector) streamKeys() ([]string, map[string]queueRef) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	streams := make([]string, 0, len(c.queues)*2)
-	ids := make([]string, 0, len(c.queues))
-	streamQueues := make(map[string]string, len(c.queues))
+	streams := make([]string, 0, len(c.queues))
+	streamQueues := make(map[string]queueRef, len(c.queues))
 	for _, queue := range c.queues {
-		stream := fmt.Sprintf("%s:%s:events", c.cfg.QueuePrefix, queue)
+		stream := queue.key() + ":events"
 		streams = append(streams, stream)
-		ids = append(ids, c.lastIDs[queue])
 		streamQueues[stream] = queue
 	}
-	streams = append(streams, ids...)
 	return streams, streamQueues
 }
 
-func (c *Collector) setLastID(queue, id string) {
+// streamArgs returns the XREAD STREAMS arguments: the stream keys followed by
+// the last ID read from each.
+func (c *Collector) streamArgs(streams []string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	args := make([]string, 0, len(streams)*2)
+	args = append(args, streams...)
+	for _, stream := range streams {
+		id, ok := c.lastIDs[stream]
+		if !ok {
+			// Dropped by a rediscovery since streamKeys; the read is harmless.
+			id = c.cfg.StartID
+		}
+		args = append(args, id)
+	}
+	return args
+}
+
+func (c *Collector) setLastID(stream, id string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if _, ok := c.lastIDs[queue]; ok {
-		c.lastIDs[queue] = id
+	if _, ok := c.lastIDs[stream]; ok {
+		c.lastIDs[stream] = id
 	}
 }
 
-func (c *Collector) processMessage(ctx context.Context, queue string, msg redis.XMessage) {
+func (c *Collector) processMessage(ctx context.Context, queue queueRef, msg redis.XMessage) {
 	event := valueString(msg.Values["event"])
 	if event == "" {
 		event = "unknown"
 	}
-	metrics.WorkloadEventsRead.WithLabelValues(queue, event).Inc()
+	metrics.WorkloadEventsRead.WithLabelValues(c.cfg.Environment, queue.prefix, queue.name, event).Inc()
 	if lag, ok := eventLagSeconds(msg.ID, c.now()); ok {
-		metrics.WorkloadEventLag.WithLabelValues(queue).Set(lag)
+		metrics.WorkloadEventLag.WithLabelValues(c.cfg.Environment, queue.prefix, queue.name).Set(lag)
 	}
 
 	result, ok := terminalResult(event)
@@ -232,28 +301,28 @@ func (c *Collector) processMessage(ctx context.Context, queue string, msg redis.
 
 	jobID := valueString(msg.Values["jobId"])
 	if jobID == "" {
-		metrics.WorkloadEventsDropped.WithLabelValues(queue, "missing_job_id").Inc()
+		metrics.WorkloadEventsDropped.WithLabelValues(c.cfg.Environment, queue.prefix, queue.name, "missing_job_id").Inc()
 		return
 	}
 
 	sample, err := c.loadJobSample(ctx, queue, jobID)
 	if err != nil {
-		metrics.WorkloadJobLookupErrors.WithLabelValues(queue, lookupErrorReason(err)).Inc()
+		metrics.WorkloadJobLookupErrors.WithLabelValues(c.cfg.Environment, queue.prefix, queue.name, lookupErrorReason(err)).Inc()
 	}
 
-	name := c.limiter.label(queue, sample.Name)
-	metrics.WorkloadJobsFinished.WithLabelValues(queue, name, result).Inc()
+	name := c.limiter.label(queue.key(), sample.Name)
+	metrics.WorkloadJobsFinished.WithLabelValues(c.cfg.Environment, queue.prefix, queue.name, name, result).Inc()
 	if sample.HasDuration {
-		metrics.WorkloadJobCompletionDuration.WithLabelValues(queue, name, result).Observe(sample.DurationSeconds)
+		metrics.WorkloadJobCompletionDuration.WithLabelValues(c.cfg.Environment, queue.prefix, queue.name, name, result).Observe(sample.DurationSeconds)
 	}
 }
 
-func (c *Collector) loadJobSample(ctx context.Context, queue, jobID string) (jobSample, error) {
-	key := fmt.Sprintf("%s:%s:%s", c.cfg.QueuePrefix, queue, jobID)
+func (c *Collector) loadJobSample(ctx context.Context, queue queueRef, jobID string) (jobSample, error) {
+	key := queue.key() + ":" + jobID
 
 	start := time.Now()
 	values, err := c.client.HMGet(ctx, key, "name", "processedOn", "finishedOn").Result()
-	metrics.RedisOperationDuration.WithLabelValues("workload_hmget_job").Observe(time.Since(start).Seconds())
+	metrics.RedisOperationDuration.WithLabelValues(c.cfg.Environment, "workload_hmget_job").Observe(time.Since(start).Seconds())
 	if err != nil {
 		return jobSample{}, err
 	}
@@ -0,0 +1,52 @@
+package workloadmetrics
+
+import "strings"
+
+const clusterSlots = 16384
+
+// hashSlot returns the Redis Cluster slot for key: CRC16 (XMODEM) of the
+// key, or of its first non-empty "{...}" hash tag, modulo 16384. BullMQ
+// relies on hash tags such as a "{bull}" prefix to keep a queue's keys in one
+// slot.
+func hashSlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % clusterSlots)
+}
+
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// groupStreamsBySlot splits streams into groups that share a hash slot, in
+// order of first appearance, since a cluster rejects multi-key commands that
+// span slots.
+func groupStreamsBySlot(streams []string) [][]string {
+	var groups [][]string
+	index := make(map[int]int)
+	for _, stream := range streams {
+		slot := hashSlot(stream)
+		i, ok := index[slot]
+		if !ok {
+			i = len(groups)
+			index[slot] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], stream)
+	}
+	return groups
+}
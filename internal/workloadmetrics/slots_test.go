@@ -0,0 +1,37 @@
+package workloadmetrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHashSlot(t *testing.T) {
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{key: "123456789", want: 0x31C3},
+		{key: "foo", want: 12182},
+		{key: "{foo}:bar", want: 12182},
+		{key: "x{foo}y{baz}", want: 12182},
+		{key: "foo{{bar}}zap", want: int(crc16("{bar") % clusterSlots)},
+	}
+	for _, tt := range tests {
+		if got := hashSlot(tt.key); got != tt.want {
+			t.Errorf("hashSlot(%q) = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+
+	if hashSlot("foo{}{bar}") != int(crc16("foo{}{bar}")%clusterSlots) {
+		t.Error("expected an empty hash tag to hash the whole key")
+	}
+}
+
+func TestGroupStreamsBySlot(t *testing.T) {
+	streams := []string{"{bull}:a:events", "bull:b:events", "{bull}:c:events"}
+	got := groupStreamsBySlot(streams)
+	want := [][]string{{"{bull}:a:events", "{bull}:c:events"}, {"bull:b:events"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("groupStreamsBySlot() = %v, want %v", got, want)
+	}
+}
@@ -11,9 +11,12 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/kofno/bullderdash/internal/audit"
+	"github.com/kofno/bullderdash/internal/auth"
 	"github.com/kofno/bullderdash/internal/config"
 	"github.com/kofno/bullderdash/internal/explorer"
 	"github.com/kofno/bullderdash/internal/metrics"
+	"github.com/kofno/bullderdash/internal/redisconn"
 	"github.com/kofno/bullderdash/internal/web"
 	"github.com/kofno/bullderdash/internal/workloadmetrics"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -30,12 +33,18 @@ func (r *statusRecorder) WriteHeader(code int) {
 	r.ResponseWriter.WriteHeader(code)
 }
 
-func withHTTPMetrics(next http.Handler) http.Handler {
+// Unwrap lets http.ResponseController reach the underlying writer, which
+// streaming handlers need to flush.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func withHTTPMetrics(basePath string, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
 		next.ServeHTTP(rec, r)
-		if path, ok := normalizePath(r.URL.Path); ok {
+		if path, ok := normalizePath(basePath, r.URL.Path); ok {
 			metrics.HTTPRequestDuration.WithLabelValues(
 				r.Method,
 				path,
@@ -45,7 +54,24 @@ func withHTTPMetrics(next http.Handler) http.Handler {
 	})
 }
 
-func normalizePath(path string) (string, bool) {
+// normalizePath maps a request path to its route pattern for the
+// http_request_duration_seconds metric. Patterns leave out BASE_PATH, so
+// dashboards work the same however the app is mounted; paths outside it are
+// not recorded.
+func normalizePath(basePath, path string) (string, bool) {
+	if basePath != "" {
+		rest, ok := strings.CutPrefix(path, basePath)
+		if !ok || !strings.HasPrefix(rest, "/") {
+			return "", false
+		}
+		path = rest
+	}
+	if _, rest, ok := web.SplitEnvironmentPath(path); ok {
+		path = rest
+	}
+	if pattern, ok := web.NormalizeAPIPath(path); ok {
+		return pattern, true
+	}
 	switch {
 	case path == "/":
 		return "/", true
@@ -55,114 +81,178 @@ func normalizePath(path string) (string, bool) {
 		return "/queue/jobs", true
 	case path == "/queue/summary":
 		return "/queue/summary", true
+	case path == "/queue/promote":
+		return "/queue/promote", true
+	case path == "/queue/pause":
+		return "/queue/pause", true
+	case path == "/queue/resume":
+		return "/queue/resume", true
+	case path == "/queue/clean":
+		return "/queue/clean", true
+	case path == "/queue/drain":
+		return "/queue/drain", true
+	case path == "/queue/obliterate":
+		return "/queue/obliterate", true
+	case path == "/queue/workers":
+		return "/queue/workers", true
+	case path == "/queue/events":
+		return "/queue/events", true
+	case path == "/queue/schedulers":
+		return "/queue/schedulers", true
+	case path == "/queue/schedulers/remove":
+		return "/queue/schedulers/remove", true
+	case path == "/queue/retry":
+		return "/queue/retry", true
+	case path == "/queue/retry/preview":
+		return "/queue/retry/preview", true
+	case path == "/queue/retry/status":
+		return "/queue/retry/status", true
+	case path == "/queue/retry/cancel":
+		return "/queue/retry/cancel", true
 	case strings.HasPrefix(path, "/queue/"):
 		return "/queue/:name", true
 	case path == "/job/detail":
 		return "/job/detail", true
+	case path == "/job/flow":
+		return "/job/flow", true
+	case path == "/job/logs":
+		return "/job/logs", true
+	case path == "/job/retry":
+		return "/job/retry", true
+	case path == "/job/remove":
+		return "/job/remove", true
+	case path == "/job/promote":
+		return "/job/promote", true
+	case path == "/audit":
+		return "/audit", true
 	case path == "/metrics":
 		return "/metrics", true
+	case strings.HasPrefix(path, web.StaticPathPrefix):
+		return "/static/:file", true
 	case path == "/health" || path == "/healthz":
 		return "/health", true
 	case path == "/ready" || path == "/readyz":
 		return "/ready", true
+	case path == auth.LoginPath || path == auth.LogoutPath || path == "/auth/callback":
+		return path, true
 	default:
 		return "", false
 	}
 }
 
+// environment is one Redis target with its own client, explorer, dashboard
+// cache and background refresh.
+type environment struct {
+	name        string
+	client      redis.UniversalClient
+	exp         *explorer.Explorer
+	cache       *web.DashboardCache
+	bulkRetries *web.BulkRetryManager
+	audit       *audit.Logger
+	// events is the separate pool live event tails block on, and
+	// eventStreams caps them at the pool's size.
+	events       redis.UniversalClient
+	eventStreams *web.EventStreams
+}
+
 func main() {
 	// 1. Load configuration
 	cfg := config.Load()
-	redisMode := "direct"
-	if cfg.RedisSentinelMaster != "" && len(cfg.RedisSentinelAddrs) > 0 {
-		redisMode = "sentinel"
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("❌ Invalid configuration: %v", err)
+	}
+	envNames := make([]string, 0, len(cfg.Environments))
+	for _, envCfg := range cfg.Environments {
+		envNames = append(envNames, envCfg.Name)
 	}
-	log.Printf("🔧 Starting Bull-der-dash with config: RedisMode=%s, Redis=%s, Port=%s, Prefix=%s, MetricsPoll=%ds, DashboardRefreshTimeout=%ds, WorkloadMetrics=%t",
-		redisMode, cfg.RedisAddr, cfg.ServerPort, cfg.QueuePrefix, cfg.MetricsPollSeconds, cfg.DashboardRefreshTimeoutSeconds, cfg.WorkloadMetricsEnabled)
+	log.Printf("🔧 Starting Bull-der-dash with config: Environments=%s, Port=%s, BasePath=%q, MetricsPoll=%ds, DashboardRefreshTimeout=%ds, WorkloadMetrics=%t",
+		strings.Join(envNames, ","), cfg.ServerPort, cfg.BasePath, cfg.MetricsPollSeconds, cfg.DashboardRefreshTimeoutSeconds, cfg.WorkloadMetricsEnabled)
 
-	// 2. Setup Redis/Valkey client
-	rdb := newRedisClient(cfg)
-	defer func(rdb *redis.Client) {
-		err := rdb.Close()
+	// 2. Setup a Redis/Valkey client per environment
+	environments := make([]*environment, 0, len(cfg.Environments))
+	// Each environment gets an even share of the live event streams, so
+	// their pools together stay within EVENTS_MAX_STREAMS connections.
+	eventStreamsPerEnv := cfg.EventsMaxStreams / len(cfg.Environments)
+	for _, envCfg := range cfg.Environments {
+		env, err := newEnvironment(envCfg, eventStreamsPerEnv)
 		if err != nil {
-			log.Printf("⚠️ Failed to close Redis connection: %v", err)
+			log.Fatalf("❌ Invalid Redis configuration (env=%s): %v", envCfg.Name, err)
 		}
-	}(rdb)
+		environments = append(environments, env)
 
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := rdb.Ping(ctx).Err(); err != nil {
-		log.Fatalf("❌ Failed to connect to Redis: %v", err)
+		// Test connection. With several environments, one that is down
+		// must not take the others with it.
+		if err := pingRedis(env.client); err != nil {
+			if len(cfg.Environments) == 1 {
+				log.Fatalf("❌ Failed to connect to Redis: %v", err)
+			}
+			log.Printf("⚠️ Failed to connect to Redis (env=%s): %v", env.name, err)
+			continue
+		}
+		log.Printf("✅ Connected to Redis/Valkey (env=%s)", env.name)
+	}
+	closeAudit, err := setupAudit(cfg.Audit, environments)
+	if err != nil {
+		log.Fatalf("❌ Invalid audit configuration: %v", err)
 	}
-	log.Println("✅ Connected to Redis/Valkey")
+	log.Printf("📝 Audit log: sink=%s", cfg.Audit.Sink)
+	defer func() {
+		for _, env := range environments {
+			if err := env.client.Close(); err != nil {
+				log.Printf("⚠️ Failed to close Redis connection (env=%s): %v", env.name, err)
+			}
+			if err := env.events.Close(); err != nil {
+				log.Printf("⚠️ Failed to close Redis events connection (env=%s): %v", env.name, err)
+			}
+		}
+	}()
 
-	exp := explorer.New(rdb)
-	dashboardCache := web.NewDashboardCache()
+	// 3. Setup HTTP routes. Queue pages are served per environment; metrics
+	// and liveness are process-wide.
+	router := web.NewEnvironmentRouter()
+	for _, env := range environments {
+		router.Handle(env.name, newEnvironmentMux(env))
+	}
 
-	// 3. Setup HTTP routes
 	mux := http.NewServeMux()
-
-	// Main dashboard
-	mux.HandleFunc("/", web.HomeHandler())
-
-	mux.HandleFunc("/queues", web.DashboardHandler(exp, cfg.QueuePrefix, dashboardCache))
-	mux.HandleFunc("/queue/jobs", web.JobListHandler(exp))
-	mux.HandleFunc("/queue/summary", web.QueueSummaryHandler(exp, cfg.QueuePrefix))
-	mux.HandleFunc("/queue/", web.QueueDetailHandler(exp, cfg.QueuePrefix))
-	mux.HandleFunc("/job/detail", web.JobDetailHandler(exp))
-	mux.HandleFunc("/search", web.SearchPageHandler(exp, cfg.QueuePrefix, dashboardCache))
+	mux.Handle("/", router)
 
 	// Health checks (K8s friendly)
 	mux.HandleFunc("/health", web.HealthHandler())
 	mux.HandleFunc("/healthz", web.HealthHandler())
-	mux.HandleFunc("/ready", web.ReadyHandler(exp))
-	mux.HandleFunc("/readyz", web.ReadyHandler(exp))
 
 	// Prometheus metrics
 	mux.Handle("/metrics", promhttp.Handler())
 
-	// Background queue stats poller for metrics freshness
-	if err := refreshDashboardSnapshot(exp, cfg.QueuePrefix, cfg.DashboardRefreshTimeoutSeconds, dashboardCache); err != nil {
-		log.Printf("⚠️ initial dashboard snapshot refresh error: %v", err)
+	// UI scripts and styles, embedded so the dashboard needs no CDN
+	mux.Handle(web.StaticPathPrefix, web.StaticHandler())
+	if missing := web.MissingAssets(); len(missing) > 0 {
+		log.Fatalf("❌ UI assets %v not embedded; run scripts/build-ui-assets.sh and rebuild", missing)
 	}
 
+	// Background queue stats pollers for metrics freshness
 	stopMetrics := make(chan struct{})
-	go func() {
-		pollSeconds := cfg.MetricsPollSeconds
-		if pollSeconds < 1 {
-			pollSeconds = 1
-		}
-		ticker := time.NewTicker(time.Duration(pollSeconds) * time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				if err := refreshDashboardSnapshot(exp, cfg.QueuePrefix, cfg.DashboardRefreshTimeoutSeconds, dashboardCache); err != nil {
-					snapshot := dashboardCache.Get()
-					if snapshot.UpdatedAt.IsZero() {
-						log.Printf("⚠️ dashboard snapshot refresh error: %v (no cached snapshot available)", err)
-						continue
-					}
-					log.Printf("⚠️ dashboard snapshot refresh error: %v (serving cached snapshot age=%s)", err, time.Since(snapshot.UpdatedAt).Round(time.Second))
-				}
-			case <-stopMetrics:
-				return
-			}
+	for _, env := range environments {
+		if err := refreshDashboardSnapshot(env.exp, cfg.DashboardRefreshTimeoutSeconds, env.cache); err != nil {
+			log.Printf("⚠️ initial dashboard snapshot refresh error (env=%s): %v", env.name, err)
 		}
-	}()
+		go runDashboardRefresh(env, cfg, stopMetrics)
+	}
 
 	workloadMetricsCtx, stopWorkloadMetrics := context.WithCancel(context.Background())
 	if cfg.WorkloadMetricsEnabled {
-		collector := workloadmetrics.New(rdb, exp, workloadmetrics.Config{
-			QueuePrefix:         cfg.QueuePrefix,
-			PollInterval:        time.Duration(cfg.WorkloadMetricsPollSeconds) * time.Second,
-			BlockTimeout:        time.Duration(cfg.WorkloadMetricsBlockSeconds) * time.Second,
-			BatchSize:           int64(cfg.WorkloadMetricsBatchSize),
-			MaxJobNamesPerQueue: cfg.WorkloadMetricsMaxJobNames,
-			StartID:             cfg.WorkloadMetricsStartID,
-		})
-		go collector.Run(workloadMetricsCtx)
+		for _, env := range environments {
+			collector := workloadmetrics.New(env.client, env.exp, workloadmetrics.Config{
+				Environment:         env.name,
+				QueuePrefixes:       env.exp.Prefixes(),
+				PollInterval:        time.Duration(cfg.WorkloadMetricsPollSeconds) * time.Second,
+				BlockTimeout:        time.Duration(cfg.WorkloadMetricsBlockSeconds) * time.Second,
+				BatchSize:           int64(cfg.WorkloadMetricsBatchSize),
+				MaxJobNamesPerQueue: cfg.WorkloadMetricsMaxJobNames,
+				StartID:             cfg.WorkloadMetricsStartID,
+			})
+			go collector.Run(workloadMetricsCtx)
+		}
 		log.Printf("📈 workload metrics collector enabled: poll=%ds block=%ds batch=%d maxJobNamesPerQueue=%d startID=%s",
 			cfg.WorkloadMetricsPollSeconds,
 			cfg.WorkloadMetricsBlockSeconds,
@@ -171,10 +261,35 @@ func main() {
 			cfg.WorkloadMetricsStartID)
 	}
 
-	// 4. Setup server with graceful shutdown
+	// 4. Authentication wraps everything but the probes and metrics it is
+	// told to leave open; RBAC grants then limit what each user may do.
+	policy, err := auth.ParsePolicy(cfg.Auth.RBACGrants, cfg.Auth.RBACGrantsFile)
+	if err != nil {
+		log.Fatalf("❌ Invalid RBAC configuration: %v", err)
+	}
+	authOpts := authOptions(cfg.Auth)
+	authOpts.BasePath = cfg.BasePath
+	authOpts.Policy = policy
+	authn, err := auth.New(authOpts)
+	if err != nil {
+		log.Fatalf("❌ Invalid authentication configuration: %v", err)
+	}
+	if authn.Enabled() {
+		log.Printf("🔒 Authentication enabled: methods=%s publicHealth=%t publicMetrics=%t",
+			strings.Join(authn.Methods(), ","), cfg.Auth.PublicHealth, cfg.Auth.PublicMetrics)
+		if policy != nil {
+			log.Printf("🔒 RBAC enabled: %d grants", policy.Len())
+		} else {
+			log.Printf("⚠️ RBAC is off (RBAC_GRANTS unset); every signed-in user is an admin on every queue")
+		}
+	} else {
+		log.Printf("⚠️ Authentication is off (AUTH_MODE unset); anyone who can reach the dashboard can read job data")
+	}
+
+	// 5. Setup server with graceful shutdown
 	server := &http.Server{
 		Addr:         ":" + cfg.ServerPort,
-		Handler:      withHTTPMetrics(mux),
+		Handler:      withHTTPMetrics(cfg.BasePath, web.WithSecurityHeaders(web.WithBasePath(cfg.BasePath, authn.Wrap(mux)))),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -182,7 +297,7 @@ func main() {
 
 	// Start server in goroutine
 	go func() {
-		log.Printf("🚀 Bull-der-dash is running on http://localhost:%s", cfg.ServerPort)
+		log.Printf("🚀 Bull-der-dash is running on http://localhost:%s%s/", cfg.ServerPort, cfg.BasePath)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("❌ Server error: %v", err)
 		}
@@ -194,7 +309,7 @@ func main() {
 	<-quit
 
 	log.Println("🛑 Shutting down gracefully...")
-	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	close(stopMetrics)
@@ -202,11 +317,171 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("❌ Server forced to shutdown: %v", err)
 	}
+	for _, env := range environments {
+		env.bulkRetries.Close()
+	}
+	// Bulk retries record their audit events as they stop, so the sink
+	// closes after them.
+	closeAudit()
 
 	log.Println("👋 Server exited")
 }
 
-func refreshDashboardSnapshot(exp *explorer.Explorer, queuePrefix string, timeoutSeconds int, cache *web.DashboardCache) error {
+func newEnvironment(envCfg config.Environment, maxEventStreams int) (*environment, error) {
+	redisOpts, err := redisOptions(envCfg).ApplyURL()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("🔌 Environment %s: RedisMode=%s, Redis=%s, RedisTLS=%t, Prefixes=%s",
+		envCfg.Name, redisOpts.Mode(), redisOpts.Addr, redisOpts.TLS.Enabled, strings.Join(envCfg.QueuePrefixes, ","))
+
+	if redisOpts.Mode() == "cluster" {
+		for _, prefix := range envCfg.QueuePrefixes {
+			if err := explorer.CheckClusterPrefix(prefix); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	rdb, err := redisconn.NewClient(redisOpts)
+	if err != nil {
+		return nil, err
+	}
+	// Live event tails block in XREAD; they get their own pool, sized to
+	// this environment's stream cap, so they cannot starve pages and actions.
+	eventsOpts := redisOpts
+	eventsOpts.PoolSize = maxEventStreams
+	eventsRdb, err := redisconn.NewClient(eventsOpts)
+	if err != nil {
+		return nil, err
+	}
+	exp := explorer.NewEnvironment(envCfg.Name, rdb, envCfg.QueuePrefixes...)
+	exp.UseEventsClient(eventsRdb)
+	return &environment{
+		name:         envCfg.Name,
+		client:       rdb,
+		exp:          exp,
+		cache:        web.NewDashboardCache(),
+		bulkRetries:  web.NewBulkRetryManager(),
+		events:       eventsRdb,
+		eventStreams: web.NewEventStreams(maxEventStreams),
+	}, nil
+}
+
+// auditRecentEvents is how many events the stdout sink keeps for the audit
+// page.
+const auditRecentEvents = 5000
+
+// setupAudit gives each environment its audit logger. The Redis sink writes
+// to the environment's own Redis; stdout and file sinks are shared, and the
+// events carry the environment name. closeAudit flushes and closes the file
+// sink on shutdown.
+func setupAudit(cfg config.Audit, environments []*environment) (closeAudit func(), err error) {
+	closeAudit = func() {}
+	var shared audit.Sink
+	switch cfg.Sink {
+	case audit.SinkNone:
+		return closeAudit, nil
+	case audit.SinkStdout:
+		shared = audit.NewWriterSink(os.Stdout, auditRecentEvents)
+	case audit.SinkFile:
+		sink, err := audit.NewFileSink(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		shared = sink
+		closeAudit = func() {
+			if err := sink.Close(); err != nil {
+				log.Printf("⚠️ Failed to close audit file %s: %v", cfg.File, err)
+			}
+		}
+	}
+	for _, env := range environments {
+		sink := shared
+		if cfg.Sink == audit.SinkRedis {
+			sink = audit.NewRedisSink(env.client, cfg.RedisKey)
+		}
+		env.audit = audit.New(sink, env.name)
+	}
+	return closeAudit, nil
+}
+
+func pingRedis(rdb redis.UniversalClient) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return rdb.Ping(ctx).Err()
+}
+
+// newEnvironmentMux wires the dashboard routes for one environment.
+func newEnvironmentMux(env *environment) *http.ServeMux {
+	exp := env.exp
+	mux := http.NewServeMux()
+
+	// Main dashboard
+	mux.HandleFunc("/", web.HomeHandler())
+
+	mux.HandleFunc("/queues", web.DashboardHandler(exp, env.cache))
+	mux.HandleFunc("/queue/jobs", web.JobListHandler(exp))
+	mux.HandleFunc("/queue/summary", web.QueueSummaryHandler(exp))
+	mux.HandleFunc("/queue/promote", web.PromoteDelayedHandler(exp, env.audit))
+	mux.HandleFunc("/queue/pause", web.PauseQueueHandler(exp, env.audit))
+	mux.HandleFunc("/queue/resume", web.ResumeQueueHandler(exp, env.audit))
+	mux.HandleFunc("/queue/clean", web.CleanQueueHandler(exp, env.audit))
+	mux.HandleFunc("/queue/drain", web.DrainQueueHandler(exp, env.audit))
+	mux.HandleFunc("/queue/obliterate", web.ObliterateQueueHandler(exp, env.audit))
+	mux.HandleFunc("/queue/workers", web.QueueWorkersHandler(exp))
+	mux.HandleFunc("/queue/events", web.QueueEventsHandler(exp, env.eventStreams))
+	mux.HandleFunc("/queue/schedulers", web.JobSchedulersHandler(exp))
+	mux.HandleFunc("/queue/schedulers/remove", web.RemoveJobSchedulerHandler(exp, env.audit))
+	mux.HandleFunc("/queue/retry", web.BulkRetryHandler(exp, env.bulkRetries, env.audit))
+	mux.HandleFunc("/queue/retry/preview", web.BulkRetryPreviewHandler(exp))
+	mux.HandleFunc("/queue/retry/status", web.BulkRetryStatusHandler(env.bulkRetries))
+	mux.HandleFunc("/queue/retry/cancel", web.BulkRetryCancelHandler(env.bulkRetries, env.audit))
+	mux.HandleFunc("/queue/", web.QueueDetailHandler(exp))
+	mux.HandleFunc("/job/detail", web.JobDetailHandler(exp))
+	mux.HandleFunc("/job/flow", web.JobFlowHandler(exp))
+	mux.HandleFunc("/job/logs", web.JobLogsHandler(exp))
+	mux.HandleFunc("/job/retry", web.RetryJobHandler(exp, env.audit))
+	mux.HandleFunc("/job/remove", web.RemoveJobHandler(exp, env.audit))
+	mux.HandleFunc("/job/promote", web.PromoteJobHandler(exp, env.audit))
+	mux.HandleFunc("/search", web.SearchPageHandler(exp, env.cache))
+	mux.HandleFunc("/audit", web.AuditHandler(env.audit))
+
+	// Versioned JSON API
+	mux.Handle("/api/v1/", web.NewAPIHandler(exp, env.cache, env.bulkRetries, env.audit))
+
+	// Readiness is per environment: /ready checks the default one and
+	// /env/<name>/ready the others.
+	mux.HandleFunc("/ready", web.ReadyHandler(exp))
+	mux.HandleFunc("/readyz", web.ReadyHandler(exp))
+	return mux
+}
+
+func runDashboardRefresh(env *environment, cfg *config.Config, stop <-chan struct{}) {
+	pollSeconds := cfg.MetricsPollSeconds
+	if pollSeconds < 1 {
+		pollSeconds = 1
+	}
+	ticker := time.NewTicker(time.Duration(pollSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := refreshDashboardSnapshot(env.exp, cfg.DashboardRefreshTimeoutSeconds, env.cache); err != nil {
+				snapshot := env.cache.Get()
+				if snapshot.UpdatedAt.IsZero() {
+					log.Printf("⚠️ dashboard snapshot refresh error (env=%s): %v (no cached snapshot available)", env.name, err)
+					continue
+				}
+				log.Printf("⚠️ dashboard snapshot refresh error (env=%s): %v (serving cached snapshot age=%s)", env.name, err, time.Since(snapshot.UpdatedAt).Round(time.Second))
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func refreshDashboardSnapshot(exp *explorer.Explorer, timeoutSeconds int, cache *web.DashboardCache) error {
 	if timeoutSeconds < 1 {
 		timeoutSeconds = 1
 	}
@@ -214,27 +489,74 @@ func refreshDashboardSnapshot(exp *explorer.Explorer, queuePrefix string, timeou
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
 	defer cancel()
 
-	return web.RefreshDashboardCache(ctx, exp, queuePrefix, cache)
+	return web.RefreshDashboardCache(ctx, exp, exp.Prefixes(), cache)
 }
 
-func newRedisClient(cfg *config.Config) *redis.Client {
-	// Enable Sentinel mode only when both master name and sentinel addresses are set.
-	if cfg.RedisSentinelMaster != "" && len(cfg.RedisSentinelAddrs) > 0 {
-		return redis.NewFailoverClient(&redis.FailoverOptions{
-			MasterName:       cfg.RedisSentinelMaster,
-			SentinelAddrs:    cfg.RedisSentinelAddrs,
-			Username:         cfg.RedisUsername,
-			Password:         cfg.RedisPassword,
-			SentinelUsername: cfg.RedisSentinelUsername,
-			SentinelPassword: cfg.RedisSentinelPassword,
-			DB:               cfg.RedisDB,
-		})
+func authOptions(cfg config.Auth) auth.Options {
+	return auth.Options{
+		Methods:        cfg.Methods,
+		BasicUsers:     cfg.BasicUsers,
+		BasicUsersFile: cfg.BasicUsersFile,
+		APITokens:      cfg.APITokens,
+		APITokensFile:  cfg.APITokensFile,
+		OIDC: auth.OIDCOptions{
+			IssuerURL:     cfg.OIDCIssuerURL,
+			ClientID:      cfg.OIDCClientID,
+			ClientSecret:  cfg.OIDCClientSecret,
+			RedirectURL:   cfg.OIDCRedirectURL,
+			Scopes:        cfg.OIDCScopes,
+			UsernameClaim: cfg.OIDCUsernameClaim,
+			GroupsClaim:   cfg.OIDCGroupsClaim,
+		},
+		SessionSecret:  cfg.SessionSecret,
+		SessionTTL:     time.Duration(cfg.SessionTTLSeconds) * time.Second,
+		Public:         publicPaths(cfg),
+		TrustedOrigins: cfg.TrustedOrigins,
 	}
+}
 
-	return redis.NewClient(&redis.Options{
-		Addr:     cfg.RedisAddr,
-		Username: cfg.RedisUsername,
-		Password: cfg.RedisPassword,
-		DB:       cfg.RedisDB,
-	})
+// publicPaths reports the probe, metrics and static asset paths that skip
+// authentication. Only readiness is also served per environment, as
+// /env/<name>/ready.
+func publicPaths(cfg config.Auth) func(path string) bool {
+	return func(path string) bool {
+		// Assets are the same for everyone and hold no queue data.
+		if strings.HasPrefix(path, web.StaticPathPrefix) {
+			return true
+		}
+		if _, rest, ok := web.SplitEnvironmentPath(path); ok {
+			return cfg.PublicHealth && (rest == "/ready" || rest == "/readyz")
+		}
+		switch path {
+		case "/health", "/healthz", "/ready", "/readyz":
+			return cfg.PublicHealth
+		case "/metrics":
+			return cfg.PublicMetrics
+		default:
+			return false
+		}
+	}
+}
+
+func redisOptions(cfg config.Environment) redisconn.Options {
+	return redisconn.Options{
+		URL:              cfg.RedisURL,
+		Addr:             cfg.RedisAddr,
+		Username:         cfg.RedisUsername,
+		Password:         cfg.RedisPassword,
+		DB:               cfg.RedisDB,
+		SentinelMaster:   cfg.RedisSentinelMaster,
+		SentinelAddrs:    cfg.RedisSentinelAddrs,
+		SentinelUsername: cfg.RedisSentinelUsername,
+		SentinelPassword: cfg.RedisSentinelPassword,
+		ClusterAddrs:     cfg.RedisClusterAddrs,
+		TLS: redisconn.TLSOptions{
+			Enabled:            cfg.RedisTLS,
+			CAFile:             cfg.RedisTLSCAFile,
+			CertFile:           cfg.RedisTLSCertFile,
+			KeyFile:            cfg.RedisTLSKeyFile,
+			ServerName:         cfg.RedisTLSServerName,
+			InsecureSkipVerify: cfg.RedisTLSInsecureSkipVerify,
+		},
+	}
 }
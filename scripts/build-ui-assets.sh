@@ -0,0 +1,65 @@
+#!/bin/sh
+# Builds the UI assets the Go binary embeds, so the dashboard serves them
+# itself instead of loading them from public CDNs:
+#   internal/web/static/htmx.min.js   pinned htmx release, checked by hash
+#   internal/web/static/tailwind.css  only the classes the templates use
+# It records their SHA-384s in scripts/ui-assets.sha384. Run it before the
+# first build and after changing template classes, and commit all three
+# files; it needs curl, openssl and npx. With --check it only verifies the
+# committed files against the checksums, offline, as the Dockerfile does,
+# and fails if either file or its checksum is missing.
+set -eu
+
+HTMX_VERSION=1.9.10
+HTMX_SHA384=D1Kt99CQMDuVetoL1lrYwg5t+9QdHe7NLX/SoJYkXDFfX37iInKRy5xLSi8nO7UC
+TAILWIND_VERSION=3.4.17
+
+root=$(cd "$(dirname "$0")/.." && pwd)
+static="internal/web/static"
+sums="scripts/ui-assets.sha384"
+cd "$root"
+
+sha384() {
+    openssl dgst -sha384 -binary "$1" | openssl base64 -A
+}
+
+if [ "${1:-}" = "--check" ]; then
+    status=0
+    for file in "$static/htmx.min.js" "$static/tailwind.css"; do
+        if ! grep -q "^$file " "$sums"; then
+            echo "$file: no checksum in $sums (run scripts/build-ui-assets.sh)" >&2
+            status=1
+        fi
+    done
+    while read -r file want; do
+        if [ ! -s "$file" ]; then
+            echo "$file: missing (run scripts/build-ui-assets.sh)" >&2
+            status=1
+        elif [ "sha384-$(sha384 "$file")" != "$want" ]; then
+            echo "$file: does not match $sums (run scripts/build-ui-assets.sh)" >&2
+            status=1
+        fi
+    done < "$sums"
+    exit $status
+fi
+
+curl -fsSL -o "$static/htmx.min.js" "https://unpkg.com/htmx.org@$HTMX_VERSION/dist/htmx.min.js"
+sum=$(sha384 "$static/htmx.min.js")
+if [ "$sum" != "$HTMX_SHA384" ]; then
+    rm -f "$static/htmx.min.js"
+    echo "htmx $HTMX_VERSION: sha384 $sum does not match $HTMX_SHA384" >&2
+    exit 1
+fi
+
+npx --yes "tailwindcss@$TAILWIND_VERSION" \
+    -i scripts/tailwind.input.css \
+    -o "$static/tailwind.css" \
+    --content "internal/web/*.go,internal/web/static/bullderdash.js" \
+    --minify
+
+{
+    echo "$static/htmx.min.js sha384-$HTMX_SHA384"
+    echo "$static/tailwind.css sha384-$(sha384 "$static/tailwind.css")"
+} > "$sums"
+
+echo "UI assets written to $static; commit them with $sums"